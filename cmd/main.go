@@ -1,8 +1,16 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"github.com/dontpanicw/SalesTracker/internal/app"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	// База часовых поясов для аналитики по tz, в alpine-образе её нет
+	_ "time/tzdata"
 )
 
 func main() {
@@ -11,7 +19,13 @@ func main() {
 		log.Fatalf("Failed to create app: %v", err)
 	}
 
-	if err := application.Run(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := application.Execute(ctx, os.Args[1:]); err != nil {
+		if errors.Is(err, app.ErrUsage) {
+			os.Exit(2)
+		}
 		log.Fatalf("Failed to run app: %v", err)
 	}
 }
@@ -3,14 +3,41 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"time"
+)
+
+// Хранилища, которые можно выбрать через DB_DRIVER
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite" // файл базы задается в SQLITE_PATH
+	DriverMemory   = "memory" // данные хранятся в памяти процесса и теряются при остановке
 )
 
 type Config struct {
-	DatabaseDSN string
-	ServerPort  string
+	DatabaseDriver   string // DriverPostgres, DriverSQLite или DriverMemory
+	DatabaseDSN      string // для SQLite — путь к файлу базы
+	ServerPort       string
+	IdempotencyTTL   time.Duration // сколько хранятся ответы на запросы с Idempotency-Key
+	IdempotencyLease time.Duration // сколько ключ занят первым запросом, прежде чем повтор сможет его перехватить
+	TrashRetention   time.Duration // сколько удаленные записи хранятся в корзине
+	PurgeInterval    time.Duration // как часто из корзины удаляются записи старше TrashRetention
+
+	// Пул соединений и таймауты PostgreSQL
+	DBMaxOpenConns    int           // максимум открытых соединений
+	DBMaxIdleConns    int           // максимум простаивающих соединений в пуле
+	DBConnMaxLifetime time.Duration // через сколько соединение закрывается и открывается заново
+	DBQueryTimeout    time.Duration // предельное время одного запроса
+	DBConnectTimeout  time.Duration // сколько при старте ждать, пока база станет доступна
+	DBConnectDelay    time.Duration // первая пауза между попытками подключения, дальше удваивается
 }
 
 func Load() (*Config, error) {
+	dbDriver := getEnv("DB_DRIVER", DriverPostgres)
+	if dbDriver != DriverPostgres && dbDriver != DriverSQLite && dbDriver != DriverMemory {
+		return nil, fmt.Errorf("invalid DB_DRIVER: must be %s, %s or %s", DriverPostgres, DriverSQLite, DriverMemory)
+	}
+
 	dbHost := getEnv("DB_HOST", "localhost")
 	dbPort := getEnv("DB_PORT", "5432")
 	dbUser := getEnv("DB_USER", "postgres")
@@ -21,10 +48,70 @@ func Load() (*Config, error) {
 		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		dbHost, dbPort, dbUser, dbPassword, dbName,
 	)
+	if dbDriver == DriverSQLite {
+		dsn = getEnv("SQLITE_PATH", "salestracker.db")
+	}
+
+	idempotencyTTL, err := getDuration("IDEMPOTENCY_TTL", "24h")
+	if err != nil {
+		return nil, err
+	}
+	idempotencyLease, err := getDuration("IDEMPOTENCY_LEASE", "1m")
+	if err != nil {
+		return nil, err
+	}
+	trashRetention, err := getDuration("TRASH_RETENTION", "720h")
+	if err != nil {
+		return nil, err
+	}
+	purgeInterval, err := getDuration("TRASH_PURGE_INTERVAL", "1h")
+	if err != nil {
+		return nil, err
+	}
+
+	maxOpenConns, err := getLimitInt("DB_MAX_OPEN_CONNS", "25")
+	if err != nil {
+		return nil, err
+	}
+	maxIdleConns, err := getLimitInt("DB_MAX_IDLE_CONNS", "5")
+	if err != nil {
+		return nil, err
+	}
+	if maxOpenConns > 0 && maxIdleConns > maxOpenConns {
+		return nil, fmt.Errorf("invalid DB_MAX_IDLE_CONNS: must not exceed DB_MAX_OPEN_CONNS")
+	}
+	connMaxLifetime, err := getLimitDuration("DB_CONN_MAX_LIFETIME", "30m")
+	if err != nil {
+		return nil, err
+	}
+	queryTimeout, err := getLimitDuration("DB_QUERY_TIMEOUT", "10s")
+	if err != nil {
+		return nil, err
+	}
+	connectTimeout, err := getLimitDuration("DB_CONNECT_TIMEOUT", "30s")
+	if err != nil {
+		return nil, err
+	}
+	connectDelay, err := getLimitDuration("DB_CONNECT_DELAY", "500ms")
+	if err != nil {
+		return nil, err
+	}
 
 	return &Config{
-		DatabaseDSN: dsn,
-		ServerPort:  getEnv("SERVER_PORT", "8080"),
+		DatabaseDriver:   dbDriver,
+		DatabaseDSN:      dsn,
+		ServerPort:       getEnv("SERVER_PORT", "8080"),
+		IdempotencyTTL:   idempotencyTTL,
+		IdempotencyLease: idempotencyLease,
+		TrashRetention:   trashRetention,
+		PurgeInterval:    purgeInterval,
+
+		DBMaxOpenConns:    maxOpenConns,
+		DBMaxIdleConns:    maxIdleConns,
+		DBConnMaxLifetime: connMaxLifetime,
+		DBQueryTimeout:    queryTimeout,
+		DBConnectTimeout:  connectTimeout,
+		DBConnectDelay:    connectDelay,
 	}, nil
 }
 
@@ -34,3 +121,32 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getDuration читает из переменной окружения положительную длительность вида 24h
+func getDuration(key, defaultValue string) (time.Duration, error) {
+	value, err := time.ParseDuration(getEnv(key, defaultValue))
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("invalid %s: must be a positive duration like %s", key, defaultValue)
+	}
+	return value, nil
+}
+
+// getLimitDuration читает из переменной окружения неотрицательную длительность
+// ограничения; 0 отключает ограничение
+func getLimitDuration(key, defaultValue string) (time.Duration, error) {
+	value, err := time.ParseDuration(getEnv(key, defaultValue))
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid %s: must be a duration like %s or 0", key, defaultValue)
+	}
+	return value, nil
+}
+
+// getLimitInt читает из переменной окружения неотрицательное целое число;
+// 0 отключает ограничение
+func getLimitInt(key, defaultValue string) (int, error) {
+	value, err := strconv.Atoi(getEnv(key, defaultValue))
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid %s: must be an integer like %s or 0", key, defaultValue)
+	}
+	return value, nil
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 )
 
 func TestLoad(t *testing.T) {
@@ -15,23 +16,101 @@ func TestLoad(t *testing.T) {
 			name: "default values",
 			envVars: map[string]string{},
 			want: &Config{
-				DatabaseDSN: "host=localhost port=5432 user=postgres password=postgres dbname=analytics sslmode=disable",
-				ServerPort:  "8080",
+				DatabaseDriver:   DriverPostgres,
+				DatabaseDSN:      "host=localhost port=5432 user=postgres password=postgres dbname=analytics sslmode=disable",
+				ServerPort:       "8080",
+				IdempotencyTTL:   24 * time.Hour,
+				IdempotencyLease: time.Minute,
+				TrashRetention:   30 * 24 * time.Hour,
+				PurgeInterval:    time.Hour,
+
+				DBMaxOpenConns:    25,
+				DBMaxIdleConns:    5,
+				DBConnMaxLifetime: 30 * time.Minute,
+				DBQueryTimeout:    10 * time.Second,
+				DBConnectTimeout:  30 * time.Second,
+				DBConnectDelay:    500 * time.Millisecond,
 			},
 		},
 		{
 			name: "custom values",
 			envVars: map[string]string{
-				"DB_HOST":     "customhost",
-				"DB_PORT":     "5433",
-				"DB_USER":     "customuser",
-				"DB_PASSWORD": "custompass",
-				"DB_NAME":     "customdb",
-				"SERVER_PORT": "9090",
+				"DB_DRIVER":            "memory",
+				"DB_HOST":              "customhost",
+				"DB_PORT":              "5433",
+				"DB_USER":              "customuser",
+				"DB_PASSWORD":          "custompass",
+				"DB_NAME":              "customdb",
+				"SERVER_PORT":          "9090",
+				"IDEMPOTENCY_TTL":      "1h30m",
+				"IDEMPOTENCY_LEASE":    "10s",
+				"TRASH_RETENTION":      "168h",
+				"TRASH_PURGE_INTERVAL": "15m",
+				"DB_MAX_OPEN_CONNS":    "50",
+				"DB_MAX_IDLE_CONNS":    "10",
+				"DB_CONN_MAX_LIFETIME": "1h",
+				"DB_QUERY_TIMEOUT":     "3s",
+				"DB_CONNECT_TIMEOUT":   "1m",
+				"DB_CONNECT_DELAY":     "1s",
+			},
+			want: &Config{
+				DatabaseDriver:   DriverMemory,
+				DatabaseDSN:      "host=customhost port=5433 user=customuser password=custompass dbname=customdb sslmode=disable",
+				ServerPort:       "9090",
+				IdempotencyTTL:   90 * time.Minute,
+				IdempotencyLease: 10 * time.Second,
+				TrashRetention:   7 * 24 * time.Hour,
+				PurgeInterval:    15 * time.Minute,
+
+				DBMaxOpenConns:    50,
+				DBMaxIdleConns:    10,
+				DBConnMaxLifetime: time.Hour,
+				DBQueryTimeout:    3 * time.Second,
+				DBConnectTimeout:  time.Minute,
+				DBConnectDelay:    time.Second,
+			},
+		},
+		{
+			name: "sqlite",
+			envVars: map[string]string{
+				"DB_DRIVER":   "sqlite",
+				"SQLITE_PATH": "/var/lib/salestracker/items.db",
 			},
 			want: &Config{
-				DatabaseDSN: "host=customhost port=5433 user=customuser password=custompass dbname=customdb sslmode=disable",
-				ServerPort:  "9090",
+				DatabaseDriver:   DriverSQLite,
+				DatabaseDSN:      "/var/lib/salestracker/items.db",
+				ServerPort:       "8080",
+				IdempotencyTTL:   24 * time.Hour,
+				IdempotencyLease: time.Minute,
+				TrashRetention:   30 * 24 * time.Hour,
+				PurgeInterval:    time.Hour,
+
+				DBMaxOpenConns:    25,
+				DBMaxIdleConns:    5,
+				DBConnMaxLifetime: 30 * time.Minute,
+				DBQueryTimeout:    10 * time.Second,
+				DBConnectTimeout:  30 * time.Second,
+				DBConnectDelay:    500 * time.Millisecond,
+			},
+		},
+		{
+			name: "zero disables pool limits and timeouts",
+			envVars: map[string]string{
+				"DB_MAX_OPEN_CONNS":    "0",
+				"DB_MAX_IDLE_CONNS":    "0",
+				"DB_CONN_MAX_LIFETIME": "0",
+				"DB_QUERY_TIMEOUT":     "0",
+				"DB_CONNECT_TIMEOUT":   "0s",
+				"DB_CONNECT_DELAY":     "0s",
+			},
+			want: &Config{
+				DatabaseDriver:   DriverPostgres,
+				DatabaseDSN:      "host=localhost port=5432 user=postgres password=postgres dbname=analytics sslmode=disable",
+				ServerPort:       "8080",
+				IdempotencyTTL:   24 * time.Hour,
+				IdempotencyLease: time.Minute,
+				TrashRetention:   30 * 24 * time.Hour,
+				PurgeInterval:    time.Hour,
 			},
 		},
 	}
@@ -54,16 +133,94 @@ func TestLoad(t *testing.T) {
 				t.Errorf("Load() error = %v", err)
 				return
 			}
+			if got.DatabaseDriver != tt.want.DatabaseDriver {
+				t.Errorf("Load() DatabaseDriver = %v, want %v", got.DatabaseDriver, tt.want.DatabaseDriver)
+			}
 			if got.DatabaseDSN != tt.want.DatabaseDSN {
 				t.Errorf("Load() DatabaseDSN = %v, want %v", got.DatabaseDSN, tt.want.DatabaseDSN)
 			}
 			if got.ServerPort != tt.want.ServerPort {
 				t.Errorf("Load() ServerPort = %v, want %v", got.ServerPort, tt.want.ServerPort)
 			}
+			if got.IdempotencyTTL != tt.want.IdempotencyTTL {
+				t.Errorf("Load() IdempotencyTTL = %v, want %v", got.IdempotencyTTL, tt.want.IdempotencyTTL)
+			}
+			if got.IdempotencyLease != tt.want.IdempotencyLease {
+				t.Errorf("Load() IdempotencyLease = %v, want %v", got.IdempotencyLease, tt.want.IdempotencyLease)
+			}
+			if got.TrashRetention != tt.want.TrashRetention {
+				t.Errorf("Load() TrashRetention = %v, want %v", got.TrashRetention, tt.want.TrashRetention)
+			}
+			if got.PurgeInterval != tt.want.PurgeInterval {
+				t.Errorf("Load() PurgeInterval = %v, want %v", got.PurgeInterval, tt.want.PurgeInterval)
+			}
+			if got.DBMaxOpenConns != tt.want.DBMaxOpenConns || got.DBMaxIdleConns != tt.want.DBMaxIdleConns {
+				t.Errorf("Load() pool size = %d/%d, want %d/%d", got.DBMaxOpenConns, got.DBMaxIdleConns, tt.want.DBMaxOpenConns, tt.want.DBMaxIdleConns)
+			}
+			if got.DBConnMaxLifetime != tt.want.DBConnMaxLifetime {
+				t.Errorf("Load() DBConnMaxLifetime = %v, want %v", got.DBConnMaxLifetime, tt.want.DBConnMaxLifetime)
+			}
+			if got.DBQueryTimeout != tt.want.DBQueryTimeout {
+				t.Errorf("Load() DBQueryTimeout = %v, want %v", got.DBQueryTimeout, tt.want.DBQueryTimeout)
+			}
+			if got.DBConnectTimeout != tt.want.DBConnectTimeout || got.DBConnectDelay != tt.want.DBConnectDelay {
+				t.Errorf("Load() connect retry = %v/%v, want %v/%v", got.DBConnectTimeout, got.DBConnectDelay, tt.want.DBConnectTimeout, tt.want.DBConnectDelay)
+			}
 		})
 	}
 }
 
+func TestLoad_InvalidDuration(t *testing.T) {
+	for _, key := range []string{"IDEMPOTENCY_TTL", "IDEMPOTENCY_LEASE", "TRASH_RETENTION", "TRASH_PURGE_INTERVAL"} {
+		for _, value := range []string{"day", "-1h", "0s"} {
+			os.Setenv(key, value)
+			if _, err := Load(); err == nil {
+				t.Errorf("Load() with %s=%q expected error", key, value)
+			}
+		}
+		os.Unsetenv(key)
+	}
+	// Ограничения пула и таймауты допускают 0, но не отрицательные значения
+	for _, key := range []string{"DB_CONN_MAX_LIFETIME", "DB_QUERY_TIMEOUT", "DB_CONNECT_TIMEOUT", "DB_CONNECT_DELAY"} {
+		for _, value := range []string{"day", "-1h"} {
+			os.Setenv(key, value)
+			if _, err := Load(); err == nil {
+				t.Errorf("Load() with %s=%q expected error", key, value)
+			}
+		}
+		os.Unsetenv(key)
+	}
+}
+
+func TestLoad_InvalidPoolSize(t *testing.T) {
+	tests := []map[string]string{
+		{"DB_MAX_OPEN_CONNS": "many"},
+		{"DB_MAX_OPEN_CONNS": "-1"},
+		{"DB_MAX_IDLE_CONNS": "-1"},
+		{"DB_MAX_OPEN_CONNS": "5", "DB_MAX_IDLE_CONNS": "10"},
+	}
+	for _, envVars := range tests {
+		for k, v := range envVars {
+			os.Setenv(k, v)
+		}
+		if _, err := Load(); err == nil {
+			t.Errorf("Load() with %v expected error", envVars)
+		}
+		for k := range envVars {
+			os.Unsetenv(k)
+		}
+	}
+}
+
+func TestLoad_InvalidDriver(t *testing.T) {
+	os.Setenv("DB_DRIVER", "mysql")
+	defer os.Unsetenv("DB_DRIVER")
+
+	if _, err := Load(); err == nil {
+		t.Error("Load() with DB_DRIVER=mysql expected error")
+	}
+}
+
 func TestGetEnv(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -0,0 +1,302 @@
+// Package aggregate считает аналитику по записям в Go для хранилищ, в которых
+// нет PERCENTILE_CONT и date_trunc: в памяти и SQLite. Результаты совпадают
+// с агрегатами PostgreSQL-адаптера.
+package aggregate
+
+import (
+	"fmt"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Item — запись периода, по которой считается аналитика
+type Item struct {
+	Type     string
+	Category string
+	Currency string
+	Date     time.Time
+	Amount   domain.Money
+}
+
+// Convert пересчитывает суммы записей в валюту отчета по последнему курсу,
+// действовавшему на дату записи. Курс ищется как в прямом, так и в обратном
+// направлении. Если для какой-то записи курса нет, возвращается ошибка валидации.
+func Convert(items []Item, rates []*domain.ExchangeRate, currency string) ([]Item, error) {
+	converted := make([]Item, len(items))
+	for i, item := range items {
+		if item.Currency != currency {
+			rate := rateOn(rates, item.Currency, currency, item.Date)
+			if rate == nil {
+				return nil, domain.NewValidationError("currency", fmt.Sprintf("no exchange rate from %s to %s on %s", item.Currency, currency, item.Date.Format("2006-01-02")))
+			}
+			item.Amount = convert(item.Amount, rate)
+			item.Currency = currency
+		}
+		converted[i] = item
+	}
+	return converted, nil
+}
+
+// rateOn возвращает курс from -> to, действовавший на дату date, или nil.
+// Обратный курс to -> from используется как 1 / rate.
+func rateOn(rates []*domain.ExchangeRate, from, to string, date time.Time) *big.Rat {
+	var best *domain.ExchangeRate
+	inverse := false
+	for _, rate := range rates {
+		direct := rate.From == from && rate.To == to
+		if !direct && !(rate.From == to && rate.To == from) {
+			continue
+		}
+		if rate.ValidFrom.After(date) {
+			continue
+		}
+		if best == nil || rate.ValidFrom.After(best.ValidFrom) || (rate.ValidFrom.Equal(best.ValidFrom) && direct) {
+			best, inverse = rate, !direct
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	value := best.Rate.Rat()
+	if inverse {
+		value.Inv(value)
+	}
+	return value
+}
+
+// convert пересчитывает сумму по курсу с округлением до копеек (половина — от нуля), как ROUND(amount * rate, 2)
+func convert(amount domain.Money, rate *big.Rat) domain.Money {
+	value := new(big.Rat).Mul(new(big.Rat).SetInt64(amount.Cents()), rate)
+	// Сумма неотрицательна, поэтому округление — отбрасывание дробной части от value + 1/2
+	value.Add(value, big.NewRat(1, 2))
+	return domain.Money(new(big.Int).Quo(value.Num(), value.Denom()).Int64())
+}
+
+// Stats вычисляет статистику сумм так же, как агрегаты statsColumns
+// PostgreSQL-адаптера: медиана и перцентили — с линейной интерполяцией
+// (PERCENTILE_CONT), стандартное отклонение и дисперсия — по генеральной совокупности
+func Stats(amounts []domain.Money, percentiles []float64) domain.Analytics {
+	analytics := domain.Analytics{Count: int64(len(amounts))}
+	if len(amounts) == 0 {
+		return analytics
+	}
+
+	sorted := append([]domain.Money{}, amounts...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum domain.Money
+	for _, amount := range sorted {
+		sum = sum.Add(amount)
+	}
+	n := int64(len(sorted))
+	mean := sum.Float64() / float64(n)
+	var squares float64
+	for _, amount := range sorted {
+		d := amount.Float64() - mean
+		squares += d * d
+	}
+
+	analytics.Sum = sum
+	// AVG по numeric точен, поэтому среднее округляется в целых копейках
+	analytics.Avg = domain.Money((2*sum.Cents() + n) / (2 * n))
+	analytics.Median = domain.MoneyFromFloat(percentileCont(sorted, 0.5))
+	analytics.Percentile = domain.MoneyFromFloat(percentileCont(sorted, 0.9))
+	analytics.Min = sorted[0]
+	analytics.Max = sorted[len(sorted)-1]
+	analytics.Variance = squares / float64(n)
+	analytics.StdDev = domain.MoneyFromFloat(math.Sqrt(analytics.Variance))
+
+	values := make([]float64, len(percentiles))
+	for i, p := range percentiles {
+		values[i] = percentileCont(sorted, p)
+	}
+	analytics.Percentiles = domain.NewPercentileMap(percentiles, values)
+	return analytics
+}
+
+// percentileCont возвращает перцентиль p упорядоченных сумм с линейной интерполяцией
+func percentileCont(sorted []domain.Money, p float64) float64 {
+	pos := p * float64(len(sorted)-1)
+	lo, hi := int(math.Floor(pos)), int(math.Ceil(pos))
+	low, high := sorted[lo].Float64(), sorted[hi].Float64()
+	return low + (pos-float64(lo))*(high-low)
+}
+
+// Report считает аналитику доходов и расходов. Тип без записей остается с нулевой статистикой.
+func Report(items []Item, query domain.AnalyticsQuery) *domain.AnalyticsReport {
+	amounts := make(map[string][]domain.Money)
+	for _, item := range items {
+		amounts[item.Type] = append(amounts[item.Type], item.Amount)
+	}
+
+	report := &domain.AnalyticsReport{Currency: query.Currency}
+	if income := amounts[domain.TypeIncome]; len(income) > 0 {
+		report.Income = Stats(income, query.Percentiles)
+	}
+	if expense := amounts[domain.TypeExpense]; len(expense) > 0 {
+		report.Expense = Stats(expense, query.Percentiles)
+	}
+	report.Balance = report.Income.Sum.Sub(report.Expense.Sum)
+	return report
+}
+
+// CheckGroupBy проверяет поля группировки
+func CheckGroupBy(fields []string) error {
+	for _, field := range fields {
+		if field != domain.GroupByCategory && field != domain.GroupByType && !domain.IsPeriodGroup(field) {
+			return domain.NewValidationError("group_by", fmt.Sprintf("unknown group_by field '%s'", field))
+		}
+	}
+	return nil
+}
+
+// Groups считает аналитику по группам. Поля группировки должны быть проверены CheckGroupBy.
+// Группы упорядочены по полям группировки в порядке group_by.
+func Groups(items []Item, query domain.AnalyticsQuery) []*domain.AnalyticsGroup {
+	type groupKey struct {
+		category string
+		itemType string
+		period   time.Time
+	}
+	keyOf := func(item Item) groupKey {
+		var key groupKey
+		for _, field := range query.GroupBy {
+			switch {
+			case field == domain.GroupByCategory:
+				key.category = item.Category
+			case field == domain.GroupByType:
+				key.itemType = item.Type
+			case domain.IsPeriodGroup(field):
+				key.period = truncatePeriod(item.Date.UTC(), field)
+			}
+		}
+		return key
+	}
+
+	var keys []groupKey
+	amounts := make(map[groupKey][]domain.Money)
+	totals := make(map[groupKey]*domain.AnalyticsGroup)
+	for _, item := range items {
+		key := keyOf(item)
+		if _, ok := amounts[key]; !ok {
+			keys = append(keys, key)
+			totals[key] = &domain.AnalyticsGroup{}
+		}
+		amounts[key] = append(amounts[key], item.Amount)
+		switch item.Type {
+		case domain.TypeIncome:
+			totals[key].Income = totals[key].Income.Add(item.Amount)
+		case domain.TypeExpense:
+			totals[key].Expense = totals[key].Expense.Add(item.Amount)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		for _, field := range query.GroupBy {
+			var c int
+			switch {
+			case field == domain.GroupByCategory:
+				c = strings.Compare(a.category, b.category)
+			case field == domain.GroupByType:
+				c = strings.Compare(a.itemType, b.itemType)
+			default:
+				c = a.period.Compare(b.period)
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+
+	var groups []*domain.AnalyticsGroup
+	for _, key := range keys {
+		group := totals[key]
+		group.Net = group.Income.Sub(group.Expense)
+		group.Analytics = Stats(amounts[key], query.Percentiles)
+		for _, field := range query.GroupBy {
+			switch {
+			case field == domain.GroupByCategory:
+				category := key.category
+				group.Category = &category
+			case field == domain.GroupByType:
+				itemType := key.itemType
+				group.Type = &itemType
+			case domain.IsPeriodGroup(field):
+				period := key.period
+				group.Period = &period
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// truncatePeriod возвращает начало периода, как date_trunc: недели начинаются с понедельника.
+// Интервалы временного ряда называются так же, как группировки по времени.
+func truncatePeriod(t time.Time, period string) time.Time {
+	year, month, day := t.Date()
+	loc := t.Location()
+	switch period {
+	case domain.GroupByWeek:
+		weekday := (int(t.Weekday()) + 6) % 7 // понедельник — 0
+		return time.Date(year, month, day-weekday, 0, 0, 0, 0, loc)
+	case domain.GroupByMonth:
+		return time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	case domain.GroupByQuarter:
+		return time.Date(year, month-(month-1)%3, 1, 0, 0, 0, 0, loc)
+	case domain.GroupByYear:
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(year, month, day, 0, 0, 0, 0, loc)
+	}
+}
+
+// nextPeriod возвращает начало следующего интервала временного ряда
+func nextPeriod(start time.Time, interval string) time.Time {
+	switch interval {
+	case domain.IntervalWeek:
+		return start.AddDate(0, 0, 7)
+	case domain.IntervalMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// TimeSeries строит непрерывный ряд интервалов от query.From до query.To.
+// Границы интервалов считаются в часовом поясе запроса, интервалы без записей — с нулями.
+func TimeSeries(items []Item, query domain.TimeSeriesQuery) []*domain.TimeSeriesPoint {
+	loc := query.Location
+
+	var points []*domain.TimeSeriesPoint
+	byStart := make(map[int64]*domain.TimeSeriesPoint)
+	last := truncatePeriod(query.To.In(loc), query.Interval)
+	for start := truncatePeriod(query.From.In(loc), query.Interval); !start.After(last); start = nextPeriod(start, query.Interval) {
+		point := &domain.TimeSeriesPoint{Start: start, End: nextPeriod(start, query.Interval)}
+		points = append(points, point)
+		byStart[start.Unix()] = point
+	}
+
+	for _, item := range items {
+		point := byStart[truncatePeriod(item.Date.In(loc), query.Interval).Unix()]
+		if point == nil {
+			continue
+		}
+		switch item.Type {
+		case domain.TypeIncome:
+			point.Income = point.Income.Add(item.Amount)
+		case domain.TypeExpense:
+			point.Expense = point.Expense.Add(item.Amount)
+		}
+	}
+	for _, point := range points {
+		point.Net = point.Income.Sub(point.Expense)
+	}
+	return points
+}
@@ -0,0 +1,109 @@
+package aggregate
+
+import (
+	"errors"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	amounts := []domain.Money{
+		domain.NewMoney(400, 0), domain.NewMoney(100, 0), domain.NewMoney(300, 0), domain.NewMoney(200, 0),
+	}
+	got := Stats(amounts, []float64{0.25})
+
+	want := domain.Analytics{
+		Sum:        domain.NewMoney(1000, 0),
+		Avg:        domain.NewMoney(250, 0),
+		Count:      4,
+		Median:     domain.NewMoney(250, 0),
+		Percentile: domain.NewMoney(370, 0),
+		Min:        domain.NewMoney(100, 0),
+		Max:        domain.NewMoney(400, 0),
+		StdDev:     domain.MoneyFromFloat(111.80339887),
+		Variance:   12500,
+	}
+	if got.Sum != want.Sum || got.Avg != want.Avg || got.Count != want.Count ||
+		got.Median != want.Median || got.Percentile != want.Percentile ||
+		got.Min != want.Min || got.Max != want.Max || got.StdDev != want.StdDev || got.Variance != want.Variance {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+	if p := got.Percentiles["0.25"]; p != domain.NewMoney(175, 0) {
+		t.Errorf("Stats() p25 = %s, want 175.00", p)
+	}
+
+	if empty := Stats(nil, nil); empty.Count != 0 || empty.Sum != 0 {
+		t.Errorf("Stats(nil) = %+v, want zero", empty)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	jan := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC)
+	rates := []*domain.ExchangeRate{
+		{From: "USD", To: "RUB", Rate: domain.MustParseRate("90"), ValidFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{From: "USD", To: "RUB", Rate: domain.MustParseRate("100"), ValidFrom: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{From: "RUB", To: "EUR", Rate: domain.MustParseRate("0.01"), ValidFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{From: "CNY", To: "RUB", Rate: domain.MustParseRate("12.5125"), ValidFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	tests := []struct {
+		name     string
+		item     Item
+		currency string
+		want     domain.Money
+		wantErr  bool
+	}{
+		{name: "same currency", item: Item{Currency: "RUB", Date: jan, Amount: domain.NewMoney(10, 0)}, currency: "RUB", want: domain.NewMoney(10, 0)},
+		{name: "rate on date", item: Item{Currency: "USD", Date: jan, Amount: domain.NewMoney(10, 0)}, currency: "RUB", want: domain.NewMoney(900, 0)},
+		{name: "latest rate", item: Item{Currency: "USD", Date: feb, Amount: domain.NewMoney(10, 0)}, currency: "RUB", want: domain.NewMoney(1000, 0)},
+		{name: "inverse rate", item: Item{Currency: "EUR", Date: jan, Amount: domain.NewMoney(1, 0)}, currency: "RUB", want: domain.NewMoney(100, 0)},
+		{name: "rounded to cents", item: Item{Currency: "RUB", Date: jan, Amount: domain.NewMoney(0, 50)}, currency: "EUR", want: domain.NewMoney(0, 1)},
+		// 2 * 12.5125 = 25.025 ровно; в float64 курс чуть меньше и сумма округлилась бы вниз
+		{name: "exact half cent", item: Item{Currency: "CNY", Date: jan, Amount: domain.NewMoney(2, 0)}, currency: "RUB", want: domain.NewMoney(25, 3)},
+		{name: "no rate", item: Item{Currency: "GBP", Date: jan, Amount: domain.NewMoney(1, 0)}, currency: "RUB", wantErr: true},
+		{name: "rate not yet valid", item: Item{Currency: "USD", Date: jan.AddDate(-1, 0, 0), Amount: domain.NewMoney(1, 0)}, currency: "RUB", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Convert([]Item{tt.item}, rates, tt.currency)
+			if tt.wantErr {
+				if !errors.Is(err, domain.ErrValidation) {
+					t.Fatalf("Convert() error = %v, want validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if got[0].Amount != tt.want || got[0].Currency != tt.currency {
+				t.Errorf("Convert() = %s %s, want %s %s", got[0].Amount, got[0].Currency, tt.want, tt.currency)
+			}
+		})
+	}
+}
+
+func TestTruncatePeriod(t *testing.T) {
+	date := time.Date(2024, 5, 16, 13, 45, 0, 0, time.UTC) // четверг
+
+	tests := []struct {
+		period string
+		want   time.Time
+	}{
+		{domain.GroupByDay, time.Date(2024, 5, 16, 0, 0, 0, 0, time.UTC)},
+		{domain.GroupByWeek, time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC)},
+		{domain.GroupByMonth, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{domain.GroupByQuarter, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{domain.GroupByYear, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.period, func(t *testing.T) {
+			if got := truncatePeriod(date, tt.period); !got.Equal(tt.want) {
+				t.Errorf("truncatePeriod() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"context"
+	"github.com/dontpanicw/SalesTracker/internal/adapter/repository/aggregate"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"sort"
+	"time"
+)
+
+// convertedItems возвращает действующие записи за период from–to с суммами,
+// пересчитанными в валюту отчета
+func (r *repository) convertedItems(from, to time.Time, currency string) ([]aggregate.Item, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := make([]int64, 0, len(r.items))
+	for id := range r.items {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var items []aggregate.Item
+	for _, id := range ids {
+		item := r.items[id]
+		if item.DeletedAt != nil || item.Date.Before(from) || item.Date.After(to) {
+			continue
+		}
+		items = append(items, aggregate.Item{
+			Type: item.Type, Category: item.Category, Currency: item.Currency, Date: item.Date, Amount: item.Amount,
+		})
+	}
+
+	rates := make([]*domain.ExchangeRate, 0, len(r.rates))
+	for _, rate := range r.rates {
+		rates = append(rates, rate)
+	}
+	return aggregate.Convert(items, rates, currency)
+}
+
+func (r *repository) GetAnalytics(ctx context.Context, query domain.AnalyticsQuery) (*domain.AnalyticsReport, error) {
+	items, err := r.convertedItems(query.From, query.To, query.Currency)
+	if err != nil {
+		return nil, err
+	}
+	return aggregate.Report(items, query), nil
+}
+
+func (r *repository) GetGroupedAnalytics(ctx context.Context, query domain.AnalyticsQuery) ([]*domain.AnalyticsGroup, error) {
+	if err := aggregate.CheckGroupBy(query.GroupBy); err != nil {
+		return nil, err
+	}
+	items, err := r.convertedItems(query.From, query.To, query.Currency)
+	if err != nil {
+		return nil, err
+	}
+	return aggregate.Groups(items, query), nil
+}
+
+// GetTimeSeries строит непрерывный ряд интервалов, интервалы без записей — с нулями
+func (r *repository) GetTimeSeries(ctx context.Context, query domain.TimeSeriesQuery) ([]*domain.TimeSeriesPoint, error) {
+	items, err := r.convertedItems(query.From.UTC(), query.To.UTC(), query.Currency)
+	if err != nil {
+		return nil, err
+	}
+	return aggregate.TimeSeries(items, query), nil
+}
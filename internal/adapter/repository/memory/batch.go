@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"context"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"time"
+)
+
+// ApplyItemBatch создает, обновляет и удаляет записи под одной блокировкой.
+// Ошибки отдельных обновлений и удалений (нет записи, не совпала версия)
+// возвращаются в failed по id записи. Если partial = false и такие ошибки есть,
+// хранилище не изменяется.
+func (r *repository) ApplyItemBatch(ctx context.Context, batch *domain.ItemBatch, partial bool) (map[int64]error, error) {
+	for _, item := range append(append([]*domain.Item{}, batch.Create...), batch.Update...) {
+		if err := checkItem(item); err != nil {
+			return nil, err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Сначала проверяются все операции, чтобы при ошибках ничего не менять
+	failed := make(map[int64]error)
+	updates := make([]*domain.Item, 0, len(batch.Update))
+	for _, item := range batch.Update {
+		if _, err := r.activeItem(item.ID, item.Version); err != nil {
+			failed[item.ID] = err
+			continue
+		}
+		updates = append(updates, item)
+	}
+	deletes := make([]int64, 0, len(batch.Delete))
+	for _, ref := range batch.Delete {
+		if _, err := r.activeItem(ref.ID, ref.Version); err != nil {
+			failed[ref.ID] = err
+			continue
+		}
+		deletes = append(deletes, ref.ID)
+	}
+	if len(failed) > 0 && !partial {
+		return failed, nil
+	}
+
+	for _, item := range batch.Create {
+		r.insertItem(item)
+	}
+	for _, item := range updates {
+		r.updateItem(r.items[item.ID], item)
+	}
+	deletedAt := storedTime(time.Now())
+	for _, id := range deletes {
+		stored := r.items[id]
+		stored.DeletedAt = &deletedAt
+		stored.Version++
+	}
+	return failed, nil
+}
@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"strconv"
+)
+
+// AddHistory сохраняет копии записей истории
+func (r *repository) AddHistory(ctx context.Context, entries []*domain.HistoryEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, entry := range entries {
+		r.lastHistoryID++
+		stored := *entry
+		stored.ID = r.lastHistoryID
+		stored.CreatedAt = storedTime(entry.CreatedAt)
+		if entry.Before != nil {
+			stored.Before = cloneItem(entry.Before)
+		}
+		if entry.After != nil {
+			stored.After = cloneItem(entry.After)
+		}
+		r.history = append(r.history, &stored)
+	}
+	return nil
+}
+
+// GetHistory возвращает страницу истории изменений от новых к старым
+func (r *repository) GetHistory(ctx context.Context, filter domain.HistoryFilter) (*domain.HistoryPage, error) {
+	cursor, err := filter.DecodeCursor()
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	page := &domain.HistoryPage{Entries: []*domain.HistoryEntry{}}
+	for i := len(r.history) - 1; i >= 0; i-- {
+		entry := r.history[i]
+		switch {
+		case cursor != 0 && entry.ID >= cursor,
+			filter.ItemID != 0 && entry.ItemID != filter.ItemID,
+			filter.Actor != "" && entry.Actor != filter.Actor,
+			filter.From != nil && entry.CreatedAt.Before(*filter.From),
+			filter.To != nil && entry.CreatedAt.After(*filter.To):
+			continue
+		}
+		// Лишняя запись показывает, есть ли следующая страница
+		if len(page.Entries) == filter.Limit {
+			page.NextCursor = strconv.FormatInt(page.Entries[filter.Limit-1].ID, 10)
+			break
+		}
+		copied := *entry
+		if entry.Before != nil {
+			copied.Before = cloneItem(entry.Before)
+		}
+		if entry.After != nil {
+			copied.After = cloneItem(entry.After)
+		}
+		page.Entries = append(page.Entries, &copied)
+	}
+	return page, nil
+}
@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"context"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+)
+
+// ReserveIdempotencyKey удаляет истекшие ключи и сохраняет новый. Незавершенный ключ
+// с истекшей арендой перехватывается. Если ключ уже есть, возвращает копию сохраненной записи.
+func (r *repository) ReserveIdempotencyKey(ctx context.Context, record *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, stored := range r.idempotency {
+		if stored.ExpiresAt.Before(record.CreatedAt) {
+			delete(r.idempotency, key)
+		}
+	}
+
+	// Незавершенный ключ с истекшей арендой перезаписывается
+	createdAt := storedTime(record.CreatedAt)
+	if stored, ok := r.idempotency[record.Key]; ok && (stored.Response != nil || !stored.LockedUntil.Before(createdAt)) {
+		existing := *stored
+		existing.Response = append([]byte(nil), stored.Response...)
+		if stored.Response == nil {
+			existing.Response = nil
+		}
+		return &existing, nil
+	}
+
+	stored := *record
+	stored.Response = nil
+	stored.CreatedAt, stored.LockedUntil, stored.ExpiresAt = createdAt, storedTime(record.LockedUntil), storedTime(record.ExpiresAt)
+	r.idempotency[record.Key] = &stored
+	return nil, nil
+}
+
+// CompleteIdempotencyKey сохраняет ответ, если резервирование record не перехвачено
+func (r *repository) CompleteIdempotencyKey(ctx context.Context, record *domain.IdempotencyRecord, response []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.idempotency[record.Key]
+	if !ok || !ownsReservation(stored, record) {
+		return domain.ErrIdempotencyKeyTakenOver
+	}
+	stored.Response = append([]byte{}, response...)
+	return nil
+}
+
+// ReleaseIdempotencyKey удаляет резервирование record, чтобы запрос можно было повторить
+func (r *repository) ReleaseIdempotencyKey(ctx context.Context, record *domain.IdempotencyRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stored, ok := r.idempotency[record.Key]; ok && ownsReservation(stored, record) {
+		delete(r.idempotency, record.Key)
+	}
+	return nil
+}
+
+// ownsReservation сообщает, что незавершенный ключ stored зарезервирован запросом record
+func ownsReservation(stored, record *domain.IdempotencyRecord) bool {
+	return stored.Response == nil && stored.LockedUntil.Equal(storedTime(record.LockedUntil))
+}
@@ -0,0 +1,212 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/dontpanicw/SalesTracker/internal/adapter/repository/search"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// selectItems возвращает копии записей по фильтру в порядке сортировки,
+// без учета курсора и размера страницы. Вызывается под mu.
+func (r *repository) selectItems(filter domain.ItemFilter) ([]*domain.Item, error) {
+	switch filter.Sort.Field {
+	case domain.SortByDate, domain.SortByAmount, domain.SortByCategory, domain.SortByCreatedAt:
+	case domain.SortByRank:
+		if filter.Query == "" {
+			return nil, domain.NewValidationError("sort", fmt.Sprintf("unknown sort field '%s'", filter.Sort.Field))
+		}
+	default:
+		return nil, domain.NewValidationError("sort", fmt.Sprintf("unknown sort field '%s'", filter.Sort.Field))
+	}
+
+	var query search.Query
+	if filter.Query != "" {
+		query = search.Parse(filter.Query)
+	}
+
+	var items []*domain.Item
+	for _, stored := range r.items {
+		if !matchesFilter(stored, filter) {
+			continue
+		}
+		item := cloneItem(stored)
+		if filter.Query != "" {
+			if item.Match = query.Match(item.Category, item.Description); item.Match == nil {
+				continue
+			}
+		}
+		items = append(items, item)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if filter.Sort.Desc {
+			return compareItems(items[j], items[i], filter.Sort.Field) < 0
+		}
+		return compareItems(items[i], items[j], filter.Sort.Field) < 0
+	})
+	return items, nil
+}
+
+// matchesFilter проверяет условия фильтра, кроме поискового запроса
+func matchesFilter(item *domain.Item, filter domain.ItemFilter) bool {
+	if (item.DeletedAt != nil) != filter.Deleted {
+		return false
+	}
+	if filter.From != nil && item.Date.Before(*filter.From) {
+		return false
+	}
+	if filter.To != nil && item.Date.After(*filter.To) {
+		return false
+	}
+	if len(filter.Types) > 0 && !contains(filter.Types, item.Type) {
+		return false
+	}
+	if len(filter.Categories) > 0 && !contains(filter.Categories, item.Category) {
+		return false
+	}
+	if filter.MinAmount != nil && item.Amount < *filter.MinAmount {
+		return false
+	}
+	if filter.MaxAmount != nil && item.Amount > *filter.MaxAmount {
+		return false
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// compareItems сравнивает записи по полю сортировки, при равенстве — по id
+func compareItems(a, b *domain.Item, field string) int {
+	var c int
+	switch field {
+	case domain.SortByAmount:
+		c = compareOrdered(a.Amount, b.Amount)
+	case domain.SortByCategory:
+		c = strings.Compare(a.Category, b.Category)
+	case domain.SortByCreatedAt:
+		c = a.CreatedAt.Compare(b.CreatedAt)
+	case domain.SortByRank:
+		c = compareOrdered(rankOf(a), rankOf(b))
+	default:
+		c = a.Date.Compare(b.Date)
+	}
+	if c != 0 {
+		return c
+	}
+	return compareOrdered(a.ID, b.ID)
+}
+
+func compareOrdered[T domain.Money | int64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func rankOf(item *domain.Item) float64 {
+	if item.Match == nil {
+		return 0
+	}
+	return item.Match.Rank
+}
+
+// cursorItem восстанавливает из курсора запись с полем сортировки и id,
+// с которой сравниваются записи следующей страницы
+func cursorItem(cursor *domain.Cursor, field string) (*domain.Item, error) {
+	item := &domain.Item{ID: cursor.ID}
+	var err error
+	switch field {
+	case domain.SortByAmount:
+		item.Amount, err = domain.ParseMoney(cursor.Value)
+	case domain.SortByCategory:
+		item.Category = cursor.Value
+	case domain.SortByCreatedAt:
+		item.CreatedAt, err = time.Parse(time.RFC3339Nano, cursor.Value)
+	case domain.SortByRank:
+		item.Match = &domain.SearchMatch{}
+		item.Match.Rank, err = strconv.ParseFloat(cursor.Value, 64)
+	default:
+		item.Date, err = time.Parse(time.RFC3339Nano, cursor.Value)
+	}
+	if err != nil {
+		return nil, domain.NewValidationError("cursor", "invalid cursor")
+	}
+	return item, nil
+}
+
+// GetAll возвращает страницу записей по фильтру. Пагинация keyset, как в
+// PostgreSQL-адаптере: следующая страница начинается после пары (поле сортировки, id) из курсора.
+func (r *repository) GetAll(ctx context.Context, filter domain.ItemFilter) (*domain.ItemPage, error) {
+	cursor, err := filter.DecodeCursor()
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	items, err := r.selectItems(filter)
+	r.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.ItemPage{Total: int64(len(items))}
+	if cursor != nil {
+		last, err := cursorItem(cursor, filter.Sort.Field)
+		if err != nil {
+			return nil, err
+		}
+		start := sort.Search(len(items), func(i int) bool {
+			c := compareItems(items[i], last, filter.Sort.Field)
+			if filter.Sort.Desc {
+				return c < 0
+			}
+			return c > 0
+		})
+		items = items[start:]
+	}
+
+	if len(items) > filter.Limit {
+		items = items[:filter.Limit]
+		page.NextCursor = domain.NewCursor(items[filter.Limit-1], filter.Sort)
+	}
+	page.Items = items
+	return page, nil
+}
+
+// StreamAll передает в fn все записи по фильтру в порядке сортировки.
+// Курсор и размер страницы не учитываются. Записи выбираются под блокировкой,
+// а fn вызывается уже без нее, поэтому может обращаться к хранилищу.
+// Ошибка fn прерывает выборку и возвращается как есть.
+func (r *repository) StreamAll(ctx context.Context, filter domain.ItemFilter, fn func(*domain.Item) error) error {
+	r.mu.RLock()
+	items, err := r.selectItems(filter)
+	r.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+// Package memory реализует port.Repository в памяти процесса. Хранилище
+// повторяет поведение PostgreSQL-адаптера и подходит для локального запуска
+// без базы и для быстрых тестов; данные теряются при остановке процесса.
+package memory
+
+import (
+	"context"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"github.com/dontpanicw/SalesTracker/internal/port"
+	"sync"
+	"time"
+)
+
+type repository struct {
+	mu sync.RWMutex
+
+	items      map[int64]*domain.Item
+	lastItemID int64
+
+	rates      map[int64]*domain.ExchangeRate
+	lastRateID int64
+
+	history       []*domain.HistoryEntry // по возрастанию id
+	lastHistoryID int64
+
+	idempotency map[string]*domain.IdempotencyRecord
+}
+
+// New создает пустое хранилище в памяти
+func New() port.Repository {
+	return &repository{
+		items:       make(map[int64]*domain.Item),
+		rates:       make(map[int64]*domain.ExchangeRate),
+		idempotency: make(map[string]*domain.IdempotencyRecord),
+	}
+}
+
+// storedTime приводит время к виду, в котором его возвращает колонка TIMESTAMP:
+// UTC с точностью до микросекунд
+func storedTime(t time.Time) time.Time {
+	return t.UTC().Round(time.Microsecond)
+}
+
+// cloneItem возвращает копию записи, чтобы вызывающий код не менял хранимые данные
+func cloneItem(item *domain.Item) *domain.Item {
+	copied := *item
+	if item.DeletedAt != nil {
+		deletedAt := *item.DeletedAt
+		copied.DeletedAt = &deletedAt
+	}
+	if item.Match != nil {
+		match := *item.Match
+		copied.Match = &match
+	}
+	return &copied
+}
+
+// checkItem проверяет ограничения CHECK таблицы items, чтобы некорректная
+// запись отклонялась так же, как в PostgreSQL-адаптере
+func checkItem(item *domain.Item) error {
+	switch {
+	case item.Type != domain.TypeIncome && item.Type != domain.TypeExpense:
+		return constraintError("items_type_check", "type")
+	case item.Amount < 0:
+		return constraintError("items_amount_check", "amount")
+	}
+	return nil
+}
+
+func constraintError(constraint, field string) error {
+	return domain.NewValidationError(field, "value violates constraint "+constraint)
+}
+
+// insertItem сохраняет копию новой записи и заполняет ID и версию. Вызывается под mu.
+func (r *repository) insertItem(item *domain.Item) {
+	r.lastItemID++
+	stored := cloneItem(item)
+	stored.ID, stored.Version = r.lastItemID, 1
+	stored.Date = storedTime(item.Date)
+	stored.CreatedAt = storedTime(item.CreatedAt)
+	stored.UpdatedAt = storedTime(item.UpdatedAt)
+	stored.DeletedAt, stored.Match = nil, nil
+	r.items[stored.ID] = stored
+	item.ID, item.Version = stored.ID, stored.Version
+}
+
+// activeItem возвращает действующую запись с проверкой версии, как условия
+// UPDATE в PostgreSQL-адаптере. Вызывается под mu.
+func (r *repository) activeItem(id, version int64) (*domain.Item, error) {
+	stored, ok := r.items[id]
+	if !ok || stored.DeletedAt != nil {
+		return nil, domain.NewNotFoundError("item not found")
+	}
+	if version != 0 && stored.Version != version {
+		return nil, domain.ErrVersionMismatch
+	}
+	return stored, nil
+}
+
+func (r *repository) Create(ctx context.Context, item *domain.Item) error {
+	if err := checkItem(item); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.insertItem(item)
+	return nil
+}
+
+// CreateMany сохраняет все записи под одной блокировкой. Если хотя бы одна
+// запись некорректна, не сохраняется ни одна.
+func (r *repository) CreateMany(ctx context.Context, items []*domain.Item) error {
+	for _, item := range items {
+		if err := checkItem(item); err != nil {
+			return err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, item := range items {
+		r.insertItem(item)
+	}
+	return nil
+}
+
+func (r *repository) GetByID(ctx context.Context, id int64) (*domain.Item, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, err := r.activeItem(id, 0)
+	if err != nil {
+		return nil, err
+	}
+	return cloneItem(stored), nil
+}
+
+// GetByIDs возвращает действующие записи с указанными id, отсутствующие пропускаются
+func (r *repository) GetByIDs(ctx context.Context, ids []int64) ([]*domain.Item, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var items []*domain.Item
+	seen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		if stored, err := r.activeItem(id, 0); err == nil && !seen[id] {
+			seen[id] = true
+			items = append(items, cloneItem(stored))
+		}
+	}
+	return items, nil
+}
+
+// Update сохраняет запись и заполняет CreatedAt и новую версию из хранилища
+func (r *repository) Update(ctx context.Context, item *domain.Item) error {
+	if err := checkItem(item); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, err := r.activeItem(item.ID, item.Version)
+	if err != nil {
+		return err
+	}
+	r.updateItem(stored, item)
+	return nil
+}
+
+// updateItem переносит изменяемые поля item в хранимую запись. Вызывается под mu.
+func (r *repository) updateItem(stored, item *domain.Item) {
+	stored.Type, stored.Amount, stored.Currency = item.Type, item.Amount, item.Currency
+	stored.Category, stored.Description = item.Category, item.Description
+	stored.Date, stored.UpdatedAt = storedTime(item.Date), storedTime(item.UpdatedAt)
+	stored.Version++
+	item.CreatedAt, item.Version = stored.CreatedAt, stored.Version
+}
+
+// Delete переносит запись в корзину
+func (r *repository) Delete(ctx context.Context, id int64, version int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, err := r.activeItem(id, version)
+	if err != nil {
+		return err
+	}
+	deletedAt := storedTime(time.Now())
+	stored.DeletedAt = &deletedAt
+	stored.Version++
+	return nil
+}
+
+// Restore возвращает запись из корзины
+func (r *repository) Restore(ctx context.Context, id int64, version int64) (*domain.Item, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.items[id]
+	if !ok || stored.DeletedAt == nil {
+		return nil, domain.NewNotFoundError("item not found")
+	}
+	if version != 0 && stored.Version != version {
+		return nil, domain.ErrVersionMismatch
+	}
+	stored.DeletedAt = nil
+	stored.Version++
+	return cloneItem(stored), nil
+}
+
+// PurgeDeleted окончательно удаляет записи, перенесенные в корзину раньше before
+func (r *repository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	for id, stored := range r.items {
+		if stored.DeletedAt != nil && stored.DeletedAt.Before(before) {
+			delete(r.items, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
@@ -0,0 +1,13 @@
+package memory
+
+import (
+	"github.com/dontpanicw/SalesTracker/internal/adapter/repository/repotest"
+	"github.com/dontpanicw/SalesTracker/internal/port"
+	"testing"
+)
+
+func TestRepository(t *testing.T) {
+	repotest.RunRepositorySuite(t, func(t *testing.T) port.Repository {
+		return New()
+	})
+}
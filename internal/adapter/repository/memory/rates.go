@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"sort"
+)
+
+func (r *repository) CreateRate(ctx context.Context, rate *domain.ExchangeRate) error {
+	if rate.Rate <= 0 {
+		return constraintError("exchange_rates_rate_check", "rate")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	validFrom := storedTime(rate.ValidFrom)
+	for _, stored := range r.rates {
+		if stored.From == rate.From && stored.To == rate.To && stored.ValidFrom.Equal(validFrom) {
+			return domain.NewConflictError("exchange rate for this pair and valid_from already exists", nil)
+		}
+	}
+
+	r.lastRateID++
+	stored := *rate
+	stored.ID = r.lastRateID
+	stored.ValidFrom, stored.CreatedAt = validFrom, storedTime(rate.CreatedAt)
+	r.rates[stored.ID] = &stored
+	rate.ID = stored.ID
+	return nil
+}
+
+func (r *repository) GetRates(ctx context.Context, from, to string) ([]*domain.ExchangeRate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var rates []*domain.ExchangeRate
+	for _, stored := range r.rates {
+		if (from == "" || stored.From == from) && (to == "" || stored.To == to) {
+			copied := *stored
+			rates = append(rates, &copied)
+		}
+	}
+	sort.Slice(rates, func(i, j int) bool {
+		a, b := rates[i], rates[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.ValidFrom.After(b.ValidFrom)
+	})
+	return rates, nil
+}
+
+func (r *repository) DeleteRate(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.rates[id]; !ok {
+		return domain.NewNotFoundError("exchange rate not found")
+	}
+	delete(r.rates, id)
+	return nil
+}
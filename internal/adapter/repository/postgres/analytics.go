@@ -0,0 +1,265 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// groupColumns сопоставляет поля группировки с SQL-выражениями
+var groupColumns = map[string]string{
+	domain.GroupByCategory: "category",
+	domain.GroupByType:     "type",
+	domain.GroupByDay:      "date_trunc('day', date)",
+	domain.GroupByWeek:     "date_trunc('week', date)",
+	domain.GroupByMonth:    "date_trunc('month', date)",
+	domain.GroupByQuarter:  "date_trunc('quarter', date)",
+	domain.GroupByYear:     "date_trunc('year', date)",
+}
+
+// statsColumns содержит агрегаты статистики, общие для запросов аналитики.
+// Параметр $3 — массив дополнительно запрошенных перцентилей.
+const statsColumns = `
+			COALESCE(SUM(amount), 0) as sum,
+			COALESCE(AVG(amount), 0) as avg,
+			COUNT(*) as count,
+			COALESCE(PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY amount), 0) as median,
+			COALESCE(PERCENTILE_CONT(0.9) WITHIN GROUP (ORDER BY amount), 0) as percentile_90,
+			COALESCE(MIN(amount), 0) as min,
+			COALESCE(MAX(amount), 0) as max,
+			COALESCE(STDDEV_POP(amount), 0) as stddev,
+			COALESCE(VAR_POP(amount), 0) as variance,
+			PERCENTILE_CONT($3::float8[]) WITHIN GROUP (ORDER BY amount) as percentiles`
+
+// convertedItems возвращает CTE converted с записями за период $1–$2, суммы которых
+// пересчитаны в валюту отчета по последнему курсу, действовавшему на дату записи.
+// Курс ищется как в прямом, так и в обратном направлении; на одну дату прямой курс
+// важнее обратного. По обратному курсу сумма делится, а не умножается на округленное
+// 1 / rate, поэтому копейки совпадают с пакетом aggregate. Если курса нет, amount = NULL.
+// Записи в корзине не учитываются.
+// currencyParam — плейсхолдер параметра с валютой отчета.
+func convertedItems(currencyParam string) string {
+	return `
+		WITH converted AS (
+			SELECT
+				i.type, i.category, i.date, i.currency,
+				CASE
+					WHEN i.currency = ` + currencyParam + ` THEN i.amount
+					WHEN r.inverse THEN ROUND(i.amount / r.rate, 2)
+					ELSE ROUND(i.amount * r.rate, 2)
+				END as amount
+			FROM items i
+			LEFT JOIN LATERAL (
+				SELECT rates.rate, rates.inverse
+				FROM (
+					SELECT rate, valid_from, false AS inverse FROM exchange_rates
+					WHERE base_currency = i.currency AND quote_currency = ` + currencyParam + `
+					UNION ALL
+					SELECT rate, valid_from, true FROM exchange_rates
+					WHERE base_currency = ` + currencyParam + ` AND quote_currency = i.currency
+				) rates
+				WHERE rates.valid_from <= i.date
+				ORDER BY rates.valid_from DESC, rates.inverse
+				LIMIT 1
+			) r ON i.currency <> ` + currencyParam + `
+			WHERE i.date >= $1 AND i.date <= $2 AND i.deleted_at IS NULL
+		)`
+}
+
+// checkRates проверяет, что для всех записей периода есть курс пересчета в валюту отчета
+func (r *repository) checkRates(ctx context.Context, from, to time.Time, currency string) error {
+	query := convertedItems("$3") + `
+		SELECT currency, date
+		FROM converted
+		WHERE amount IS NULL
+		LIMIT 1
+	`
+	var itemCurrency string
+	var date time.Time
+	err := r.conn().QueryRowContext(ctx, query, from, to, currency).Scan(&itemCurrency, &date)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return wrapError(err)
+	}
+	return domain.NewValidationError("currency", fmt.Sprintf("no exchange rate from %s to %s on %s", itemCurrency, currency, date.Format("2006-01-02")))
+}
+
+func (r *repository) GetAnalytics(ctx context.Context, query domain.AnalyticsQuery) (*domain.AnalyticsReport, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	if err := r.checkRates(ctx, query.From.UTC(), query.To.UTC(), query.Currency); err != nil {
+		return nil, wrapError(err)
+	}
+
+	sqlQuery := convertedItems("$4") + `
+		SELECT
+			type,` + statsColumns + `
+		FROM converted
+		GROUP BY type
+	`
+	rows, err := r.conn().QueryContext(ctx, sqlQuery, query.From.UTC(), query.To.UTC(), pq.Array(query.Percentiles), query.Currency)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	report := &domain.AnalyticsReport{Currency: query.Currency}
+	for rows.Next() {
+		var itemType string
+		analytics := domain.Analytics{}
+		var percentiles []float64
+		dest := append([]interface{}{&itemType}, statsDest(&analytics, &percentiles)...)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, wrapError(err)
+		}
+		analytics.Percentiles = domain.NewPercentileMap(query.Percentiles, percentiles)
+		switch itemType {
+		case domain.TypeIncome:
+			report.Income = analytics
+		case domain.TypeExpense:
+			report.Expense = analytics
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrapError(err)
+	}
+
+	report.Balance = report.Income.Sum.Sub(report.Expense.Sum)
+	return report, nil
+}
+
+func (r *repository) GetGroupedAnalytics(ctx context.Context, query domain.AnalyticsQuery) ([]*domain.AnalyticsGroup, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	columns := make([]string, 0, len(query.GroupBy))
+	for _, field := range query.GroupBy {
+		column, ok := groupColumns[field]
+		if !ok {
+			return nil, domain.NewValidationError("group_by", fmt.Sprintf("unknown group_by field '%s'", field))
+		}
+		columns = append(columns, column)
+	}
+	groupList := strings.Join(columns, ", ")
+
+	if err := r.checkRates(ctx, query.From.UTC(), query.To.UTC(), query.Currency); err != nil {
+		return nil, wrapError(err)
+	}
+
+	sqlQuery := convertedItems("$4") + `
+		SELECT
+			` + groupList + `,
+			COALESCE(SUM(amount) FILTER (WHERE type = 'income'), 0) as income,
+			COALESCE(SUM(amount) FILTER (WHERE type = 'expense'), 0) as expense,` + statsColumns + `
+		FROM converted
+		GROUP BY ` + groupList + `
+		ORDER BY ` + groupList
+	rows, err := r.conn().QueryContext(ctx, sqlQuery, query.From.UTC(), query.To.UTC(), pq.Array(query.Percentiles), query.Currency)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	var groups []*domain.AnalyticsGroup
+	for rows.Next() {
+		group := &domain.AnalyticsGroup{}
+		dest := make([]interface{}, 0, len(query.GroupBy)+12)
+		for _, field := range query.GroupBy {
+			switch {
+			case field == domain.GroupByCategory:
+				group.Category = new(string)
+				dest = append(dest, group.Category)
+			case field == domain.GroupByType:
+				group.Type = new(string)
+				dest = append(dest, group.Type)
+			case domain.IsPeriodGroup(field):
+				group.Period = new(time.Time)
+				dest = append(dest, group.Period)
+			}
+		}
+		var percentiles []float64
+		dest = append(dest, &group.Income, &group.Expense)
+		dest = append(dest, statsDest(&group.Analytics, &percentiles)...)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, wrapError(err)
+		}
+		group.Net = group.Income.Sub(group.Expense)
+		group.Percentiles = domain.NewPercentileMap(query.Percentiles, percentiles)
+		groups = append(groups, group)
+	}
+	return groups, wrapError(rows.Err())
+}
+
+// statsDest возвращает приемники для колонок statsColumns
+func statsDest(analytics *domain.Analytics, percentiles *[]float64) []interface{} {
+	return []interface{}{
+		&analytics.Sum,
+		&analytics.Avg,
+		&analytics.Count,
+		&analytics.Median,
+		&analytics.Percentile,
+		&analytics.Min,
+		&analytics.Max,
+		&analytics.StdDev,
+		&analytics.Variance,
+		pq.Array(percentiles),
+	}
+}
+
+// GetTimeSeries строит непрерывный ряд интервалов через generate_series.
+// Даты в таблице хранятся в UTC, границы интервалов считаются в часовом поясе запроса.
+func (r *repository) GetTimeSeries(ctx context.Context, query domain.TimeSeriesQuery) ([]*domain.TimeSeriesPoint, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	from, to, loc := query.From.UTC(), query.To.UTC(), query.Location
+	if err := r.checkRates(ctx, from, to, query.Currency); err != nil {
+		return nil, wrapError(err)
+	}
+
+	sqlQuery := convertedItems("$5") + `,
+		buckets AS (
+			SELECT generate_series(
+				date_trunc($3::text, $1::timestamp AT TIME ZONE 'UTC' AT TIME ZONE $4::text),
+				date_trunc($3::text, $2::timestamp AT TIME ZONE 'UTC' AT TIME ZONE $4::text),
+				('1 ' || $3::text)::interval
+			) AS bucket
+		)
+		SELECT
+			b.bucket AT TIME ZONE $4::text as start,
+			(b.bucket + ('1 ' || $3::text)::interval) AT TIME ZONE $4::text as end,
+			COALESCE(SUM(c.amount) FILTER (WHERE c.type = 'income'), 0) as income,
+			COALESCE(SUM(c.amount) FILTER (WHERE c.type = 'expense'), 0) as expense
+		FROM buckets b
+		LEFT JOIN converted c
+			ON date_trunc($3::text, c.date AT TIME ZONE 'UTC' AT TIME ZONE $4::text) = b.bucket
+		GROUP BY b.bucket
+		ORDER BY b.bucket
+	`
+	rows, err := r.conn().QueryContext(ctx, sqlQuery, from, to, query.Interval, loc.String(), query.Currency)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	var points []*domain.TimeSeriesPoint
+	for rows.Next() {
+		point := &domain.TimeSeriesPoint{}
+		if err := rows.Scan(&point.Start, &point.End, &point.Income, &point.Expense); err != nil {
+			return nil, wrapError(err)
+		}
+		point.Start = point.Start.In(loc)
+		point.End = point.End.In(loc)
+		point.Net = point.Income.Sub(point.Expense)
+		points = append(points, point)
+	}
+	return points, wrapError(rows.Err())
+}
@@ -0,0 +1,232 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ApplyItemBatch создает, обновляет и удаляет записи в одной транзакции.
+// Ошибки отдельных обновлений и удалений (нет записи, не совпала версия)
+// возвращаются в failed по id записи. Если partial = false и такие ошибки есть,
+// транзакция откатывается.
+func (r *repository) ApplyItemBatch(ctx context.Context, batch *domain.ItemBatch, partial bool) (map[int64]error, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer tx.Rollback()
+
+	if err := insertItems(ctx, tx.Tx, batch.Create); err != nil {
+		return nil, wrapError(err)
+	}
+	failed := make(map[int64]error)
+	if err := updateItems(ctx, tx.Tx, batch.Update, failed); err != nil {
+		return nil, wrapError(err)
+	}
+	if err := deleteItems(ctx, tx.Tx, batch.Delete, failed); err != nil {
+		return nil, wrapError(err)
+	}
+	if len(failed) > 0 && !partial {
+		return failed, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, wrapError(err)
+	}
+	return failed, nil
+}
+
+// insertItems загружает записи через COPY. Идентификаторы выделяются заранее
+// из последовательности, потому что COPY не возвращает вставленные строки.
+func insertItems(ctx context.Context, tx *sql.Tx, items []*domain.Item) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT nextval(pg_get_serial_sequence('items', 'id')) FROM generate_series(1, $1)`, len(items))
+	if err != nil {
+		return err
+	}
+	ids := make([]int64, 0, len(items))
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("items",
+		"id", "type", "amount", "currency", "category", "description", "date", "created_at", "updated_at"))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for i, item := range items {
+		if _, err := stmt.ExecContext(
+			ctx,
+			ids[i], item.Type, item.Amount, item.Currency, item.Category, item.Description, item.Date,
+			item.CreatedAt, item.UpdatedAt,
+		); err != nil {
+			return err
+		}
+	}
+	// Вызов без аргументов завершает COPY
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return err
+	}
+
+	for i, item := range items {
+		item.ID = ids[i]
+		item.Version = 1
+	}
+	return nil
+}
+
+// updateItems обновляет записи одним запросом. Записи, которых нет
+// или у которых не совпала версия, попадают в failed.
+func updateItems(ctx context.Context, tx *sql.Tx, items []*domain.Item, failed map[int64]error) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	n := len(items)
+	ids, versions := make([]int64, n), make([]int64, n)
+	types, amounts, currencies := make([]string, n), make([]string, n), make([]string, n)
+	categories, descriptions := make([]string, n), make([]string, n)
+	dates, updatedAt := make([]string, n), make([]string, n)
+	byID := make(map[int64]*domain.Item, n)
+	for i, item := range items {
+		ids[i], versions[i] = item.ID, item.Version
+		types[i], amounts[i], currencies[i] = item.Type, item.Amount.String(), item.Currency
+		categories[i], descriptions[i] = item.Category, item.Description
+		dates[i], updatedAt[i] = string(pq.FormatTimestamp(item.Date)), string(pq.FormatTimestamp(item.UpdatedAt))
+		byID[item.ID] = item
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE items AS i
+		SET type = u.type, amount = u.amount, currency = u.currency, category = u.category,
+			description = u.description, date = u.date, updated_at = u.updated_at, version = i.version + 1
+		FROM unnest(
+			$1::bigint[], $2::bigint[], $3::text[], $4::numeric[], $5::text[],
+			$6::text[], $7::text[], $8::timestamp[], $9::timestamp[]
+		) AS u(id, version, type, amount, currency, category, description, date, updated_at)
+		WHERE i.id = u.id AND i.deleted_at IS NULL AND (u.version = 0 OR i.version = u.version)
+		RETURNING i.id, i.created_at, i.version
+	`,
+		pq.Array(ids), pq.Array(versions), pq.Array(types), pq.Array(amounts), pq.Array(currencies),
+		pq.Array(categories), pq.Array(descriptions), pq.Array(dates), pq.Array(updatedAt),
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	updated := make(map[int64]bool, n)
+	for rows.Next() {
+		var id int64
+		var createdAt time.Time
+		var version int64
+		if err := rows.Scan(&id, &createdAt, &version); err != nil {
+			return err
+		}
+		byID[id].CreatedAt, byID[id].Version = createdAt, version
+		updated[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return classifyMissing(ctx, tx, ids, updated, failed)
+}
+
+// deleteItems переносит записи в корзину одним запросом. Записи, которых нет
+// или у которых не совпала версия, попадают в failed.
+func deleteItems(ctx context.Context, tx *sql.Tx, refs []domain.ItemRef, failed map[int64]error) error {
+	if len(refs) == 0 {
+		return nil
+	}
+
+	ids, versions := make([]int64, len(refs)), make([]int64, len(refs))
+	for i, ref := range refs {
+		ids[i], versions[i] = ref.ID, ref.Version
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE items AS i
+		SET deleted_at = $3, version = i.version + 1
+		FROM unnest($1::bigint[], $2::bigint[]) AS d(id, version)
+		WHERE i.id = d.id AND i.deleted_at IS NULL AND (d.version = 0 OR i.version = d.version)
+		RETURNING i.id
+	`, pq.Array(ids), pq.Array(versions), time.Now())
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	deleted := make(map[int64]bool, len(refs))
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		deleted[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return classifyMissing(ctx, tx, ids, deleted, failed)
+}
+
+// classifyMissing записывает в failed причину, по которой запись из ids не изменена:
+// запись существует и не в корзине — не совпала версия, иначе — запись не найдена
+func classifyMissing(ctx context.Context, tx *sql.Tx, ids []int64, changed map[int64]bool, failed map[int64]error) error {
+	var missing []int64
+	for _, id := range ids {
+		if !changed[id] {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM items WHERE id = ANY($1) AND deleted_at IS NULL`, pq.Array(missing))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	exists := make(map[int64]bool, len(missing))
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		exists[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range missing {
+		if exists[id] {
+			failed[id] = domain.ErrVersionMismatch
+		} else {
+			failed[id] = domain.NewNotFoundError("item not found")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"net"
+
+	"github.com/lib/pq"
+)
+
+// wrapError переводит ошибки драйвера в ошибки предметной области:
+// нарушения ограничений — в конфликт или ошибку валидации, проблемы
+// соединения и перегрузку сервера — в недоступность. Остальные ошибки
+// возвращаются как есть.
+func wrapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		return err
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch {
+		case pqErr.Code == "23505", pqErr.Code == "23503":
+			// unique_violation, foreign_key_violation
+			return domain.NewConflictError("record conflicts with existing data", err)
+		case pqErr.Code == "40001", pqErr.Code == "40P01":
+			// serialization_failure, deadlock_detected
+			return domain.NewConflictError("concurrent modification, please retry", err)
+		case pqErr.Code == "23514", pqErr.Code == "23502":
+			// check_violation, not_null_violation
+			return &domain.Error{Kind: domain.ErrValidation, Message: "value violates constraint " + pqErr.Constraint, Field: pqErr.Column, Err: err}
+		case pqErr.Code.Class() == "22":
+			// data_exception: переполнение числа, слишком длинная строка и т.п.
+			return &domain.Error{Kind: domain.ErrValidation, Message: pqErr.Message, Field: pqErr.Column, Err: err}
+		case pqErr.Code.Class() == "08", pqErr.Code.Class() == "53", pqErr.Code.Class() == "57":
+			// connection_exception, insufficient_resources, operator_intervention
+			return domain.NewUnavailableError(err)
+		}
+		return err
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) ||
+		errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return domain.NewUnavailableError(err)
+	}
+	return err
+}
+
+// isUniqueViolation проверяет, что запрос нарушил ограничение уникальности
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
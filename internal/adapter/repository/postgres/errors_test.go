@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestWrapError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantKind error
+	}{
+		{name: "unique violation", err: &pq.Error{Code: "23505"}, wantKind: domain.ErrConflict},
+		{name: "serialization failure", err: &pq.Error{Code: "40001"}, wantKind: domain.ErrConflict},
+		{name: "check violation", err: &pq.Error{Code: "23514", Constraint: "items_amount_check"}, wantKind: domain.ErrValidation},
+		{name: "numeric overflow", err: &pq.Error{Code: "22003"}, wantKind: domain.ErrValidation},
+		{name: "connection failure", err: &pq.Error{Code: "08006"}, wantKind: domain.ErrUnavailable},
+		{name: "too many connections", err: &pq.Error{Code: "53300"}, wantKind: domain.ErrUnavailable},
+		{name: "bad connection", err: fmt.Errorf("query: %w", driver.ErrBadConn), wantKind: domain.ErrUnavailable},
+		{name: "deadline exceeded", err: context.DeadlineExceeded, wantKind: domain.ErrUnavailable},
+		{name: "domain error passes through", err: domain.NewNotFoundError("item not found"), wantKind: domain.ErrNotFound},
+		{name: "undefined table", err: &pq.Error{Code: "42P01"}},
+		{name: "unknown error", err: errors.New("boom")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := wrapError(tt.err)
+			if !errors.Is(got, tt.err) {
+				t.Errorf("wrapError() = %v, lost original error", got)
+			}
+			if tt.wantKind == nil {
+				if got != tt.err {
+					t.Errorf("wrapError() = %v, want unchanged error", got)
+				}
+				return
+			}
+			if !errors.Is(got, tt.wantKind) {
+				t.Errorf("wrapError() = %v, want kind %v", got, tt.wantKind)
+			}
+		})
+	}
+
+	if wrapError(nil) != nil {
+		t.Error("wrapError(nil) != nil")
+	}
+}
+
+func TestIsSerializationFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "serialization failure", err: &pq.Error{Code: "40001"}, want: true},
+		{name: "deadlock", err: &pq.Error{Code: "40P01"}, want: true},
+		{name: "wrapped by wrapError", err: wrapError(&pq.Error{Code: "40001"}), want: true},
+		{name: "unique violation", err: &pq.Error{Code: "23505"}},
+		{name: "domain error", err: domain.NewNotFoundError("item not found")},
+		{name: "nil", err: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isSerializationFailure(tt.err); got != tt.want {
+				t.Errorf("isSerializationFailure() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// AddHistory сохраняет записи истории одним запросом
+func (r *repository) AddHistory(ctx context.Context, entries []*domain.HistoryEntry) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	n := len(entries)
+	itemIDs, actions, actors := make([]int64, n), make([]string, n), make([]string, n)
+	before, after := make([]sql.NullString, n), make([]sql.NullString, n)
+	createdAt := make([]string, n)
+	for i, entry := range entries {
+		itemIDs[i], actions[i], actors[i] = entry.ItemID, entry.Action, entry.Actor
+		createdAt[i] = string(pq.FormatTimestamp(entry.CreatedAt.UTC()))
+		var err error
+		if before[i], err = itemSnapshot(entry.Before); err != nil {
+			return err
+		}
+		if after[i], err = itemSnapshot(entry.After); err != nil {
+			return err
+		}
+	}
+
+	_, err := r.conn().ExecContext(ctx, `
+		INSERT INTO item_history (item_id, action, actor, before, after, created_at)
+		SELECT * FROM unnest($1::bigint[], $2::text[], $3::text[], $4::jsonb[], $5::jsonb[], $6::timestamp[])
+	`,
+		pq.Array(itemIDs), pq.Array(actions), pq.Array(actors),
+		pq.Array(before), pq.Array(after), pq.Array(createdAt),
+	)
+	return wrapError(err)
+}
+
+// itemSnapshot сериализует состояние записи для колонок before и after
+func itemSnapshot(item *domain.Item) (sql.NullString, error) {
+	if item == nil {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(item)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("failed to marshal item snapshot: %w", err)
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// GetHistory возвращает страницу истории изменений от новых к старым
+func (r *repository) GetHistory(ctx context.Context, filter domain.HistoryFilter) (*domain.HistoryPage, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	cursor, err := filter.DecodeCursor()
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	var conditions []string
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if filter.ItemID != 0 {
+		conditions = append(conditions, "item_id = "+arg(filter.ItemID))
+	}
+	if filter.Actor != "" {
+		conditions = append(conditions, "actor = "+arg(filter.Actor))
+	}
+	if filter.From != nil {
+		conditions = append(conditions, "created_at >= "+arg(filter.From.UTC()))
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "created_at <= "+arg(filter.To.UTC()))
+	}
+	if cursor != 0 {
+		conditions = append(conditions, "id < "+arg(cursor))
+	}
+	query := `SELECT id, item_id, action, actor, before, after, created_at FROM item_history`
+	if len(conditions) > 0 {
+		query += "\n\t\tWHERE " + strings.Join(conditions, " AND ")
+	}
+	// Лишняя запись показывает, есть ли следующая страница
+	query += "\n\t\tORDER BY id DESC LIMIT " + arg(filter.Limit+1)
+
+	rows, err := r.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	page := &domain.HistoryPage{Entries: []*domain.HistoryEntry{}}
+	for rows.Next() {
+		entry := &domain.HistoryEntry{}
+		var before, after []byte
+		if err := rows.Scan(&entry.ID, &entry.ItemID, &entry.Action, &entry.Actor, &before, &after, &entry.CreatedAt); err != nil {
+			return nil, wrapError(err)
+		}
+		if entry.Before, err = parseSnapshot(before); err != nil {
+			return nil, err
+		}
+		if entry.After, err = parseSnapshot(after); err != nil {
+			return nil, err
+		}
+		page.Entries = append(page.Entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrapError(err)
+	}
+
+	if len(page.Entries) > filter.Limit {
+		page.Entries = page.Entries[:filter.Limit]
+		page.NextCursor = strconv.FormatInt(page.Entries[filter.Limit-1].ID, 10)
+	}
+	return page, nil
+}
+
+// parseSnapshot восстанавливает запись из колонки before или after
+func parseSnapshot(data []byte) (*domain.Item, error) {
+	if data == nil {
+		return nil, nil
+	}
+	item := &domain.Item{}
+	if err := json.Unmarshal(data, item); err != nil {
+		return nil, fmt.Errorf("failed to parse item snapshot: %w", err)
+	}
+	return item, nil
+}
@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+)
+
+// ReserveIdempotencyKey удаляет истекшие ключи и сохраняет новый. Незавершенный ключ
+// с истекшей арендой перехватывается. Если ключ уже есть, возвращает сохраненную запись.
+func (r *repository) ReserveIdempotencyKey(ctx context.Context, record *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	if _, err := r.conn().ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at < $1`, record.CreatedAt); err != nil {
+		return nil, wrapError(err)
+	}
+
+	// Незавершенный ключ с истекшей арендой перезаписывается: первый запрос
+	// не сохранил ответ вовремя. Из параллельных повторов ключ получает один.
+	result, err := r.conn().ExecContext(ctx, `
+		INSERT INTO idempotency_keys (key, request_hash, created_at, locked_until, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash,
+			created_at = EXCLUDED.created_at,
+			locked_until = EXCLUDED.locked_until,
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.response IS NULL AND idempotency_keys.locked_until < EXCLUDED.created_at
+	`, record.Key, record.RequestHash, record.CreatedAt, record.LockedUntil, record.ExpiresAt)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	reserved, err := result.RowsAffected()
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	if reserved == 1 {
+		return nil, nil
+	}
+
+	existing := &domain.IdempotencyRecord{}
+	err = r.conn().QueryRowContext(ctx, `
+		SELECT key, request_hash, response, created_at, locked_until, expires_at
+		FROM idempotency_keys
+		WHERE key = $1
+	`, record.Key).Scan(&existing.Key, &existing.RequestHash, &existing.Response, &existing.CreatedAt, &existing.LockedUntil, &existing.ExpiresAt)
+	if err == sql.ErrNoRows {
+		// Ключ удалили между вставкой и чтением: первый запрос завершился ошибкой
+		return nil, domain.NewConflictError("concurrent request with this idempotency key failed, please retry", err)
+	}
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return existing, nil
+}
+
+// CompleteIdempotencyKey сохраняет ответ, если резервирование record не перехвачено.
+// Резервирование узнается по locked_until.
+func (r *repository) CompleteIdempotencyKey(ctx context.Context, record *domain.IdempotencyRecord, response []byte) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.conn().ExecContext(ctx, `
+		UPDATE idempotency_keys SET response = $1
+		WHERE key = $2 AND locked_until = $3 AND response IS NULL
+	`, response, record.Key, record.LockedUntil)
+	if err != nil {
+		return wrapError(err)
+	}
+	completed, err := result.RowsAffected()
+	if err != nil {
+		return wrapError(err)
+	}
+	if completed == 0 {
+		return domain.ErrIdempotencyKeyTakenOver
+	}
+	return nil
+}
+
+// ReleaseIdempotencyKey удаляет резервирование record, чтобы запрос можно было повторить
+func (r *repository) ReleaseIdempotencyKey(ctx context.Context, record *domain.IdempotencyRecord) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.conn().ExecContext(ctx, `
+		DELETE FROM idempotency_keys
+		WHERE key = $1 AND locked_until = $2 AND response IS NULL
+	`, record.Key, record.LockedUntil)
+	return wrapError(err)
+}
@@ -0,0 +1,207 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// sortColumns сопоставляет поля сортировки с колонками и SQL-типами значений курсора
+var sortColumns = map[string][2]string{
+	domain.SortByDate:      {"date", "timestamp"},
+	domain.SortByAmount:    {"amount", "numeric"},
+	domain.SortByCategory:  {"category", "text"},
+	domain.SortByCreatedAt: {"created_at", "timestamp"},
+}
+
+// itemQuery содержит части запроса списка записей, общие для GetAll и StreamAll
+type itemQuery struct {
+	filter     domain.ItemFilter
+	conditions []string
+	args       []interface{}
+	columns    string    // колонки выборки, при поиске — с рангом и фрагментом
+	sortColumn [2]string // выражение сортировки и SQL-тип значения курсора
+}
+
+// newItemQuery строит условия выборки по фильтру без учета курсора и размера страницы
+func newItemQuery(filter domain.ItemFilter) (*itemQuery, error) {
+	sortColumn, ok := sortColumns[filter.Sort.Field]
+	if filter.Sort.Field == domain.SortByRank && filter.Query != "" {
+		ok = true // выражение ранга зависит от запроса и подставляется ниже
+	}
+	if !ok {
+		return nil, domain.NewValidationError("sort", fmt.Sprintf("unknown sort field '%s'", filter.Sort.Field))
+	}
+
+	q := &itemQuery{filter: filter, columns: itemColumns, sortColumn: sortColumn}
+	if filter.Deleted {
+		q.conditions = append(q.conditions, "deleted_at IS NOT NULL")
+	} else {
+		q.conditions = append(q.conditions, "deleted_at IS NULL")
+	}
+	if filter.From != nil {
+		q.conditions = append(q.conditions, "date >= "+q.arg(filter.From.UTC()))
+	}
+	if filter.To != nil {
+		q.conditions = append(q.conditions, "date <= "+q.arg(filter.To.UTC()))
+	}
+	if len(filter.Types) > 0 {
+		q.conditions = append(q.conditions, "type = ANY("+q.arg(pq.Array(filter.Types))+")")
+	}
+	if len(filter.Categories) > 0 {
+		q.conditions = append(q.conditions, "category = ANY("+q.arg(pq.Array(filter.Categories))+")")
+	}
+	if filter.MinAmount != nil {
+		q.conditions = append(q.conditions, "amount >= "+q.arg(*filter.MinAmount))
+	}
+	if filter.MaxAmount != nil {
+		q.conditions = append(q.conditions, "amount <= "+q.arg(*filter.MaxAmount))
+	}
+
+	// При поиске к записи добавляются ранг и фрагмент с подсветкой совпадений
+	if filter.Query != "" {
+		tsQuery := "websearch_to_tsquery('simple', " + q.arg(filter.Query) + ")"
+		q.conditions = append(q.conditions, "search_vector @@ "+tsQuery)
+		rankExpr := "ts_rank(search_vector, " + tsQuery + ")"
+		q.columns += `,
+			` + rankExpr + ` as rank,
+			ts_headline('simple', category || ' ' || description, ` + tsQuery + `,
+				'StartSel=<mark>, StopSel=</mark>, MaxFragments=2') as snippet`
+		if filter.Sort.Field == domain.SortByRank {
+			q.sortColumn = [2]string{rankExpr, "real"}
+		}
+	}
+	return q, nil
+}
+
+// arg добавляет параметр запроса и возвращает его плейсхолдер
+func (q *itemQuery) arg(value interface{}) string {
+	q.args = append(q.args, value)
+	return fmt.Sprintf("$%d", len(q.args))
+}
+
+// where возвращает условия фильтра, объединенные через AND
+func (q *itemQuery) where() string {
+	if len(q.conditions) == 0 {
+		return ""
+	}
+	return "\n\t\tWHERE " + strings.Join(q.conditions, " AND ")
+}
+
+// after добавляет условие keyset-пагинации: записи после пары (поле сортировки, id) из курсора
+func (q *itemQuery) after(cursor *domain.Cursor) {
+	operator := ">"
+	if q.filter.Sort.Desc {
+		operator = "<"
+	}
+	q.conditions = append(q.conditions, fmt.Sprintf("(%s, id) %s (%s::%s, %s)",
+		q.sortColumn[0], operator, q.arg(cursor.Value), q.sortColumn[1], q.arg(cursor.ID)))
+}
+
+// selectSQL возвращает запрос выборки записей в порядке сортировки фильтра
+func (q *itemQuery) selectSQL() string {
+	direction := "ASC"
+	if q.filter.Sort.Desc {
+		direction = "DESC"
+	}
+	return `
+		SELECT ` + q.columns + `
+		FROM items` + q.where() + `
+		ORDER BY ` + q.sortColumn[0] + ` ` + direction + `, id ` + direction
+}
+
+// scan читает запись из результата selectSQL
+func (q *itemQuery) scan(rows *sql.Rows) (*domain.Item, error) {
+	if q.filter.Query == "" {
+		return scanItem(rows)
+	}
+	match := &domain.SearchMatch{}
+	item, err := scanItem(rows, &match.Rank, &match.Snippet)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	item.Match = match
+	return item, nil
+}
+
+// GetAll возвращает страницу записей по фильтру. Пагинация keyset: следующая
+// страница начинается после пары (поле сортировки, id) из курсора.
+func (r *repository) GetAll(ctx context.Context, filter domain.ItemFilter) (*domain.ItemPage, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	cursor, err := filter.DecodeCursor()
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	q, err := newItemQuery(filter)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	page := &domain.ItemPage{}
+	countQuery := `SELECT COUNT(*) FROM items` + q.where()
+	if err := r.conn().QueryRowContext(ctx, countQuery, q.args...).Scan(&page.Total); err != nil {
+		return nil, wrapError(err)
+	}
+
+	if cursor != nil {
+		q.after(cursor)
+	}
+	// Запрашиваем на одну запись больше, чтобы узнать, есть ли следующая страница
+	query := q.selectSQL() + `
+		LIMIT ` + q.arg(filter.Limit+1)
+	rows, err := r.conn().QueryContext(ctx, query, q.args...)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := q.scan(rows)
+		if err != nil {
+			return nil, wrapError(err)
+		}
+		page.Items = append(page.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrapError(err)
+	}
+
+	if len(page.Items) > filter.Limit {
+		page.Items = page.Items[:filter.Limit]
+		page.NextCursor = domain.NewCursor(page.Items[filter.Limit-1], filter.Sort)
+	}
+	return page, nil
+}
+
+// StreamAll передает в fn все записи по фильтру в порядке сортировки, не загружая
+// их в память целиком. Курсор и размер страницы не учитываются.
+// Ошибка fn прерывает выборку и возвращается как есть.
+func (r *repository) StreamAll(ctx context.Context, filter domain.ItemFilter, fn func(*domain.Item) error) error {
+	q, err := newItemQuery(filter)
+	if err != nil {
+		return wrapError(err)
+	}
+
+	rows, err := r.conn().QueryContext(ctx, q.selectSQL(), q.args...)
+	if err != nil {
+		return wrapError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := q.scan(rows)
+		if err != nil {
+			return wrapError(err)
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return wrapError(rows.Err())
+}
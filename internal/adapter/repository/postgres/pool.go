@@ -0,0 +1,112 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"log"
+	"time"
+)
+
+// maxConnectDelay ограничивает рост паузы между попытками подключения
+const maxConnectDelay = 10 * time.Second
+
+// Option настраивает пул соединений и таймауты репозитория
+type Option func(*options)
+
+type options struct {
+	maxOpenConns    int
+	maxIdleConns    int
+	connMaxLifetime time.Duration
+	queryTimeout    time.Duration
+	connectTimeout  time.Duration
+	connectDelay    time.Duration
+}
+
+// WithPool задает размер пула и время жизни соединения. Нулевые значения
+// оставляют настройки database/sql по умолчанию.
+func WithPool(maxOpen, maxIdle int, maxLifetime time.Duration) Option {
+	return func(o *options) {
+		o.maxOpenConns = maxOpen
+		o.maxIdleConns = maxIdle
+		o.connMaxLifetime = maxLifetime
+	}
+}
+
+// WithQueryTimeout ограничивает время выполнения каждой операции репозитория,
+// кроме StreamAll: выгрузка длится столько, сколько клиент читает ответ
+func WithQueryTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.queryTimeout = timeout
+	}
+}
+
+// WithConnectRetry задает, сколько New ждет доступности базы. Попытки
+// повторяются с паузой delay, которая удваивается после каждой неудачи.
+// Без этой опции New проверяет соединение один раз.
+func WithConnectRetry(timeout, delay time.Duration) Option {
+	return func(o *options) {
+		o.connectTimeout = timeout
+		o.connectDelay = delay
+	}
+}
+
+// connect проверяет соединение с базой, повторяя попытки с экспоненциальной
+// паузой, пока не истечет timeout. При нулевом timeout попытка одна.
+func connect(db *sql.DB, timeout, delay time.Duration) error {
+	if timeout <= 0 || delay <= 0 {
+		return db.Ping()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	deadline, _ := ctx.Deadline()
+
+	for attempt := 1; ; attempt++ {
+		err := db.PingContext(ctx)
+		if err == nil {
+			return nil
+		}
+		if time.Now().Add(delay).After(deadline) {
+			return err
+		}
+		log.Printf("Database is not available (attempt %d): %v, retrying in %s", attempt, err, delay)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay = nextConnectDelay(delay)
+	}
+}
+
+// nextConnectDelay удваивает паузу между попытками подключения до maxConnectDelay
+func nextConnectDelay(delay time.Duration) time.Duration {
+	if delay*2 > maxConnectDelay {
+		return maxConnectDelay
+	}
+	return delay * 2
+}
+
+// withTimeout ограничивает время операции queryTimeout, если он задан
+func (r *repository) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.queryTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, r.queryTimeout)
+}
+
+// PoolStats возвращает состояние пула соединений
+func (r *repository) PoolStats() domain.PoolStats {
+	stats := r.db.Stats()
+	return domain.PoolStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDurationMs:     stats.WaitDuration.Milliseconds(),
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	}
+}
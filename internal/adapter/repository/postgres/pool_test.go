@@ -0,0 +1,41 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextConnectDelay(t *testing.T) {
+	tests := []struct {
+		delay time.Duration
+		want  time.Duration
+	}{
+		{delay: 500 * time.Millisecond, want: time.Second},
+		{delay: 4 * time.Second, want: 8 * time.Second},
+		{delay: 8 * time.Second, want: maxConnectDelay},
+		{delay: maxConnectDelay, want: maxConnectDelay},
+	}
+
+	for _, tt := range tests {
+		if got := nextConnectDelay(tt.delay); got != tt.want {
+			t.Errorf("nextConnectDelay(%v) = %v, want %v", tt.delay, got, tt.want)
+		}
+	}
+}
+
+func TestNew_ConnectRetry(t *testing.T) {
+	// На порту 1 никто не слушает, поэтому каждая попытка сразу завершается ошибкой
+	dsn := "host=127.0.0.1 port=1 user=postgres password=postgres dbname=analytics sslmode=disable"
+
+	start := time.Now()
+	_, err := New(dsn, WithConnectRetry(300*time.Millisecond, 50*time.Millisecond))
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Fatal("New() expected error for unavailable database")
+	}
+	// Паузы 50 и 100 мс укладываются в 300 мс, следующая пауза 200 мс — уже нет
+	if elapsed < 150*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("New() gave up after %v, want about 150ms of retries", elapsed)
+	}
+}
@@ -8,145 +8,241 @@ import (
 	"github.com/dontpanicw/SalesTracker/internal/port"
 	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 type repository struct {
-	db *sql.DB
+	db           *sql.DB
+	tx           *sql.Tx       // транзакция WithinTx; nil, если запросы выполняются через пул
+	queryTimeout time.Duration // 0 — без ограничения
 }
 
 // New создает новый экземпляр PostgreSQL репозитория
-func New(dsn string) (port.Repository, error) {
+func New(dsn string, opts ...Option) (port.Repository, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	db, err := sql.Open("postgres", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	db.SetMaxOpenConns(o.maxOpenConns)
+	if o.maxIdleConns > 0 {
+		db.SetMaxIdleConns(o.maxIdleConns)
+	}
+	db.SetConnMaxLifetime(o.connMaxLifetime)
 
-	if err := db.Ping(); err != nil {
+	if err := connect(db, o.connectTimeout, o.connectDelay); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return &repository{db: db}, nil
+	return &repository{db: db, queryTimeout: o.queryTimeout}, nil
+}
+
+// itemColumns — колонки записи в порядке полей, которые читает scanItem
+const itemColumns = `id, type, amount, currency, category, description, date, created_at, updated_at, version, deleted_at`
+
+// rowScanner — общий интерфейс *sql.Row и *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanItem читает колонки itemColumns и дополнительные приемники extra
+func scanItem(row rowScanner, extra ...interface{}) (*domain.Item, error) {
+	item := &domain.Item{}
+	dest := append([]interface{}{
+		&item.ID, &item.Type, &item.Amount, &item.Currency, &item.Category,
+		&item.Description, &item.Date, &item.CreatedAt, &item.UpdatedAt, &item.Version, &item.DeletedAt,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return item, nil
 }
 
 func (r *repository) Create(ctx context.Context, item *domain.Item) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	query := `
-		INSERT INTO items (type, amount, category, date, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id
+		INSERT INTO items (type, amount, currency, category, description, date, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, version
 	`
-	return r.db.QueryRowContext(
+	err := r.conn().QueryRowContext(
 		ctx, query,
-		item.Type, item.Amount, item.Category, item.Date,
+		item.Type, item.Amount, item.Currency, item.Category, item.Description, item.Date,
 		item.CreatedAt, item.UpdatedAt,
-	).Scan(&item.ID)
+	).Scan(&item.ID, &item.Version)
+	return wrapError(err)
+}
+
+// CreateMany загружает записи одной транзакцией через COPY
+func (r *repository) CreateMany(ctx context.Context, items []*domain.Item) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return wrapError(err)
+	}
+	defer tx.Rollback()
+
+	if err := insertItems(ctx, tx.Tx, items); err != nil {
+		return wrapError(err)
+	}
+	return wrapError(tx.Commit())
 }
 
 func (r *repository) GetByID(ctx context.Context, id int64) (*domain.Item, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	query := `
-		SELECT id, type, amount, category, date, created_at, updated_at
+		SELECT ` + itemColumns + `
 		FROM items
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
-	item := &domain.Item{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&item.ID, &item.Type, &item.Amount, &item.Category,
-		&item.Date, &item.CreatedAt, &item.UpdatedAt,
-	)
+	item, err := scanItem(r.conn().QueryRowContext(ctx, query, id))
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("item not found")
+		return nil, domain.NewNotFoundError("item not found")
 	}
-	return item, err
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return item, nil
 }
 
-func (r *repository) GetAll(ctx context.Context, from, to *time.Time) ([]*domain.Item, error) {
+// GetByIDs возвращает действующие записи с указанными id, отсутствующие пропускаются
+func (r *repository) GetByIDs(ctx context.Context, ids []int64) ([]*domain.Item, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
 	query := `
-		SELECT id, type, amount, category, date, created_at, updated_at
+		SELECT ` + itemColumns + `
 		FROM items
-		WHERE ($1::timestamp IS NULL OR date >= $1)
-		  AND ($2::timestamp IS NULL OR date <= $2)
-		ORDER BY date DESC
+		WHERE id = ANY($1) AND deleted_at IS NULL
 	`
-	rows, err := r.db.QueryContext(ctx, query, from, to)
+	rows, err := r.conn().QueryContext(ctx, query, pq.Array(ids))
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	defer rows.Close()
 
 	var items []*domain.Item
 	for rows.Next() {
-		item := &domain.Item{}
-		if err := rows.Scan(
-			&item.ID, &item.Type, &item.Amount, &item.Category,
-			&item.Date, &item.CreatedAt, &item.UpdatedAt,
-		); err != nil {
-			return nil, err
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, wrapError(err)
 		}
 		items = append(items, item)
 	}
-	return items, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, wrapError(err)
+	}
+	return items, nil
 }
 
+// Update сохраняет запись и заполняет CreatedAt и новую версию значениями из базы
 func (r *repository) Update(ctx context.Context, item *domain.Item) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	query := `
 		UPDATE items
-		SET type = $1, amount = $2, category = $3, date = $4, updated_at = $5
-		WHERE id = $6
+		SET type = $1, amount = $2, currency = $3, category = $4, description = $5, date = $6,
+			updated_at = $7, version = version + 1
+		WHERE id = $8 AND deleted_at IS NULL AND ($9::bigint = 0 OR version = $9)
+		RETURNING created_at, version
 	`
-	result, err := r.db.ExecContext(
+	err := r.conn().QueryRowContext(
 		ctx, query,
-		item.Type, item.Amount, item.Category, item.Date,
-		item.UpdatedAt, item.ID,
-	)
+		item.Type, item.Amount, item.Currency, item.Category, item.Description, item.Date,
+		item.UpdatedAt, item.ID, item.Version,
+	).Scan(&item.CreatedAt, &item.Version)
+	if err == sql.ErrNoRows {
+		return r.missingItemError(ctx, item.ID, false)
+	}
+	return wrapError(err)
+}
+
+// Delete переносит запись в корзину: запись остается в базе с отметкой deleted_at
+func (r *repository) Delete(ctx context.Context, id int64, version int64) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE items
+		SET deleted_at = $3, version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL AND ($2::bigint = 0 OR version = $2)
+	`
+	result, err := r.conn().ExecContext(ctx, query, id, version, time.Now())
 	if err != nil {
-		return err
+		return wrapError(err)
 	}
 	rows, err := result.RowsAffected()
 	if err != nil {
-		return err
+		return wrapError(err)
 	}
 	if rows == 0 {
-		return fmt.Errorf("item not found")
+		return r.missingItemError(ctx, id, false)
 	}
 	return nil
 }
 
-func (r *repository) Delete(ctx context.Context, id int64) error {
-	query := `DELETE FROM items WHERE id = $1`
-	result, err := r.db.ExecContext(ctx, query, id)
-	if err != nil {
-		return err
+// Restore возвращает запись из корзины
+func (r *repository) Restore(ctx context.Context, id int64, version int64) (*domain.Item, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE items
+		SET deleted_at = NULL, version = version + 1
+		WHERE id = $1 AND deleted_at IS NOT NULL AND ($2::bigint = 0 OR version = $2)
+		RETURNING ` + itemColumns
+	item, err := scanItem(r.conn().QueryRowContext(ctx, query, id, version))
+	if err == sql.ErrNoRows {
+		return nil, r.missingItemError(ctx, id, true)
 	}
-	rows, err := result.RowsAffected()
 	if err != nil {
-		return err
+		return nil, wrapError(err)
 	}
-	if rows == 0 {
-		return fmt.Errorf("item not found")
+	return item, nil
+}
+
+// PurgeDeleted окончательно удаляет записи, перенесенные в корзину раньше before
+func (r *repository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.conn().ExecContext(ctx, `DELETE FROM items WHERE deleted_at < $1`, before)
+	if err != nil {
+		return 0, wrapError(err)
 	}
-	return nil
+	purged, err := result.RowsAffected()
+	return purged, wrapError(err)
 }
 
-func (r *repository) GetAnalytics(ctx context.Context, from, to time.Time) (*domain.Analytics, error) {
-	query := `
-		SELECT 
-			COALESCE(SUM(amount), 0) as sum,
-			COALESCE(AVG(amount), 0) as avg,
-			COUNT(*) as count,
-			COALESCE(PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY amount), 0) as median,
-			COALESCE(PERCENTILE_CONT(0.9) WITHIN GROUP (ORDER BY amount), 0) as percentile_90
-		FROM items
-		WHERE date >= $1 AND date <= $2
-	`
-	analytics := &domain.Analytics{}
-	err := r.db.QueryRowContext(ctx, query, from, to).Scan(
-		&analytics.Sum,
-		&analytics.Avg,
-		&analytics.Count,
-		&analytics.Median,
-		&analytics.Percentile,
-	)
-	return analytics, err
+// missingItemError объясняет, почему условное изменение не затронуло ни одной строки:
+// записи нет или ее версия не совпала с ожидаемой. deleted указывает,
+// где искать запись: в корзине или среди действующих.
+func (r *repository) missingItemError(ctx context.Context, id int64, deleted bool) error {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM items WHERE id = $1 AND (deleted_at IS NOT NULL) = $2)`
+	err := r.conn().QueryRowContext(ctx, query, id, deleted).Scan(&exists)
+	if err != nil {
+		return wrapError(err)
+	}
+	if exists {
+		return domain.ErrVersionMismatch
+	}
+	return domain.NewNotFoundError("item not found")
 }
 
 func (r *repository) Close() error {
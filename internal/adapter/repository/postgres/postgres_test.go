@@ -3,7 +3,12 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"github.com/dontpanicw/SalesTracker/internal/adapter/repository/repotest"
 	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"github.com/dontpanicw/SalesTracker/internal/port"
+	"github.com/dontpanicw/SalesTracker/pkg/migrations"
+	"math"
 	"os"
 	"testing"
 	"time"
@@ -29,266 +34,108 @@ func setupTestDB(t *testing.T) (*sql.DB, func()) {
 		return nil, nil
 	}
 
-	// Create test table
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS items (
-			id BIGSERIAL PRIMARY KEY,
-			type VARCHAR(20) NOT NULL CHECK (type IN ('income', 'expense')),
-			amount DECIMAL(15, 2) NOT NULL CHECK (amount >= 0),
-			category VARCHAR(100) NOT NULL,
-			date TIMESTAMP NOT NULL,
-			created_at TIMESTAMP NOT NULL DEFAULT NOW(),
-			updated_at TIMESTAMP NOT NULL DEFAULT NOW()
-		)
-	`)
+	// Схема создается теми же встроенными миграциями, что и в приложении.
+	// Откат в начале убирает данные прошлого прерванного запуска.
+	ctx := context.Background()
+	migrator, err := migrations.New(db)
 	if err != nil {
-		t.Fatalf("Failed to create test table: %v", err)
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+	if _, err := migrator.Down(ctx, math.MaxInt32); err != nil {
+		t.Fatalf("Failed to roll back migrations: %v", err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Failed to apply migrations: %v", err)
 	}
 
 	cleanup := func() {
-		db.Exec("DROP TABLE IF EXISTS items")
+		if _, err := migrator.Down(ctx, math.MaxInt32); err != nil {
+			t.Errorf("Failed to roll back migrations: %v", err)
+		}
+		db.Exec("DROP TABLE IF EXISTS schema_migrations")
 		db.Close()
 	}
 
 	return db, cleanup
 }
 
-func TestRepository_Create(t *testing.T) {
-	db, cleanup := setupTestDB(t)
-	defer cleanup()
-
-	repo := &repository{db: db}
-	ctx := context.Background()
-
-	item := &domain.Item{
-		Type:      "income",
-		Amount:    1000.50,
-		Category:  "Salary",
-		Date:      time.Now(),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-	}
-
-	err := repo.Create(ctx, item)
-	if err != nil {
-		t.Fatalf("Create() error = %v", err)
-	}
-
-	if item.ID == 0 {
-		t.Error("Create() did not set ID")
-	}
+func TestRepository(t *testing.T) {
+	repotest.RunRepositorySuite(t, func(t *testing.T) port.Repository {
+		db, cleanup := setupTestDB(t)
+		t.Cleanup(cleanup)
+		return &repository{db: db}
+	})
 }
 
-func TestRepository_GetByID(t *testing.T) {
+func TestRepository_WithinTx(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
 	repo := &repository{db: db}
 	ctx := context.Background()
-
-	// Create test item
-	item := &domain.Item{
-		Type:      "expense",
-		Amount:    500.00,
-		Category:  "Food",
-		Date:      time.Now(),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-	}
-	repo.Create(ctx, item)
-
-	// Get item
-	got, err := repo.GetByID(ctx, item.ID)
-	if err != nil {
-		t.Fatalf("GetByID() error = %v", err)
-	}
-
-	if got.ID != item.ID {
-		t.Errorf("GetByID() ID = %v, want %v", got.ID, item.ID)
-	}
-	if got.Type != item.Type {
-		t.Errorf("GetByID() Type = %v, want %v", got.Type, item.Type)
-	}
-	if got.Amount != item.Amount {
-		t.Errorf("GetByID() Amount = %v, want %v", got.Amount, item.Amount)
-	}
-}
-
-func TestRepository_GetAll(t *testing.T) {
-	db, cleanup := setupTestDB(t)
-	defer cleanup()
-
-	repo := &repository{db: db}
-	ctx := context.Background()
-
-	// Create test items
 	now := time.Now()
-	items := []*domain.Item{
-		{Type: "income", Amount: 1000.00, Category: "Salary", Date: now, CreatedAt: now, UpdatedAt: now},
-		{Type: "expense", Amount: 500.00, Category: "Food", Date: now.AddDate(0, 0, -1), CreatedAt: now, UpdatedAt: now},
-		{Type: "income", Amount: 2000.00, Category: "Bonus", Date: now.AddDate(0, 0, -2), CreatedAt: now, UpdatedAt: now},
-	}
-
-	for _, item := range items {
-		repo.Create(ctx, item)
-	}
-
-	// Get all items
-	got, err := repo.GetAll(ctx, nil, nil)
-	if err != nil {
-		t.Fatalf("GetAll() error = %v", err)
-	}
-
-	if len(got) != len(items) {
-		t.Errorf("GetAll() returned %d items, want %d", len(got), len(items))
+	newItem := func() *domain.Item {
+		return &domain.Item{Type: "expense", Amount: domain.NewMoney(100, 0), Currency: "RUB", Category: "Food", Date: now, CreatedAt: now, UpdatedAt: now}
 	}
-}
-
-func TestRepository_Update(t *testing.T) {
-	db, cleanup := setupTestDB(t)
-	defer cleanup()
-
-	repo := &repository{db: db}
-	ctx := context.Background()
 
-	// Create test item
-	item := &domain.Item{
-		Type:      "income",
-		Amount:    1000.00,
-		Category:  "Salary",
-		Date:      time.Now(),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+	// Ошибка fn откатывает все операции транзакции
+	boom := errors.New("boom")
+	err := repo.WithinTx(ctx, func(ctx context.Context, tx port.Repository) error {
+		if err := tx.Create(ctx, newItem()); err != nil {
+			return err
+		}
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("WithinTx() error = %v, want %v", err, boom)
 	}
-	repo.Create(ctx, item)
 
-	// Update item
-	item.Amount = 1500.00
-	item.Category = "Salary + Bonus"
-	item.UpdatedAt = time.Now()
-
-	err := repo.Update(ctx, item)
+	// Откат пакета внутри транзакции не отменяет предыдущие операции
+	created := newItem()
+	err = repo.WithinTx(ctx, func(ctx context.Context, tx port.Repository) error {
+		if err := tx.Create(ctx, created); err != nil {
+			return err
+		}
+		failed, err := tx.ApplyItemBatch(ctx, &domain.ItemBatch{
+			Create: []*domain.Item{newItem()},
+			Delete: []domain.ItemRef{{ID: 999999}},
+		}, false)
+		if err != nil {
+			return err
+		}
+		if len(failed) != 1 {
+			t.Errorf("ApplyItemBatch() failed = %v, want one missing item", failed)
+		}
+		return tx.AddHistory(ctx, []*domain.HistoryEntry{{ItemID: created.ID, Action: domain.HistoryCreate, Actor: "test", After: created, CreatedAt: now}})
+	})
 	if err != nil {
-		t.Fatalf("Update() error = %v", err)
-	}
-
-	// Verify update
-	got, _ := repo.GetByID(ctx, item.ID)
-	if got.Amount != 1500.00 {
-		t.Errorf("Update() Amount = %v, want %v", got.Amount, 1500.00)
-	}
-	if got.Category != "Salary + Bonus" {
-		t.Errorf("Update() Category = %v, want %v", got.Category, "Salary + Bonus")
-	}
-}
-
-func TestRepository_Delete(t *testing.T) {
-	db, cleanup := setupTestDB(t)
-	defer cleanup()
-
-	repo := &repository{db: db}
-	ctx := context.Background()
-
-	// Create test item
-	item := &domain.Item{
-		Type:      "expense",
-		Amount:    300.00,
-		Category:  "Transport",
-		Date:      time.Now(),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		t.Fatalf("WithinTx() error = %v", err)
 	}
-	repo.Create(ctx, item)
 
-	// Delete item
-	err := repo.Delete(ctx, item.ID)
+	page, err := repo.GetAll(ctx, domain.ItemFilter{Sort: domain.DefaultItemSort, Limit: 10})
 	if err != nil {
-		t.Fatalf("Delete() error = %v", err)
-	}
-
-	// Verify deletion
-	_, err = repo.GetByID(ctx, item.ID)
-	if err == nil {
-		t.Error("Delete() item still exists")
+		t.Fatalf("GetAll() error = %v", err)
 	}
-}
-
-func TestRepository_GetAnalytics(t *testing.T) {
-	db, cleanup := setupTestDB(t)
-	defer cleanup()
-
-	repo := &repository{db: db}
-	ctx := context.Background()
-
-	// Create test items
-	now := time.Now()
-	amounts := []float64{100, 200, 300, 400, 500, 600, 700, 800, 900, 1000}
-
-	for _, amount := range amounts {
-		item := &domain.Item{
-			Type:      "income",
-			Amount:    amount,
-			Category:  "Test",
-			Date:      now,
-			CreatedAt: now,
-			UpdatedAt: now,
-		}
-		repo.Create(ctx, item)
+	if page.Total != 1 || page.Items[0].ID != created.ID {
+		t.Errorf("GetAll() = %+v, want only item %d", page.Items, created.ID)
 	}
-
-	// Get analytics
-	from := now.AddDate(0, 0, -1)
-	to := now.AddDate(0, 0, 1)
-
-	analytics, err := repo.GetAnalytics(ctx, from, to)
+	history, err := repo.GetHistory(ctx, domain.HistoryFilter{Limit: 10})
 	if err != nil {
-		t.Fatalf("GetAnalytics() error = %v", err)
-	}
-
-	// Verify results
-	expectedSum := 5500.0
-	if analytics.Sum != expectedSum {
-		t.Errorf("GetAnalytics() Sum = %v, want %v", analytics.Sum, expectedSum)
-	}
-
-	expectedAvg := 550.0
-	if analytics.Avg != expectedAvg {
-		t.Errorf("GetAnalytics() Avg = %v, want %v", analytics.Avg, expectedAvg)
+		t.Fatalf("GetHistory() error = %v", err)
 	}
-
-	if analytics.Count != 10 {
-		t.Errorf("GetAnalytics() Count = %v, want %v", analytics.Count, 10)
-	}
-
-	expectedMedian := 550.0
-	if analytics.Median != expectedMedian {
-		t.Errorf("GetAnalytics() Median = %v, want %v", analytics.Median, expectedMedian)
-	}
-
-	// 90th percentile should be around 900-910 (PERCENTILE_CONT interpolates)
-	if analytics.Percentile < 900.0 || analytics.Percentile > 910.0 {
-		t.Errorf("GetAnalytics() Percentile = %v, want between 900 and 910", analytics.Percentile)
+	if len(history.Entries) != 1 {
+		t.Errorf("GetHistory() returned %d entries, want 1", len(history.Entries))
 	}
 }
 
-func TestRepository_GetAnalytics_EmptyData(t *testing.T) {
+func TestRepository_QueryTimeout(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	repo := &repository{db: db}
-	ctx := context.Background()
-
-	// Get analytics with no data
-	from := time.Now().AddDate(0, 0, -1)
-	to := time.Now()
-
-	analytics, err := repo.GetAnalytics(ctx, from, to)
-	if err != nil {
-		t.Fatalf("GetAnalytics() error = %v", err)
-	}
-
-	// All values should be 0
-	if analytics.Sum != 0 || analytics.Avg != 0 || analytics.Count != 0 {
-		t.Errorf("GetAnalytics() with empty data should return zeros, got %+v", analytics)
+	// Запрос не успевает выполниться за отведенное время
+	repo := &repository{db: db, queryTimeout: time.Nanosecond}
+	_, err := repo.GetByID(context.Background(), 1)
+	if !errors.Is(err, domain.ErrUnavailable) {
+		t.Errorf("GetByID() error = %v, want ErrUnavailable", err)
 	}
 }
@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"context"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+)
+
+func (r *repository) CreateRate(ctx context.Context, rate *domain.ExchangeRate) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO exchange_rates (base_currency, quote_currency, rate, valid_from, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	err := r.conn().QueryRowContext(
+		ctx, query,
+		rate.From, rate.To, rate.Rate, rate.ValidFrom, rate.CreatedAt,
+	).Scan(&rate.ID)
+	if isUniqueViolation(err) {
+		return domain.NewConflictError("exchange rate for this pair and valid_from already exists", err)
+	}
+	return wrapError(err)
+}
+
+func (r *repository) GetRates(ctx context.Context, from, to string) ([]*domain.ExchangeRate, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, base_currency, quote_currency, rate, valid_from, created_at
+		FROM exchange_rates
+		WHERE ($1 = '' OR base_currency = $1)
+		  AND ($2 = '' OR quote_currency = $2)
+		ORDER BY base_currency, quote_currency, valid_from DESC
+	`
+	rows, err := r.conn().QueryContext(ctx, query, from, to)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	var rates []*domain.ExchangeRate
+	for rows.Next() {
+		rate := &domain.ExchangeRate{}
+		if err := rows.Scan(
+			&rate.ID, &rate.From, &rate.To, &rate.Rate,
+			&rate.ValidFrom, &rate.CreatedAt,
+		); err != nil {
+			return nil, wrapError(err)
+		}
+		rates = append(rates, rate)
+	}
+	return rates, wrapError(rows.Err())
+}
+
+func (r *repository) DeleteRate(ctx context.Context, id int64) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := `DELETE FROM exchange_rates WHERE id = $1`
+	result, err := r.conn().ExecContext(ctx, query, id)
+	if err != nil {
+		return wrapError(err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return wrapError(err)
+	}
+	if rows == 0 {
+		return domain.NewNotFoundError("exchange rate not found")
+	}
+	return nil
+}
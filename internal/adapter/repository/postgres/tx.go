@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/dontpanicw/SalesTracker/internal/port"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// txAttempts — сколько раз WithinTx выполняет транзакцию при конфликтах сериализации
+const txAttempts = 3
+
+// txRetryDelay — пауза перед повтором, растет с каждой попыткой
+const txRetryDelay = 10 * time.Millisecond
+
+// querier — общий интерфейс *sql.DB и *sql.Tx
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// conn возвращает транзакцию WithinTx или пул соединений
+func (r *repository) conn() querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db
+}
+
+// WithinTx выполняет fn в транзакции с уровнем изоляции SERIALIZABLE и повторяет ее
+// при конфликте сериализации или взаимной блокировке
+func (r *repository) WithinTx(ctx context.Context, fn func(ctx context.Context, repo port.Repository) error) error {
+	if r.tx != nil {
+		return fn(ctx, r)
+	}
+
+	var err error
+	for attempt := 1; attempt <= txAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return wrapError(ctx.Err())
+			case <-time.After(time.Duration(attempt-1) * txRetryDelay):
+			}
+		}
+		if err = r.runTx(ctx, fn); !isSerializationFailure(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// runTx выполняет одну попытку транзакции WithinTx
+func (r *repository) runTx(ctx context.Context, fn func(ctx context.Context, repo port.Repository) error) error {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return wrapError(err)
+	}
+	defer tx.Rollback()
+
+	txRepo := *r
+	txRepo.tx = tx
+	if err := fn(ctx, &txRepo); err != nil {
+		return err
+	}
+	return wrapError(tx.Commit())
+}
+
+// isSerializationFailure проверяет, что транзакцию можно повторить:
+// она прервана из-за конфликта сериализации или взаимной блокировки
+func isSerializationFailure(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && (pqErr.Code == "40001" || pqErr.Code == "40P01")
+}
+
+// opTx — транзакция одной операции репозитория, например CreateMany. Внутри
+// WithinTx операция выполняется в точке сохранения общей транзакции, чтобы ее
+// откат не отменял предыдущие операции.
+type opTx struct {
+	*sql.Tx
+	savepoint bool
+	released  bool
+}
+
+// begin начинает транзакцию операции
+func (r *repository) begin(ctx context.Context) (*opTx, error) {
+	if r.tx == nil {
+		tx, err := r.db.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &opTx{Tx: tx}, nil
+	}
+	if _, err := r.tx.ExecContext(ctx, `SAVEPOINT repository_op`); err != nil {
+		return nil, err
+	}
+	return &opTx{Tx: r.tx, savepoint: true}, nil
+}
+
+func (t *opTx) Commit() error {
+	if !t.savepoint {
+		return t.Tx.Commit()
+	}
+	if _, err := t.Tx.Exec(`RELEASE SAVEPOINT repository_op`); err != nil {
+		return err
+	}
+	t.released = true
+	return nil
+}
+
+// Rollback откатывает транзакцию операции. После Commit точка сохранения
+// уже освобождена и откатывать нечего, поэтому Rollback можно вызывать в defer.
+func (t *opTx) Rollback() error {
+	if !t.savepoint {
+		return t.Tx.Rollback()
+	}
+	if t.released {
+		return nil
+	}
+	t.released = true
+	_, err := t.Tx.Exec(`ROLLBACK TO SAVEPOINT repository_op`)
+	return err
+}
@@ -0,0 +1,353 @@
+package repotest
+
+import (
+	"context"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"github.com/dontpanicw/SalesTracker/internal/port"
+	"testing"
+	"time"
+)
+
+func testGetAnalytics(t *testing.T, repo port.Repository) {
+	ctx := context.Background()
+
+	// Create test items
+	now := time.Now()
+	amounts := []int64{100, 200, 300, 400, 500, 600, 700, 800, 900, 1000}
+
+	for _, amount := range amounts {
+		item := &domain.Item{
+			Type:      "income",
+			Amount:    domain.NewMoney(amount, 0),
+			Currency:  "RUB",
+			Category:  "Test",
+			Date:      now,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		repo.Create(ctx, item)
+	}
+
+	// Get analytics
+	from := now.AddDate(0, 0, -1)
+	to := now.AddDate(0, 0, 1)
+
+	report, err := repo.GetAnalytics(ctx, domain.AnalyticsQuery{From: from, To: to, Currency: "RUB"})
+	if err != nil {
+		t.Fatalf("GetAnalytics() error = %v", err)
+	}
+	analytics := report.Income
+
+	// Verify results
+	expectedSum := domain.NewMoney(5500, 0)
+	if analytics.Sum != expectedSum {
+		t.Errorf("GetAnalytics() Sum = %v, want %v", analytics.Sum, expectedSum)
+	}
+
+	expectedAvg := domain.NewMoney(550, 0)
+	if analytics.Avg != expectedAvg {
+		t.Errorf("GetAnalytics() Avg = %v, want %v", analytics.Avg, expectedAvg)
+	}
+
+	if analytics.Count != 10 {
+		t.Errorf("GetAnalytics() Count = %v, want %v", analytics.Count, 10)
+	}
+
+	expectedMedian := domain.NewMoney(550, 0)
+	if analytics.Median != expectedMedian {
+		t.Errorf("GetAnalytics() Median = %v, want %v", analytics.Median, expectedMedian)
+	}
+
+	// 90th percentile should be around 900-910 (PERCENTILE_CONT interpolates)
+	if analytics.Percentile < domain.NewMoney(900, 0) || analytics.Percentile > domain.NewMoney(910, 0) {
+		t.Errorf("GetAnalytics() Percentile = %v, want between 900 and 910", analytics.Percentile)
+	}
+}
+
+func testGetAnalyticsEmptyData(t *testing.T, repo port.Repository) {
+	ctx := context.Background()
+
+	// Get analytics with no data
+	from := time.Now().AddDate(0, 0, -1)
+	to := time.Now()
+
+	report, err := repo.GetAnalytics(ctx, domain.AnalyticsQuery{From: from, To: to, Currency: "RUB"})
+	if err != nil {
+		t.Fatalf("GetAnalytics() error = %v", err)
+	}
+
+	// All values should be 0
+	if report.Income.Count != 0 || report.Expense.Count != 0 || report.Balance != 0 {
+		t.Errorf("GetAnalytics() with empty data should return zeros, got %+v", report)
+	}
+}
+
+func testGetAnalyticsIncomeAndExpense(t *testing.T, repo port.Repository) {
+	ctx := context.Background()
+
+	now := time.Now()
+	items := []*domain.Item{
+		{Type: "income", Amount: domain.NewMoney(3000, 0), Currency: "RUB", Category: "Salary", Date: now, CreatedAt: now, UpdatedAt: now},
+		{Type: "income", Amount: domain.NewMoney(1000, 0), Currency: "RUB", Category: "Bonus", Date: now, CreatedAt: now, UpdatedAt: now},
+		{Type: "expense", Amount: domain.NewMoney(500, 0), Currency: "RUB", Category: "Food", Date: now, CreatedAt: now, UpdatedAt: now},
+	}
+	for _, item := range items {
+		repo.Create(ctx, item)
+	}
+
+	report, err := repo.GetAnalytics(ctx, domain.AnalyticsQuery{From: now.AddDate(0, 0, -1), To: now.AddDate(0, 0, 1), Currency: "RUB"})
+	if err != nil {
+		t.Fatalf("GetAnalytics() error = %v", err)
+	}
+
+	if report.Income.Sum != domain.NewMoney(4000, 0) || report.Income.Count != 2 {
+		t.Errorf("GetAnalytics() Income = %+v, want sum 4000 and count 2", report.Income)
+	}
+	if report.Expense.Sum != domain.NewMoney(500, 0) || report.Expense.Count != 1 {
+		t.Errorf("GetAnalytics() Expense = %+v, want sum 500 and count 1", report.Expense)
+	}
+	if report.Balance != domain.NewMoney(3500, 0) {
+		t.Errorf("GetAnalytics() Balance = %v, want %v", report.Balance, "3500.00")
+	}
+}
+
+func testGetGroupedAnalytics(t *testing.T, repo port.Repository) {
+	ctx := context.Background()
+
+	jan := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	feb := time.Date(2024, 2, 10, 12, 0, 0, 0, time.UTC)
+	items := []*domain.Item{
+		{Type: "expense", Amount: domain.NewMoney(100, 0), Currency: "RUB", Category: "Food", Date: jan, CreatedAt: jan, UpdatedAt: jan},
+		{Type: "expense", Amount: domain.NewMoney(300, 0), Currency: "RUB", Category: "Food", Date: jan, CreatedAt: jan, UpdatedAt: jan},
+		{Type: "expense", Amount: domain.NewMoney(200, 0), Currency: "RUB", Category: "Food", Date: feb, CreatedAt: feb, UpdatedAt: feb},
+		{Type: "income", Amount: domain.NewMoney(5000, 0), Currency: "RUB", Category: "Salary", Date: feb, CreatedAt: feb, UpdatedAt: feb},
+	}
+	for _, item := range items {
+		repo.Create(ctx, item)
+	}
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	groups, err := repo.GetGroupedAnalytics(ctx, domain.AnalyticsQuery{
+		From:     from,
+		To:       to,
+		GroupBy:  []string{domain.GroupByCategory, domain.GroupByMonth},
+		Currency: "RUB",
+	})
+	if err != nil {
+		t.Fatalf("GetGroupedAnalytics() error = %v", err)
+	}
+
+	if len(groups) != 3 {
+		t.Fatalf("GetGroupedAnalytics() returned %d groups, want 3", len(groups))
+	}
+
+	first := groups[0]
+	if *first.Category != "Food" || first.Period.Month() != time.January {
+		t.Errorf("GetGroupedAnalytics() first group = %v %v, want Food January", *first.Category, first.Period)
+	}
+	if first.Sum != domain.NewMoney(400, 0) || first.Count != 2 || first.Median != domain.NewMoney(200, 0) {
+		t.Errorf("GetGroupedAnalytics() first group stats = %+v", first.Analytics)
+	}
+	if first.Type != nil {
+		t.Errorf("GetGroupedAnalytics() Type should not be set when not grouped by type")
+	}
+	if first.Income != 0 || first.Expense != domain.NewMoney(400, 0) || first.Net != domain.NewMoney(-400, 0) {
+		t.Errorf("GetGroupedAnalytics() first group income = %v, expense = %v, net = %v", first.Income, first.Expense, first.Net)
+	}
+
+	// Без группировки по типу доходы и расходы периода возвращаются раздельно
+	groups, err = repo.GetGroupedAnalytics(ctx, domain.AnalyticsQuery{
+		From:     from,
+		To:       to,
+		GroupBy:  []string{domain.GroupByMonth},
+		Currency: "RUB",
+	})
+	if err != nil {
+		t.Fatalf("GetGroupedAnalytics() error = %v", err)
+	}
+	if len(groups) != 2 {
+		t.Fatalf("GetGroupedAnalytics() returned %d groups, want 2", len(groups))
+	}
+	february := groups[1]
+	if february.Income != domain.NewMoney(5000, 0) || february.Expense != domain.NewMoney(200, 0) || february.Net != domain.NewMoney(4800, 0) {
+		t.Errorf("GetGroupedAnalytics() February income = %v, expense = %v, net = %v, want 5000.00, 200.00, 4800.00",
+			february.Income, february.Expense, february.Net)
+	}
+}
+
+func testGetTimeSeries(t *testing.T, repo port.Repository) {
+	ctx := context.Background()
+
+	day1 := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	day3 := time.Date(2024, 3, 3, 10, 0, 0, 0, time.UTC)
+	items := []*domain.Item{
+		{Type: "income", Amount: domain.NewMoney(1000, 0), Currency: "RUB", Category: "Salary", Date: day1, CreatedAt: day1, UpdatedAt: day1},
+		{Type: "expense", Amount: domain.NewMoney(250, 0), Currency: "RUB", Category: "Food", Date: day1, CreatedAt: day1, UpdatedAt: day1},
+		{Type: "expense", Amount: domain.NewMoney(100, 0), Currency: "RUB", Category: "Food", Date: day3, CreatedAt: day3, UpdatedAt: day3},
+	}
+	for _, item := range items {
+		repo.Create(ctx, item)
+	}
+
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 3, 23, 59, 59, 0, time.UTC)
+	points, err := repo.GetTimeSeries(ctx, domain.TimeSeriesQuery{
+		From:     from,
+		To:       to,
+		Interval: domain.IntervalDay,
+		Location: time.UTC,
+		Currency: "RUB",
+	})
+	if err != nil {
+		t.Fatalf("GetTimeSeries() error = %v", err)
+	}
+
+	if len(points) != 3 {
+		t.Fatalf("GetTimeSeries() returned %d points, want 3", len(points))
+	}
+	if points[0].Income != domain.NewMoney(1000, 0) || points[0].Expense != domain.NewMoney(250, 0) || points[0].Net != domain.NewMoney(750, 0) {
+		t.Errorf("GetTimeSeries() first point = %+v", points[0])
+	}
+	if points[1].Income != 0 || points[1].Expense != 0 {
+		t.Errorf("GetTimeSeries() gap point should be zero, got %+v", points[1])
+	}
+	if !points[1].Start.Equal(time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("GetTimeSeries() gap point start = %v", points[1].Start)
+	}
+	if points[2].Net != domain.NewMoney(-100, 0) {
+		t.Errorf("GetTimeSeries() last point Net = %v, want -100", points[2].Net)
+	}
+}
+
+func testGetAnalyticsPercentiles(t *testing.T, repo port.Repository) {
+	ctx := context.Background()
+
+	now := time.Now()
+	for _, amount := range []int64{100, 200, 300, 400, 500} {
+		item := &domain.Item{Type: "expense", Amount: domain.NewMoney(amount, 0), Currency: "RUB", Category: "Test", Date: now, CreatedAt: now, UpdatedAt: now}
+		repo.Create(ctx, item)
+	}
+
+	report, err := repo.GetAnalytics(ctx, domain.AnalyticsQuery{
+		From:        now.AddDate(0, 0, -1),
+		To:          now.AddDate(0, 0, 1),
+		Percentiles: []float64{0.25, 0.75, 1},
+		Currency:    "RUB",
+	})
+	if err != nil {
+		t.Fatalf("GetAnalytics() error = %v", err)
+	}
+
+	expense := report.Expense
+	want := map[string]domain.Money{"0.25": domain.NewMoney(200, 0), "0.75": domain.NewMoney(400, 0), "1": domain.NewMoney(500, 0)}
+	for key, value := range want {
+		if expense.Percentiles[key] != value {
+			t.Errorf("GetAnalytics() Percentiles[%s] = %v, want %v", key, expense.Percentiles[key], value)
+		}
+	}
+	if expense.Min != domain.NewMoney(100, 0) || expense.Max != domain.NewMoney(500, 0) {
+		t.Errorf("GetAnalytics() Min = %v, Max = %v, want 100 and 500", expense.Min, expense.Max)
+	}
+	if expense.Variance != 20000 {
+		t.Errorf("GetAnalytics() Variance = %v, want 20000", expense.Variance)
+	}
+	if report.Income.Percentiles != nil {
+		t.Errorf("GetAnalytics() Income without rows should have no percentiles, got %v", report.Income.Percentiles)
+	}
+}
+
+func testGetAnalyticsCurrencyConversion(t *testing.T, repo port.Repository) {
+	ctx := context.Background()
+
+	jan := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC)
+	rates := []*domain.ExchangeRate{
+		{From: "USD", To: "RUB", Rate: domain.MustParseRate("90"), ValidFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), CreatedAt: jan},
+		{From: "USD", To: "RUB", Rate: domain.MustParseRate("100"), ValidFrom: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), CreatedAt: feb},
+	}
+	for _, rate := range rates {
+		if err := repo.CreateRate(ctx, rate); err != nil {
+			t.Fatalf("CreateRate() error = %v", err)
+		}
+	}
+
+	items := []*domain.Item{
+		{Type: "income", Amount: domain.NewMoney(10, 0), Currency: "USD", Category: "Sales", Date: jan, CreatedAt: jan, UpdatedAt: jan},
+		{Type: "income", Amount: domain.NewMoney(10, 0), Currency: "USD", Category: "Sales", Date: feb, CreatedAt: feb, UpdatedAt: feb},
+		{Type: "expense", Amount: domain.NewMoney(500, 0), Currency: "RUB", Category: "Food", Date: feb, CreatedAt: feb, UpdatedAt: feb},
+	}
+	for _, item := range items {
+		repo.Create(ctx, item)
+	}
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	report, err := repo.GetAnalytics(ctx, domain.AnalyticsQuery{From: from, To: to, Currency: "RUB"})
+	if err != nil {
+		t.Fatalf("GetAnalytics() error = %v", err)
+	}
+	if report.Income.Sum != domain.NewMoney(1900, 0) {
+		t.Errorf("GetAnalytics() Income.Sum in RUB = %v, want 1900.00", report.Income.Sum)
+	}
+	if report.Balance != domain.NewMoney(1400, 0) {
+		t.Errorf("GetAnalytics() Balance in RUB = %v, want 1400.00", report.Balance)
+	}
+
+	// Обратный курс: RUB -> USD по курсу USD/RUB = 100
+	report, err = repo.GetAnalytics(ctx, domain.AnalyticsQuery{From: feb, To: to, Currency: "USD"})
+	if err != nil {
+		t.Fatalf("GetAnalytics() error = %v", err)
+	}
+	if report.Expense.Sum != domain.NewMoney(5, 0) {
+		t.Errorf("GetAnalytics() Expense.Sum in USD = %v, want 5.00", report.Expense.Sum)
+	}
+
+	if _, err := repo.GetAnalytics(ctx, domain.AnalyticsQuery{From: from, To: to, Currency: "EUR"}); err == nil {
+		t.Error("GetAnalytics() expected error when exchange rate is missing")
+	}
+}
+
+func testGetAnalyticsEmptyRange(t *testing.T, repo port.Repository) {
+	ctx := context.Background()
+
+	date := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	item := &domain.Item{Type: "expense", Amount: domain.NewMoney(100, 0), Currency: "RUB", Category: "Food", Date: date, CreatedAt: date, UpdatedAt: date}
+	if err := repo.Create(ctx, item); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// Период без записей, хотя в хранилище они есть
+	from := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2023, 1, 3, 23, 59, 59, 0, time.UTC)
+
+	report, err := repo.GetAnalytics(ctx, domain.AnalyticsQuery{From: from, To: to, Percentiles: []float64{0.5}, Currency: "RUB"})
+	if err != nil {
+		t.Fatalf("GetAnalytics() error = %v", err)
+	}
+	if report.Expense.Count != 0 || report.Expense.Sum != 0 || report.Expense.Median != 0 || report.Expense.Max != 0 {
+		t.Errorf("GetAnalytics() expense = %+v, want zeros", report.Expense)
+	}
+
+	groups, err := repo.GetGroupedAnalytics(ctx, domain.AnalyticsQuery{From: from, To: to, GroupBy: []string{domain.GroupByCategory}, Currency: "RUB"})
+	if err != nil {
+		t.Fatalf("GetGroupedAnalytics() error = %v", err)
+	}
+	if len(groups) != 0 {
+		t.Errorf("GetGroupedAnalytics() returned %d groups, want 0", len(groups))
+	}
+
+	points, err := repo.GetTimeSeries(ctx, domain.TimeSeriesQuery{From: from, To: to, Interval: domain.IntervalDay, Location: time.UTC, Currency: "RUB"})
+	if err != nil {
+		t.Fatalf("GetTimeSeries() error = %v", err)
+	}
+	if len(points) != 3 {
+		t.Fatalf("GetTimeSeries() returned %d points, want 3", len(points))
+	}
+	for _, point := range points {
+		if point.Income != 0 || point.Expense != 0 || point.Net != 0 {
+			t.Errorf("GetTimeSeries() point = %+v, want zeros", point)
+		}
+	}
+}
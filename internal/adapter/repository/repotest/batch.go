@@ -0,0 +1,76 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"github.com/dontpanicw/SalesTracker/internal/port"
+	"testing"
+	"time"
+)
+
+func testApplyItemBatch(t *testing.T, repo port.Repository) {
+	ctx := context.Background()
+	now := time.Now()
+	newItem := func(category string) *domain.Item {
+		return &domain.Item{
+			Type: "expense", Amount: domain.NewMoney(100, 0), Currency: "RUB", Category: category,
+			Date: now, CreatedAt: now, UpdatedAt: now,
+		}
+	}
+
+	existing := []*domain.Item{newItem("Food"), newItem("Cafe"), newItem("Taxi")}
+	if err := repo.CreateMany(ctx, existing); err != nil {
+		t.Fatalf("CreateMany() error = %v", err)
+	}
+	if existing[0].ID == 0 || existing[0].Version != 1 || existing[1].ID <= existing[0].ID {
+		t.Fatalf("CreateMany() ids = %d, %d, version %d", existing[0].ID, existing[1].ID, existing[0].Version)
+	}
+
+	update := newItem("Groceries")
+	update.ID = existing[0].ID
+	update.Version = 1
+	stale := newItem("Coffee")
+	stale.ID = existing[1].ID
+	stale.Version = 5
+	created := newItem("Books")
+	batch := &domain.ItemBatch{
+		Create: []*domain.Item{created},
+		Update: []*domain.Item{update, stale},
+		Delete: []domain.ItemRef{{ID: existing[2].ID}, {ID: existing[2].ID + 1000}},
+	}
+
+	// Все или ничего: ошибки отменяют весь пакет
+	failed, err := repo.ApplyItemBatch(ctx, batch, false)
+	if err != nil {
+		t.Fatalf("ApplyItemBatch() error = %v", err)
+	}
+	if !errors.Is(failed[stale.ID], domain.ErrVersionMismatch) || !errors.Is(failed[existing[2].ID+1000], domain.ErrNotFound) || len(failed) != 2 {
+		t.Errorf("ApplyItemBatch() failed = %v", failed)
+	}
+	page, _ := repo.GetAll(ctx, domain.ItemFilter{Sort: domain.DefaultItemSort, Limit: 10})
+	if page.Total != 3 {
+		t.Errorf("atomic batch with errors changed data: total = %d, want 3", page.Total)
+	}
+
+	// Частичное выполнение сохраняет успешные операции
+	update.Version = 1
+	failed, err = repo.ApplyItemBatch(ctx, batch, true)
+	if err != nil || len(failed) != 2 {
+		t.Fatalf("ApplyItemBatch() partial = %v, %v", failed, err)
+	}
+	if created.ID == 0 || update.Version != 2 || update.CreatedAt.IsZero() {
+		t.Errorf("ApplyItemBatch() did not fill created id or new version: %+v, %+v", created, update)
+	}
+	got, _ := repo.GetByID(ctx, update.ID)
+	if got.Category != "Groceries" || got.Version != 2 {
+		t.Errorf("GetByID() after batch = %+v", got)
+	}
+	if _, err := repo.GetByID(ctx, existing[2].ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("GetByID() deleted item error = %v, want ErrNotFound", err)
+	}
+	trash, _ := repo.GetAll(ctx, domain.ItemFilter{Sort: domain.DefaultItemSort, Limit: 10, Deleted: true})
+	if trash.Total != 1 || trash.Items[0].ID != existing[2].ID {
+		t.Errorf("batch delete did not move item to trash: %+v", trash)
+	}
+}
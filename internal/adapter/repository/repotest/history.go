@@ -0,0 +1,60 @@
+package repotest
+
+import (
+	"context"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"github.com/dontpanicw/SalesTracker/internal/port"
+	"testing"
+	"time"
+)
+
+func testHistory(t *testing.T, repo port.Repository) {
+	ctx := context.Background()
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	before := &domain.Item{ID: 1, Type: "expense", Amount: domain.NewMoney(100, 50), Currency: "RUB", Category: "Food", Date: date, Version: 1}
+	after := *before
+	after.Amount, after.Version = domain.NewMoney(120, 0), 2
+	aliceCtx := domain.WithActor(ctx, "alice")
+	entries := []*domain.HistoryEntry{
+		domain.NewHistoryEntry(aliceCtx, domain.HistoryCreate, nil, before),
+		domain.NewHistoryEntry(domain.WithActor(ctx, "bob"), domain.HistoryUpdate, before, &after),
+		domain.NewHistoryEntry(aliceCtx, domain.HistoryDelete, &after, nil),
+		domain.NewHistoryEntry(aliceCtx, domain.HistoryCreate, nil, &domain.Item{ID: 2, Category: "Taxi"}),
+	}
+	if err := repo.AddHistory(ctx, entries); err != nil {
+		t.Fatalf("AddHistory() error = %v", err)
+	}
+
+	// История записи от новых изменений к старым, по две на страницу
+	page, err := repo.GetHistory(ctx, domain.HistoryFilter{ItemID: 1, Limit: 2})
+	if err != nil {
+		t.Fatalf("GetHistory() error = %v", err)
+	}
+	if len(page.Entries) != 2 || page.NextCursor == "" {
+		t.Fatalf("GetHistory() = %d entries, cursor %q, want 2 and next page", len(page.Entries), page.NextCursor)
+	}
+	if page.Entries[0].Action != domain.HistoryDelete || page.Entries[0].After != nil || page.Entries[0].Before.Version != 2 {
+		t.Errorf("GetHistory() newest entry = %+v", page.Entries[0])
+	}
+	update := page.Entries[1]
+	if update.Actor != "bob" || update.Before.Amount != domain.NewMoney(100, 50) || update.After.Amount != domain.NewMoney(120, 0) {
+		t.Errorf("GetHistory() update entry = %+v, before %+v, after %+v", update, update.Before, update.After)
+	}
+
+	next, err := repo.GetHistory(ctx, domain.HistoryFilter{ItemID: 1, Limit: 2, Cursor: page.NextCursor})
+	if err != nil || len(next.Entries) != 1 || next.Entries[0].Action != domain.HistoryCreate || next.NextCursor != "" {
+		t.Errorf("GetHistory() second page = %+v, %v, want create entry only", next, err)
+	}
+
+	// Лента аудита по автору
+	audit, err := repo.GetHistory(ctx, domain.HistoryFilter{Actor: "alice", Limit: 10})
+	if err != nil || len(audit.Entries) != 3 {
+		t.Errorf("GetHistory() by actor = %+v, %v, want 3 entries", audit, err)
+	}
+	future := time.Now().Add(time.Hour)
+	empty, err := repo.GetHistory(ctx, domain.HistoryFilter{From: &future, Limit: 10})
+	if err != nil || len(empty.Entries) != 0 {
+		t.Errorf("GetHistory() from future = %+v, %v, want no entries", empty, err)
+	}
+}
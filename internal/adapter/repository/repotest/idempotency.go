@@ -0,0 +1,91 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"github.com/dontpanicw/SalesTracker/internal/port"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testIdempotencyKeys(t *testing.T, repo port.Repository) {
+	ctx := context.Background()
+	now := time.Now()
+	hash := strings.Repeat("a", 64)
+
+	record := &domain.IdempotencyRecord{Key: "key-1", RequestHash: hash, CreatedAt: now, LockedUntil: now.Add(time.Minute), ExpiresAt: now.Add(time.Hour)}
+	existing, err := repo.ReserveIdempotencyKey(ctx, record)
+	if err != nil || existing != nil {
+		t.Fatalf("ReserveIdempotencyKey() = %v, %v, want nil, nil", existing, err)
+	}
+
+	// Ответа еще нет: повтор видит ключ в процессе выполнения
+	existing, err = repo.ReserveIdempotencyKey(ctx, record)
+	if err != nil || existing == nil || existing.Response != nil {
+		t.Fatalf("ReserveIdempotencyKey() repeat = %+v, %v, want record without response", existing, err)
+	}
+
+	if err := repo.CompleteIdempotencyKey(ctx, record, []byte(`{"id":1}`)); err != nil {
+		t.Fatalf("CompleteIdempotencyKey() error = %v", err)
+	}
+	existing, _ = repo.ReserveIdempotencyKey(ctx, record)
+	if existing == nil || string(existing.Response) != `{"id":1}` || existing.RequestHash != hash {
+		t.Errorf("ReserveIdempotencyKey() after complete = %+v", existing)
+	}
+
+	// Завершенный ключ не освобождается и не перехватывается после аренды
+	repo.ReleaseIdempotencyKey(ctx, record)
+	if existing, _ = repo.ReserveIdempotencyKey(ctx, record); existing == nil {
+		t.Error("ReleaseIdempotencyKey() removed completed key")
+	}
+	afterLease := &domain.IdempotencyRecord{Key: "key-1", RequestHash: hash, CreatedAt: now.Add(2 * time.Minute), LockedUntil: now.Add(3 * time.Minute), ExpiresAt: now.Add(time.Hour)}
+	if existing, _ = repo.ReserveIdempotencyKey(ctx, afterLease); existing == nil || existing.Response == nil {
+		t.Errorf("ReserveIdempotencyKey() took over completed key: %+v", existing)
+	}
+
+	// Истекший ключ заменяется новым
+	later := &domain.IdempotencyRecord{Key: "key-1", RequestHash: hash, CreatedAt: now.Add(2 * time.Hour), LockedUntil: now.Add(2*time.Hour + time.Minute), ExpiresAt: now.Add(3 * time.Hour)}
+	if existing, err = repo.ReserveIdempotencyKey(ctx, later); err != nil || existing != nil {
+		t.Errorf("ReserveIdempotencyKey() after expiry = %v, %v, want nil, nil", existing, err)
+	}
+}
+
+func testIdempotencyLease(t *testing.T, repo port.Repository) {
+	ctx := context.Background()
+	now := time.Now()
+	hash := strings.Repeat("a", 64)
+
+	first := &domain.IdempotencyRecord{Key: "key-1", RequestHash: hash, CreatedAt: now, LockedUntil: now.Add(time.Minute), ExpiresAt: now.Add(time.Hour)}
+	if existing, err := repo.ReserveIdempotencyKey(ctx, first); err != nil || existing != nil {
+		t.Fatalf("ReserveIdempotencyKey() = %v, %v, want nil, nil", existing, err)
+	}
+
+	// Пока аренда действует, повтор видит ключ в процессе выполнения
+	during := &domain.IdempotencyRecord{Key: "key-1", RequestHash: hash, CreatedAt: now.Add(30 * time.Second), LockedUntil: now.Add(90 * time.Second), ExpiresAt: now.Add(time.Hour)}
+	if existing, err := repo.ReserveIdempotencyKey(ctx, during); err != nil || existing == nil || existing.Response != nil {
+		t.Fatalf("ReserveIdempotencyKey() during lease = %+v, %v, want record without response", existing, err)
+	}
+
+	// Первый запрос не сохранил ответ за время аренды: повтор перехватывает ключ
+	retry := &domain.IdempotencyRecord{Key: "key-1", RequestHash: hash, CreatedAt: now.Add(2 * time.Minute), LockedUntil: now.Add(3 * time.Minute), ExpiresAt: now.Add(time.Hour)}
+	if existing, err := repo.ReserveIdempotencyKey(ctx, retry); err != nil || existing != nil {
+		t.Fatalf("ReserveIdempotencyKey() after lease = %+v, %v, want nil, nil", existing, err)
+	}
+
+	// Первый запрос больше не может ни завершить, ни освободить перехваченный ключ
+	if err := repo.CompleteIdempotencyKey(ctx, first, []byte(`{"id":1}`)); !errors.Is(err, domain.ErrIdempotencyKeyTakenOver) {
+		t.Errorf("CompleteIdempotencyKey() stale reservation error = %v, want ErrIdempotencyKeyTakenOver", err)
+	}
+	if err := repo.ReleaseIdempotencyKey(ctx, first); err != nil {
+		t.Fatalf("ReleaseIdempotencyKey() error = %v", err)
+	}
+	if err := repo.CompleteIdempotencyKey(ctx, retry, []byte(`{"id":2}`)); err != nil {
+		t.Fatalf("CompleteIdempotencyKey() error = %v", err)
+	}
+	existing, err := repo.ReserveIdempotencyKey(ctx, retry)
+	if err != nil || existing == nil || string(existing.Response) != `{"id":2}` {
+		t.Errorf("ReserveIdempotencyKey() after takeover = %+v, %v, want response of the retry", existing, err)
+	}
+}
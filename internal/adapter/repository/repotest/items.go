@@ -0,0 +1,387 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"github.com/dontpanicw/SalesTracker/internal/port"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testCreate(t *testing.T, repo port.Repository) {
+	ctx := context.Background()
+
+	item := &domain.Item{
+		Type:      "income",
+		Amount:    domain.NewMoney(1000, 50),
+		Currency:  "RUB",
+		Category:  "Salary",
+		Date:      time.Now(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	err := repo.Create(ctx, item)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if item.ID == 0 {
+		t.Error("Create() did not set ID")
+	}
+}
+
+func testCreateMany(t *testing.T, repo port.Repository) {
+	ctx := context.Background()
+
+	now := time.Now()
+	items := []*domain.Item{
+		{Type: "income", Amount: domain.NewMoney(1000, 0), Currency: "RUB", Category: "Salary", Date: now, CreatedAt: now, UpdatedAt: now},
+		{Type: "expense", Amount: domain.NewMoney(500, 0), Currency: "RUB", Category: "Food", Date: now, CreatedAt: now, UpdatedAt: now},
+	}
+	if err := repo.CreateMany(ctx, items); err != nil {
+		t.Fatalf("CreateMany() error = %v", err)
+	}
+	for _, item := range items {
+		if item.ID == 0 {
+			t.Error("CreateMany() did not set ID")
+		}
+	}
+
+	// Ошибка в одной записи откатывает всю пачку
+	broken := []*domain.Item{
+		{Type: "income", Amount: domain.NewMoney(1, 0), Currency: "RUB", Category: "Salary", Date: now, CreatedAt: now, UpdatedAt: now},
+		{Type: "unknown", Amount: domain.NewMoney(1, 0), Currency: "RUB", Category: "Salary", Date: now, CreatedAt: now, UpdatedAt: now},
+	}
+	if err := repo.CreateMany(ctx, broken); err == nil {
+		t.Fatal("CreateMany() expected error for invalid item")
+	}
+	page, err := repo.GetAll(ctx, domain.ItemFilter{Sort: domain.DefaultItemSort, Limit: 10})
+	if err != nil {
+		t.Fatalf("GetAll() error = %v", err)
+	}
+	if page.Total != int64(len(items)) {
+		t.Errorf("GetAll() total = %d after failed batch, want %d", page.Total, len(items))
+	}
+}
+
+func testGetByID(t *testing.T, repo port.Repository) {
+	ctx := context.Background()
+
+	// Create test item
+	item := &domain.Item{
+		Type:      "expense",
+		Amount:    domain.NewMoney(500, 0),
+		Currency:  "RUB",
+		Category:  "Food",
+		Date:      time.Now(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	repo.Create(ctx, item)
+
+	// Get item
+	got, err := repo.GetByID(ctx, item.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+
+	if got.ID != item.ID {
+		t.Errorf("GetByID() ID = %v, want %v", got.ID, item.ID)
+	}
+	if got.Type != item.Type {
+		t.Errorf("GetByID() Type = %v, want %v", got.Type, item.Type)
+	}
+	if got.Amount != item.Amount {
+		t.Errorf("GetByID() Amount = %v, want %v", got.Amount, item.Amount)
+	}
+}
+
+func testUpdate(t *testing.T, repo port.Repository) {
+	ctx := context.Background()
+
+	// Create test item
+	item := &domain.Item{
+		Type:      "income",
+		Amount:    domain.NewMoney(1000, 0),
+		Currency:  "RUB",
+		Category:  "Salary",
+		Date:      time.Now(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	repo.Create(ctx, item)
+
+	stored, _ := repo.GetByID(ctx, item.ID)
+
+	// Update item, as PUT does: CreatedAt is not sent by the client
+	item.Amount = domain.NewMoney(1500, 0)
+	item.Category = "Salary + Bonus"
+	item.CreatedAt = time.Time{}
+	item.UpdatedAt = time.Now()
+
+	err := repo.Update(ctx, item)
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if !item.CreatedAt.Equal(stored.CreatedAt) {
+		t.Errorf("Update() CreatedAt = %v, want %v", item.CreatedAt, stored.CreatedAt)
+	}
+
+	missing := &domain.Item{ID: item.ID + 1000, Type: "income", Amount: domain.NewMoney(1, 0), Currency: "RUB", Category: "X", Date: time.Now()}
+	if err := repo.Update(ctx, missing); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Update() missing item error = %v, want ErrNotFound", err)
+	}
+
+	// Verify update
+	got, _ := repo.GetByID(ctx, item.ID)
+	if got.Amount != domain.NewMoney(1500, 0) {
+		t.Errorf("Update() Amount = %v, want %v", got.Amount, "1500.00")
+	}
+	if got.Category != "Salary + Bonus" {
+		t.Errorf("Update() Category = %v, want %v", got.Category, "Salary + Bonus")
+	}
+}
+
+func testUpdateVersion(t *testing.T, repo port.Repository) {
+	ctx := context.Background()
+
+	item := &domain.Item{
+		Type:      "expense",
+		Amount:    domain.NewMoney(100, 0),
+		Currency:  "RUB",
+		Category:  "Food",
+		Date:      time.Now(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := repo.Create(ctx, item); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if item.Version != 1 {
+		t.Fatalf("Create() Version = %d, want 1", item.Version)
+	}
+
+	// Two clients read version 1, the first one saves
+	first, second := *item, *item
+	first.Amount = domain.NewMoney(150, 0)
+	if err := repo.Update(ctx, &first); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if first.Version != 2 {
+		t.Errorf("Update() Version = %d, want 2", first.Version)
+	}
+
+	// The second one still has version 1 and must not overwrite the change
+	second.Amount = domain.NewMoney(200, 0)
+	if err := repo.Update(ctx, &second); !errors.Is(err, domain.ErrVersionMismatch) {
+		t.Errorf("Update() stale version error = %v, want ErrVersionMismatch", err)
+	}
+	if err := repo.Delete(ctx, item.ID, 1); !errors.Is(err, domain.ErrVersionMismatch) {
+		t.Errorf("Delete() stale version error = %v, want ErrVersionMismatch", err)
+	}
+
+	got, _ := repo.GetByID(ctx, item.ID)
+	if got.Amount != domain.NewMoney(150, 0) || got.Version != 2 {
+		t.Errorf("GetByID() = amount %v, version %d, want 150.00, 2", got.Amount, got.Version)
+	}
+}
+
+func testDelete(t *testing.T, repo port.Repository) {
+	ctx := context.Background()
+
+	// Create test item
+	item := &domain.Item{
+		Type:      "expense",
+		Amount:    domain.NewMoney(300, 0),
+		Currency:  "RUB",
+		Category:  "Transport",
+		Date:      time.Now(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	repo.Create(ctx, item)
+
+	// Delete item
+	err := repo.Delete(ctx, item.ID, item.Version)
+	if err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	// Verify deletion
+	_, err = repo.GetByID(ctx, item.ID)
+	if err == nil {
+		t.Error("Delete() item still exists")
+	}
+}
+
+func testTrash(t *testing.T, repo port.Repository) {
+	ctx := context.Background()
+	now := time.Now()
+
+	kept := &domain.Item{Type: "expense", Amount: domain.NewMoney(100, 0), Currency: "RUB", Category: "Food", Date: now, CreatedAt: now, UpdatedAt: now}
+	deleted := &domain.Item{Type: "expense", Amount: domain.NewMoney(300, 0), Currency: "RUB", Category: "Transport", Date: now, CreatedAt: now, UpdatedAt: now}
+	for _, item := range []*domain.Item{kept, deleted} {
+		if err := repo.Create(ctx, item); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	if err := repo.Delete(ctx, deleted.ID, 1); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	// Удаленная запись не видна в списке, аналитике и недоступна для изменения
+	page, err := repo.GetAll(ctx, domain.ItemFilter{Sort: domain.DefaultItemSort, Limit: 10})
+	if err != nil || page.Total != 1 || page.Items[0].ID != kept.ID {
+		t.Errorf("GetAll() = %+v, %v, want only kept item", page, err)
+	}
+	report, err := repo.GetAnalytics(ctx, domain.AnalyticsQuery{From: now.AddDate(0, 0, -1), To: now.AddDate(0, 0, 1), Currency: "RUB"})
+	if err != nil || report.Expense.Sum != domain.NewMoney(100, 0) {
+		t.Errorf("GetAnalytics() = %+v, %v, want expense sum 100", report, err)
+	}
+	deleted.Version = 0
+	if err := repo.Update(ctx, deleted); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Update() deleted item error = %v, want ErrNotFound", err)
+	}
+	if err := repo.Delete(ctx, deleted.ID, 0); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Delete() deleted item error = %v, want ErrNotFound", err)
+	}
+
+	// Корзина содержит только удаленную запись
+	trash, err := repo.GetAll(ctx, domain.ItemFilter{Sort: domain.DefaultItemSort, Limit: 10, Deleted: true})
+	if err != nil || trash.Total != 1 || trash.Items[0].ID != deleted.ID || trash.Items[0].DeletedAt == nil {
+		t.Fatalf("GetAll() trash = %+v, %v, want deleted item", trash, err)
+	}
+	if trash.Items[0].Version != 2 {
+		t.Errorf("GetAll() trash version = %d, want 2", trash.Items[0].Version)
+	}
+
+	if _, err := repo.Restore(ctx, deleted.ID, 1); !errors.Is(err, domain.ErrVersionMismatch) {
+		t.Errorf("Restore() stale version error = %v, want ErrVersionMismatch", err)
+	}
+	restored, err := repo.Restore(ctx, deleted.ID, 2)
+	if err != nil || restored.DeletedAt != nil || restored.Version != 3 {
+		t.Fatalf("Restore() = %+v, %v, want active item with version 3", restored, err)
+	}
+	if _, err := repo.Restore(ctx, deleted.ID, 0); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Restore() active item error = %v, want ErrNotFound", err)
+	}
+
+	// Очистка удаляет только записи, перенесенные в корзину раньше границы
+	if err := repo.Delete(ctx, deleted.ID, 0); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if purged, err := repo.PurgeDeleted(ctx, now.Add(-time.Hour)); err != nil || purged != 0 {
+		t.Errorf("PurgeDeleted() before deletion = %d, %v, want 0", purged, err)
+	}
+	if purged, err := repo.PurgeDeleted(ctx, time.Now().Add(time.Second)); err != nil || purged != 1 {
+		t.Errorf("PurgeDeleted() = %d, %v, want 1", purged, err)
+	}
+	if _, err := repo.Restore(ctx, deleted.ID, 0); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Restore() purged item error = %v, want ErrNotFound", err)
+	}
+	if _, err := repo.GetByID(ctx, kept.ID); err != nil {
+		t.Errorf("GetByID() kept item error = %v", err)
+	}
+}
+
+func testConcurrent(t *testing.T, repo port.Repository) {
+	ctx := context.Background()
+
+	const workers, perWorker = 8, 50
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				item := &domain.Item{Type: "expense", Amount: domain.NewMoney(10, 0), Currency: "RUB", Category: "Food", Date: time.Now()}
+				if err := repo.Create(ctx, item); err != nil {
+					t.Errorf("Create() error = %v", err)
+					return
+				}
+				item.Amount = domain.NewMoney(20, 0)
+				if err := repo.Update(ctx, item); err != nil {
+					t.Errorf("Update() error = %v", err)
+					return
+				}
+				if _, err := repo.GetAll(ctx, domain.ItemFilter{Sort: domain.DefaultItemSort, Limit: 10}); err != nil {
+					t.Errorf("GetAll() error = %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	page, err := repo.GetAll(ctx, domain.ItemFilter{Sort: domain.DefaultItemSort, Limit: 1})
+	if err != nil {
+		t.Fatalf("GetAll() error = %v", err)
+	}
+	if page.Total != workers*perWorker {
+		t.Errorf("GetAll() total = %d, want %d", page.Total, workers*perWorker)
+	}
+	report, err := repo.GetAnalytics(ctx, domain.AnalyticsQuery{From: time.Now().Add(-time.Hour), To: time.Now().Add(time.Hour), Currency: "RUB"})
+	if err != nil {
+		t.Fatalf("GetAnalytics() error = %v", err)
+	}
+	if want := domain.NewMoney(20*workers*perWorker, 0); report.Expense.Sum != want {
+		t.Errorf("GetAnalytics() expense sum = %s, want %s", report.Expense.Sum, want)
+	}
+}
+
+func testNotFound(t *testing.T, repo port.Repository) {
+	ctx := context.Background()
+
+	now := time.Now()
+	active := &domain.Item{Type: "income", Amount: domain.NewMoney(100, 0), Currency: "RUB", Category: "Salary", Date: now, CreatedAt: now, UpdatedAt: now}
+	if err := repo.Create(ctx, active); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	const missingID = 999999
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"GetByID", func() error {
+			_, err := repo.GetByID(ctx, missingID)
+			return err
+		}},
+		{"Update", func() error {
+			missing := *active
+			missing.ID = missingID
+			return repo.Update(ctx, &missing)
+		}},
+		{"Delete", func() error { return repo.Delete(ctx, missingID, 0) }},
+		{"Restore", func() error {
+			_, err := repo.Restore(ctx, missingID, 0)
+			return err
+		}},
+		// Восстановить можно только запись из корзины
+		{"Restore active item", func() error {
+			_, err := repo.Restore(ctx, active.ID, 0)
+			return err
+		}},
+		{"DeleteRate", func() error { return repo.DeleteRate(ctx, missingID) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, domain.ErrNotFound) {
+				t.Errorf("%s() error = %v, want ErrNotFound", tt.name, err)
+			}
+		})
+	}
+
+	// Отсутствующие id в GetByIDs пропускаются без ошибки
+	items, err := repo.GetByIDs(ctx, []int64{active.ID, missingID})
+	if err != nil {
+		t.Fatalf("GetByIDs() error = %v", err)
+	}
+	if len(items) != 1 || items[0].ID != active.ID {
+		t.Errorf("GetByIDs() = %+v, want only item %d", items, active.ID)
+	}
+}
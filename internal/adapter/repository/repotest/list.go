@@ -0,0 +1,301 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"github.com/dontpanicw/SalesTracker/internal/port"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testGetAll(t *testing.T, repo port.Repository) {
+	ctx := context.Background()
+
+	// Create test items
+	now := time.Now()
+	items := []*domain.Item{
+		{Type: "income", Amount: domain.NewMoney(1000, 0), Currency: "RUB", Category: "Salary", Date: now, CreatedAt: now, UpdatedAt: now},
+		{Type: "expense", Amount: domain.NewMoney(500, 0), Currency: "RUB", Category: "Food", Date: now.AddDate(0, 0, -1), CreatedAt: now, UpdatedAt: now},
+		{Type: "income", Amount: domain.NewMoney(2000, 0), Currency: "RUB", Category: "Bonus", Date: now.AddDate(0, 0, -2), CreatedAt: now, UpdatedAt: now},
+	}
+
+	for _, item := range items {
+		repo.Create(ctx, item)
+	}
+
+	// Get all items
+	got, err := repo.GetAll(ctx, domain.ItemFilter{Sort: domain.DefaultItemSort, Limit: 10})
+	if err != nil {
+		t.Fatalf("GetAll() error = %v", err)
+	}
+
+	if len(got.Items) != len(items) || got.Total != int64(len(items)) {
+		t.Errorf("GetAll() returned %d items (total %d), want %d", len(got.Items), got.Total, len(items))
+	}
+	if got.NextCursor != "" {
+		t.Errorf("GetAll() NextCursor = %q, want empty on the last page", got.NextCursor)
+	}
+
+	// Filters
+	minAmount := domain.NewMoney(600, 0)
+	got, err = repo.GetAll(ctx, domain.ItemFilter{
+		Types:     []string{"income"},
+		MinAmount: &minAmount,
+		Sort:      domain.DefaultItemSort,
+		Limit:     10,
+	})
+	if err != nil {
+		t.Fatalf("GetAll() error = %v", err)
+	}
+	if got.Total != 2 {
+		t.Errorf("GetAll() with filters total = %d, want 2", got.Total)
+	}
+}
+
+func testGetAllPagination(t *testing.T, repo port.Repository) {
+	ctx := context.Background()
+
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		// Одинаковые суммы у пар записей проверяют дозапрос по id
+		item := &domain.Item{Type: "expense", Amount: domain.NewMoney(int64(100*(i/2)), 0), Currency: "RUB", Category: "Food", Date: now, CreatedAt: now, UpdatedAt: now}
+		if err := repo.Create(ctx, item); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	filter := domain.ItemFilter{Sort: domain.ItemSort{Field: domain.SortByAmount}, Limit: 2}
+	var ids []int64
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatal("GetAll() pagination did not terminate")
+		}
+		page, err := repo.GetAll(ctx, filter)
+		if err != nil {
+			t.Fatalf("GetAll() error = %v", err)
+		}
+		if page.Total != 5 {
+			t.Errorf("GetAll() total = %d, want 5", page.Total)
+		}
+		for _, item := range page.Items {
+			ids = append(ids, item.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		filter.Cursor = page.NextCursor
+	}
+
+	if len(ids) != 5 {
+		t.Fatalf("GetAll() returned %d items over all pages, want 5", len(ids))
+	}
+	for i := 1; i < len(ids); i++ {
+		if ids[i] <= ids[i-1] {
+			t.Errorf("GetAll() pages out of order: %v", ids)
+			break
+		}
+	}
+}
+
+func testStreamAll(t *testing.T, repo port.Repository) {
+	ctx := context.Background()
+
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		item := &domain.Item{Type: "income", Amount: domain.NewMoney(int64(100*(i+1)), 0), Currency: "RUB", Category: "Salary", Date: now, CreatedAt: now, UpdatedAt: now}
+		if err := repo.Create(ctx, item); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	// Размер страницы не ограничивает выгрузку
+	var amounts []domain.Money
+	err := repo.StreamAll(ctx, domain.ItemFilter{Sort: domain.ItemSort{Field: domain.SortByAmount, Desc: true}, Limit: 1}, func(item *domain.Item) error {
+		amounts = append(amounts, item.Amount)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamAll() error = %v", err)
+	}
+	if len(amounts) != 3 || amounts[0] != domain.NewMoney(300, 0) {
+		t.Errorf("StreamAll() amounts = %v, want 3 items starting with 300.00", amounts)
+	}
+
+	stop := errors.New("stop")
+	err = repo.StreamAll(ctx, domain.ItemFilter{Sort: domain.DefaultItemSort}, func(item *domain.Item) error {
+		return stop
+	})
+	if err != stop {
+		t.Errorf("StreamAll() error = %v, want callback error", err)
+	}
+}
+
+func testGetAllSearch(t *testing.T, repo port.Repository) {
+	ctx := context.Background()
+
+	now := time.Now()
+	items := []*domain.Item{
+		{Type: "expense", Amount: domain.NewMoney(300, 0), Currency: "RUB", Category: "Кафе", Description: "кофе и круассан", Date: now, CreatedAt: now, UpdatedAt: now},
+		{Type: "expense", Amount: domain.NewMoney(900, 0), Currency: "RUB", Category: "Продукты", Description: "молоко, хлеб", Date: now, CreatedAt: now, UpdatedAt: now},
+		{Type: "expense", Amount: domain.NewMoney(150, 0), Currency: "RUB", Category: "Кофе", Date: now, CreatedAt: now, UpdatedAt: now},
+	}
+	for _, item := range items {
+		if err := repo.Create(ctx, item); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	got, err := repo.GetAll(ctx, domain.ItemFilter{Query: "кофе", Sort: domain.DefaultSearchSort, Limit: 10})
+	if err != nil {
+		t.Fatalf("GetAll() error = %v", err)
+	}
+	if got.Total != 2 || len(got.Items) != 2 {
+		t.Fatalf("GetAll() search found %d items (total %d), want 2", len(got.Items), got.Total)
+	}
+	// Совпадение в категории весит больше, чем в описании
+	if got.Items[0].ID != items[2].ID {
+		t.Errorf("GetAll() first result = %d, want category match %d", got.Items[0].ID, items[2].ID)
+	}
+	for _, item := range got.Items {
+		if item.Match == nil || item.Match.Rank <= 0 || !strings.Contains(item.Match.Snippet, "<mark>") {
+			t.Errorf("GetAll() item %d has no highlighted match: %+v", item.ID, item.Match)
+		}
+	}
+}
+
+func testGetAllDateRange(t *testing.T, repo port.Repository) {
+	ctx := context.Background()
+
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 31, 23, 59, 59, 0, time.UTC)
+	dates := []time.Time{
+		from.Add(-time.Second), // до начала периода
+		from,
+		to,
+		to.Add(time.Second), // после конца периода
+	}
+	for _, date := range dates {
+		item := &domain.Item{Type: "expense", Amount: domain.NewMoney(100, 0), Currency: "RUB", Category: "Food", Date: date, CreatedAt: date, UpdatedAt: date}
+		if err := repo.Create(ctx, item); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	// Границы периода входят в выборку
+	page, err := repo.GetAll(ctx, domain.ItemFilter{From: &from, To: &to, Sort: domain.ItemSort{Field: domain.SortByDate}, Limit: 10})
+	if err != nil {
+		t.Fatalf("GetAll() error = %v", err)
+	}
+	if page.Total != 2 || len(page.Items) != 2 {
+		t.Fatalf("GetAll() total = %d, items = %d, want 2", page.Total, len(page.Items))
+	}
+	if !page.Items[0].Date.Equal(from) || !page.Items[1].Date.Equal(to) {
+		t.Errorf("GetAll() dates = %v, %v, want %v, %v", page.Items[0].Date, page.Items[1].Date, from, to)
+	}
+
+	report, err := repo.GetAnalytics(ctx, domain.AnalyticsQuery{From: from, To: to, Currency: "RUB"})
+	if err != nil {
+		t.Fatalf("GetAnalytics() error = %v", err)
+	}
+	if report.Expense.Count != 2 {
+		t.Errorf("GetAnalytics() expense count = %d, want 2", report.Expense.Count)
+	}
+}
+
+func testGetAllDateRangeOffset(t *testing.T, repo port.Repository) {
+	ctx := context.Background()
+
+	// Границы с ненулевым смещением сравниваются как моменты времени:
+	// 2024-03-01T03:00:00+03:00 — это 2024-03-01T00:00:00Z
+	msk := time.FixedZone("MSK", 3*60*60)
+	from := time.Date(2024, 3, 1, 3, 0, 0, 0, msk)
+	to := time.Date(2024, 3, 2, 2, 59, 59, 0, msk)
+	dates := []time.Time{
+		time.Date(2024, 2, 29, 23, 59, 59, 0, time.UTC), // до начала периода
+		time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 1, 23, 59, 59, 0, time.UTC),
+		time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), // после конца периода
+	}
+	for _, date := range dates {
+		item := &domain.Item{Type: "expense", Amount: domain.NewMoney(100, 0), Currency: "RUB", Category: "Food", Date: date, CreatedAt: date, UpdatedAt: date}
+		if err := repo.Create(ctx, item); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	page, err := repo.GetAll(ctx, domain.ItemFilter{From: &from, To: &to, Sort: domain.ItemSort{Field: domain.SortByDate}, Limit: 10})
+	if err != nil {
+		t.Fatalf("GetAll() error = %v", err)
+	}
+	if page.Total != 2 || len(page.Items) != 2 {
+		t.Fatalf("GetAll() total = %d, items = %d, want 2", page.Total, len(page.Items))
+	}
+	if !page.Items[0].Date.Equal(dates[1]) || !page.Items[1].Date.Equal(dates[2]) {
+		t.Errorf("GetAll() dates = %v, %v, want %v, %v", page.Items[0].Date, page.Items[1].Date, dates[1], dates[2])
+	}
+
+	report, err := repo.GetAnalytics(ctx, domain.AnalyticsQuery{From: from, To: to, Currency: "RUB"})
+	if err != nil {
+		t.Fatalf("GetAnalytics() error = %v", err)
+	}
+	if report.Expense.Count != 2 {
+		t.Errorf("GetAnalytics() expense count = %d, want 2", report.Expense.Count)
+	}
+
+	groups, err := repo.GetGroupedAnalytics(ctx, domain.AnalyticsQuery{From: from, To: to, GroupBy: []string{domain.GroupByCategory}, Currency: "RUB"})
+	if err != nil {
+		t.Fatalf("GetGroupedAnalytics() error = %v", err)
+	}
+	if len(groups) != 1 || groups[0].Analytics.Count != 2 {
+		t.Errorf("GetGroupedAnalytics() = %+v, want one group with 2 items", groups)
+	}
+}
+
+func testGetAllOrdering(t *testing.T, repo port.Repository) {
+	ctx := context.Background()
+
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	items := []*domain.Item{
+		{Type: "expense", Amount: domain.NewMoney(300, 0), Currency: "RUB", Category: "Rent", Date: now, CreatedAt: now, UpdatedAt: now},
+		{Type: "expense", Amount: domain.NewMoney(100, 0), Currency: "RUB", Category: "Food", Date: now.Add(time.Hour), CreatedAt: now, UpdatedAt: now},
+		{Type: "expense", Amount: domain.NewMoney(200, 0), Currency: "RUB", Category: "Auto", Date: now.Add(-time.Hour), CreatedAt: now, UpdatedAt: now},
+		{Type: "expense", Amount: domain.NewMoney(100, 0), Currency: "RUB", Category: "Food", Date: now, CreatedAt: now, UpdatedAt: now},
+	}
+	for _, item := range items {
+		if err := repo.Create(ctx, item); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	// При равных значениях поля сортировки порядок задает id в том же направлении
+	tests := []struct {
+		name string
+		sort domain.ItemSort
+		want []int // индексы items в ожидаемом порядке
+	}{
+		{"amount asc", domain.ItemSort{Field: domain.SortByAmount}, []int{1, 3, 2, 0}},
+		{"amount desc", domain.ItemSort{Field: domain.SortByAmount, Desc: true}, []int{0, 2, 3, 1}},
+		{"category asc", domain.ItemSort{Field: domain.SortByCategory}, []int{2, 1, 3, 0}},
+		{"date desc", domain.ItemSort{Field: domain.SortByDate, Desc: true}, []int{1, 3, 0, 2}},
+		{"created_at asc", domain.ItemSort{Field: domain.SortByCreatedAt}, []int{0, 1, 2, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := repo.GetAll(ctx, domain.ItemFilter{Sort: tt.sort, Limit: 10})
+			if err != nil {
+				t.Fatalf("GetAll() error = %v", err)
+			}
+			if len(page.Items) != len(tt.want) {
+				t.Fatalf("GetAll() returned %d items, want %d", len(page.Items), len(tt.want))
+			}
+			for i, index := range tt.want {
+				if page.Items[i].ID != items[index].ID {
+					t.Errorf("GetAll() item %d = %d, want %d", i, page.Items[i].ID, items[index].ID)
+				}
+			}
+		})
+	}
+}
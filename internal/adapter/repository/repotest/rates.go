@@ -0,0 +1,47 @@
+package repotest
+
+import (
+	"context"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"github.com/dontpanicw/SalesTracker/internal/port"
+	"testing"
+	"time"
+)
+
+func testRates(t *testing.T, repo port.Repository) {
+	ctx := context.Background()
+
+	now := time.Now()
+	rate := &domain.ExchangeRate{From: "EUR", To: "RUB", Rate: domain.MustParseRate("99.5"), ValidFrom: now, CreatedAt: now}
+	if err := repo.CreateRate(ctx, rate); err != nil {
+		t.Fatalf("CreateRate() error = %v", err)
+	}
+
+	rates, err := repo.GetRates(ctx, "EUR", "")
+	if err != nil {
+		t.Fatalf("GetRates() error = %v", err)
+	}
+	if len(rates) != 1 || rates[0].Rate != domain.MustParseRate("99.5") {
+		t.Errorf("GetRates() = %+v, want one EUR/RUB rate", rates)
+	}
+
+	// Курс хранится без потерь со всеми 10 знаками после запятой
+	precise := &domain.ExchangeRate{From: "JPY", To: "RUB", Rate: domain.MustParseRate("0.6123456789"), ValidFrom: now, CreatedAt: now}
+	if err := repo.CreateRate(ctx, precise); err != nil {
+		t.Fatalf("CreateRate() error = %v", err)
+	}
+	rates, err = repo.GetRates(ctx, "JPY", "RUB")
+	if err != nil {
+		t.Fatalf("GetRates() error = %v", err)
+	}
+	if len(rates) != 1 || rates[0].Rate != precise.Rate {
+		t.Errorf("GetRates() = %+v, want rate %s", rates, precise.Rate)
+	}
+
+	if err := repo.DeleteRate(ctx, rate.ID); err != nil {
+		t.Fatalf("DeleteRate() error = %v", err)
+	}
+	if err := repo.DeleteRate(ctx, rate.ID); err == nil {
+		t.Error("DeleteRate() expected error for missing rate")
+	}
+}
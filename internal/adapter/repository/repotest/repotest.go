@@ -0,0 +1,61 @@
+// Package repotest содержит общий набор тестов, которому должна соответствовать
+// любая реализация port.Repository. Каждый адаптер запускает его из своих тестов:
+//
+//	func TestRepository(t *testing.T) {
+//		repotest.RunRepositorySuite(t, newTestRepository)
+//	}
+package repotest
+
+import (
+	"github.com/dontpanicw/SalesTracker/internal/port"
+	"testing"
+)
+
+// Factory создает пустое хранилище для одного теста. Ресурсы освобождаются
+// через t.Cleanup; если хранилище недоступно, фабрика пропускает тест через t.Skip.
+type Factory func(t *testing.T) port.Repository
+
+// RunRepositorySuite запускает каждый тест набора подтестом t на новом
+// хранилище из factory
+func RunRepositorySuite(t *testing.T, factory Factory) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, repo port.Repository)
+	}{
+		{"Create", testCreate},
+		{"CreateMany", testCreateMany},
+		{"GetByID", testGetByID},
+		{"Update", testUpdate},
+		{"Update_Version", testUpdateVersion},
+		{"Delete", testDelete},
+		{"Trash", testTrash},
+		{"NotFound", testNotFound},
+		{"GetAll", testGetAll},
+		{"GetAll_Pagination", testGetAllPagination},
+		{"StreamAll", testStreamAll},
+		{"GetAll_Search", testGetAllSearch},
+		{"GetAll_DateRange", testGetAllDateRange},
+		{"GetAll_DateRangeOffset", testGetAllDateRangeOffset},
+		{"GetAll_Ordering", testGetAllOrdering},
+		{"GetAnalytics", testGetAnalytics},
+		{"GetAnalytics_EmptyData", testGetAnalyticsEmptyData},
+		{"GetAnalytics_IncomeAndExpense", testGetAnalyticsIncomeAndExpense},
+		{"GetGroupedAnalytics", testGetGroupedAnalytics},
+		{"GetTimeSeries", testGetTimeSeries},
+		{"GetAnalytics_Percentiles", testGetAnalyticsPercentiles},
+		{"GetAnalytics_CurrencyConversion", testGetAnalyticsCurrencyConversion},
+		{"GetAnalytics_EmptyRange", testGetAnalyticsEmptyRange},
+		{"Rates", testRates},
+		{"IdempotencyKeys", testIdempotencyKeys},
+		{"IdempotencyLease", testIdempotencyLease},
+		{"ApplyItemBatch", testApplyItemBatch},
+		{"History", testHistory},
+		{"Concurrent", testConcurrent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, factory(t))
+		})
+	}
+}
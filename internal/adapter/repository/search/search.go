@@ -0,0 +1,195 @@
+// Package search ищет записи по запросу в синтаксисе websearch_to_tsquery для
+// хранилищ без полнотекстового поиска PostgreSQL: в памяти и SQLite. Ранг и
+// фрагмент с подсветкой приблизительно повторяют ts_rank и ts_headline.
+package search
+
+import (
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"strings"
+	"unicode"
+)
+
+// Веса совпадений в ранге, как у setweight в search_vector: категория — A, описание — B
+const (
+	categoryWeight    = 1.0
+	descriptionWeight = 0.4
+)
+
+// maxSnippetWords ограничивает длину фрагмента, как MaxWords у ts_headline
+const maxSnippetWords = 35
+
+// Query — поисковый запрос: слова объединяются через И, "фраза в кавычках"
+// ищется целиком, -слово исключает записи, or объединяет части запроса через ИЛИ.
+// Запрос — список частей через ИЛИ.
+type Query [][]term
+
+// term — слово или фраза запроса
+type term struct {
+	words  []string
+	negate bool
+}
+
+// lexemes разбивает текст на слова в нижнем регистре, как конфигурация 'simple'
+func lexemes(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), isSeparator)
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// Parse разбирает поисковый запрос. Слова без букв и цифр пропускаются;
+// запрос без слов не находит ничего, как и в PostgreSQL.
+func Parse(query string) Query {
+	var parsed Query
+	var current []term
+	add := func(text string, negate bool) {
+		if words := lexemes(text); len(words) > 0 {
+			current = append(current, term{words: words, negate: negate})
+		}
+	}
+
+	for rest := strings.TrimSpace(query); rest != ""; rest = strings.TrimSpace(rest) {
+		negate := false
+		if rest[0] == '-' {
+			negate, rest = true, rest[1:]
+		}
+		if rest != "" && rest[0] == '"' {
+			phrase, tail, _ := strings.Cut(rest[1:], `"`)
+			add(phrase, negate)
+			rest = tail
+			continue
+		}
+
+		end := strings.IndexFunc(rest, unicode.IsSpace)
+		if end < 0 {
+			end = len(rest)
+		}
+		word := rest[:end]
+		rest = rest[end:]
+		if strings.EqualFold(word, "or") && !negate {
+			if len(current) > 0 {
+				parsed = append(parsed, current)
+				current = nil
+			}
+			continue
+		}
+		add(word, negate)
+	}
+	if len(current) > 0 {
+		parsed = append(parsed, current)
+	}
+	return parsed
+}
+
+// Match проверяет, что запись с категорией category и описанием description
+// подходит под запрос, и возвращает ранг и фрагмент с подсветкой или nil.
+// Каждое вхождение слова запроса в категорию дает categoryWeight, в описание — descriptionWeight.
+func (q Query) Match(category, description string) *domain.SearchMatch {
+	categoryWords := lexemes(category)
+	words := append(append([]string{}, categoryWords...), lexemes(description)...)
+
+	matched := false
+	for _, terms := range q {
+		if matchesAll(words, terms) {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return nil
+	}
+
+	found := make(map[string]bool)
+	for _, terms := range q {
+		for _, term := range terms {
+			if !term.negate {
+				for _, word := range term.words {
+					found[word] = true
+				}
+			}
+		}
+	}
+	rank := 0.0
+	for i, word := range words {
+		if found[word] {
+			if i < len(categoryWords) {
+				rank += categoryWeight
+			} else {
+				rank += descriptionWeight
+			}
+		}
+	}
+	return &domain.SearchMatch{Rank: rank, Snippet: highlight(category+" "+description, found)}
+}
+
+// matchesAll проверяет, что в тексте есть все слова и фразы части запроса и нет исключенных
+func matchesAll(words []string, terms []term) bool {
+	for _, term := range terms {
+		if containsPhrase(words, term.words) == term.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// containsPhrase ищет слова фразы подряд
+func containsPhrase(words, phrase []string) bool {
+	for i := 0; i+len(phrase) <= len(words); i++ {
+		found := true
+		for j, word := range phrase {
+			if words[i+j] != word {
+				found = false
+				break
+			}
+		}
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+// highlight выделяет найденные слова тегом <mark>. Длинный текст обрезается
+// до maxSnippetWords слов, начиная незадолго до первого совпадения.
+func highlight(text string, found map[string]bool) string {
+	// Текст разбивается на чередующиеся слова и разделители
+	var parts []string
+	var isWord []bool
+	start := 0
+	runes := []rune(text)
+	for i := 1; i <= len(runes); i++ {
+		if i == len(runes) || isSeparator(runes[i]) != isSeparator(runes[start]) {
+			parts = append(parts, string(runes[start:i]))
+			isWord = append(isWord, !isSeparator(runes[start]))
+			start = i
+		}
+	}
+
+	var wordIndexes []int
+	first := -1
+	for i, part := range parts {
+		if isWord[i] {
+			if first < 0 && found[strings.ToLower(part)] {
+				first = len(wordIndexes)
+			}
+			wordIndexes = append(wordIndexes, i)
+		}
+	}
+	from, to := 0, len(parts)
+	if len(wordIndexes) > maxSnippetWords {
+		firstWord := max(0, min(first-maxSnippetWords/4, len(wordIndexes)-maxSnippetWords))
+		from = wordIndexes[firstWord]
+		to = wordIndexes[firstWord+maxSnippetWords-1] + 1
+	}
+
+	var b strings.Builder
+	for i := from; i < to; i++ {
+		if isWord[i] && found[strings.ToLower(parts[i])] {
+			b.WriteString("<mark>" + parts[i] + "</mark>")
+		} else {
+			b.WriteString(parts[i])
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
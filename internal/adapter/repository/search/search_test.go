@@ -0,0 +1,48 @@
+package search
+
+import (
+	"testing"
+)
+
+func TestQuery_Match(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		category    string
+		description string
+		wantMatch   bool
+		wantSnippet string
+	}{
+		{name: "word in category", query: "salary", category: "Salary", description: "March", wantMatch: true, wantSnippet: "<mark>Salary</mark> March"},
+		{name: "all words required", query: "salary bonus", category: "Salary", description: "March", wantMatch: false},
+		{name: "phrase", query: `"coffee beans"`, category: "Food", description: "Coffee beans, 1kg", wantMatch: true, wantSnippet: "Food <mark>Coffee</mark> <mark>beans</mark>, 1kg"},
+		{name: "phrase words apart", query: `"coffee beans"`, category: "Food", description: "beans and coffee", wantMatch: false},
+		{name: "negation", query: "food -coffee", category: "Food", description: "Coffee", wantMatch: false},
+		{name: "or", query: "rent or salary", category: "Salary", description: "", wantMatch: true, wantSnippet: "<mark>Salary</mark>"},
+		{name: "no words", query: "- !", category: "Salary", description: "", wantMatch: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match := Parse(tt.query).Match(tt.category, tt.description)
+			if (match != nil) != tt.wantMatch {
+				t.Fatalf("Match() = %+v, want match %v", match, tt.wantMatch)
+			}
+			if match != nil && match.Snippet != tt.wantSnippet {
+				t.Errorf("Match() snippet = %q, want %q", match.Snippet, tt.wantSnippet)
+			}
+		})
+	}
+}
+
+func TestQuery_Match_Rank(t *testing.T) {
+	query := Parse("coffee")
+	inCategory := query.Match("Coffee", "")
+	inDescription := query.Match("Food", "Coffee")
+	if inCategory == nil || inDescription == nil {
+		t.Fatal("Match() = nil, want match")
+	}
+	if inCategory.Rank <= inDescription.Rank {
+		t.Errorf("Match() rank in category = %v, want greater than in description %v", inCategory.Rank, inDescription.Rank)
+	}
+}
@@ -0,0 +1,75 @@
+package sqlite
+
+import (
+	"context"
+	"github.com/dontpanicw/SalesTracker/internal/adapter/repository/aggregate"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"time"
+)
+
+// convertedItems возвращает действующие записи за период from–to с суммами,
+// пересчитанными в валюту отчета. В SQLite нет PERCENTILE_CONT и date_trunc,
+// поэтому записи выбираются целиком, а статистика считается пакетом aggregate.
+func (r *repository) convertedItems(ctx context.Context, from, to time.Time, currency string) ([]aggregate.Item, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT type, category, currency, date, amount
+		FROM items
+		WHERE date >= $1 AND date <= $2 AND deleted_at IS NULL
+		ORDER BY id
+	`, timestamp(from), timestamp(to))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	var items []aggregate.Item
+	needRates := false
+	for rows.Next() {
+		var item aggregate.Item
+		if err := rows.Scan(&item.Type, &item.Category, &item.Currency, &item.Date, cents{&item.Amount}); err != nil {
+			return nil, wrapError(err)
+		}
+		needRates = needRates || item.Currency != currency
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrapError(err)
+	}
+
+	var rates []*domain.ExchangeRate
+	if needRates {
+		// Нужны курсы в валюту отчета и из нее: обратный курс используется как 1 / rate
+		if rates, err = r.selectRates(ctx, `WHERE base_currency = $1 OR quote_currency = $1`, currency); err != nil {
+			return nil, err
+		}
+	}
+	return aggregate.Convert(items, rates, currency)
+}
+
+func (r *repository) GetAnalytics(ctx context.Context, query domain.AnalyticsQuery) (*domain.AnalyticsReport, error) {
+	items, err := r.convertedItems(ctx, query.From, query.To, query.Currency)
+	if err != nil {
+		return nil, err
+	}
+	return aggregate.Report(items, query), nil
+}
+
+func (r *repository) GetGroupedAnalytics(ctx context.Context, query domain.AnalyticsQuery) ([]*domain.AnalyticsGroup, error) {
+	if err := aggregate.CheckGroupBy(query.GroupBy); err != nil {
+		return nil, err
+	}
+	items, err := r.convertedItems(ctx, query.From, query.To, query.Currency)
+	if err != nil {
+		return nil, err
+	}
+	return aggregate.Groups(items, query), nil
+}
+
+// GetTimeSeries строит непрерывный ряд интервалов, интервалы без записей — с нулями
+func (r *repository) GetTimeSeries(ctx context.Context, query domain.TimeSeriesQuery) ([]*domain.TimeSeriesPoint, error) {
+	items, err := r.convertedItems(ctx, query.From, query.To, query.Currency)
+	if err != nil {
+		return nil, err
+	}
+	return aggregate.TimeSeries(items, query), nil
+}
@@ -0,0 +1,69 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"time"
+)
+
+// ApplyItemBatch создает, обновляет и удаляет записи в одной транзакции.
+// Ошибки отдельных обновлений и удалений (нет записи, не совпала версия)
+// возвращаются в failed по id записи. Если partial = false и такие ошибки есть,
+// транзакция откатывается.
+func (r *repository) ApplyItemBatch(ctx context.Context, batch *domain.ItemBatch, partial bool) (map[int64]error, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer tx.Rollback()
+
+	for _, item := range batch.Create {
+		if err := insertItem(ctx, tx, item); err != nil {
+			return nil, wrapError(err)
+		}
+	}
+
+	failed := make(map[int64]error)
+	for _, item := range batch.Update {
+		err := updateItem(ctx, tx, item)
+		if err == sql.ErrNoRows {
+			err = missingItemError(ctx, tx, item.ID, false)
+		}
+		if err := batchError(err, item.ID, failed); err != nil {
+			return nil, err
+		}
+	}
+	deletedAt := time.Now()
+	for _, ref := range batch.Delete {
+		err := deleteItem(ctx, tx, ref.ID, ref.Version, deletedAt)
+		if err == sql.ErrNoRows {
+			err = missingItemError(ctx, tx, ref.ID, false)
+		}
+		if err := batchError(err, ref.ID, failed); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(failed) > 0 && !partial {
+		return failed, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, wrapError(err)
+	}
+	return failed, nil
+}
+
+// batchError записывает в failed ошибку изменения записи id, если запись не найдена
+// или не совпала версия. Остальные ошибки прерывают пакет и возвращаются.
+func batchError(err error, id int64, failed map[int64]error) error {
+	if err == nil {
+		return nil
+	}
+	if err == domain.ErrVersionMismatch || errors.Is(err, domain.ErrNotFound) {
+		failed[id] = err
+		return nil
+	}
+	return wrapError(err)
+}
@@ -0,0 +1,63 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"strings"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// wrapError переводит ошибки драйвера в ошибки предметной области так же,
+// как PostgreSQL-адаптер: нарушения ограничений — в конфликт или ошибку
+// валидации, занятую базу и истекший таймаут — в недоступность.
+// Остальные ошибки возвращаются как есть.
+func wrapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		return err
+	}
+
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.Code() {
+		case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+			return domain.NewConflictError("record conflicts with existing data", err)
+		case sqlite3.SQLITE_CONSTRAINT_CHECK, sqlite3.SQLITE_CONSTRAINT_NOTNULL:
+			return &domain.Error{Kind: domain.ErrValidation, Message: "value violates constraint " + constraintName(sqliteErr), Err: err}
+		}
+		// Расширенные коды, например SQLITE_BUSY_SNAPSHOT, сравниваются по младшему байту
+		switch sqliteErr.Code() & 0xff {
+		case sqlite3.SQLITE_BUSY, sqlite3.SQLITE_LOCKED, sqlite3.SQLITE_FULL:
+			return domain.NewUnavailableError(err)
+		}
+		return err
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return domain.NewUnavailableError(err)
+	}
+	return err
+}
+
+// constraintName возвращает имя нарушенного ограничения из текста ошибки, например
+// items_type_check из "constraint failed: CHECK constraint failed: items_type_check (275)"
+func constraintName(err *sqlite.Error) string {
+	message := err.Error()
+	name := message[strings.LastIndex(message, "constraint failed: ")+len("constraint failed: "):]
+	if i := strings.LastIndex(name, " ("); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// isUniqueViolation проверяет, что запрос нарушил ограничение уникальности
+func isUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+}
@@ -0,0 +1,83 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"testing"
+)
+
+func TestWrapError(t *testing.T) {
+	repo := newTestRepository(t)
+	if _, err := repo.db.Exec(`
+		INSERT INTO exchange_rates (base_currency, quote_currency, rate, valid_from, created_at)
+		VALUES ('USD', 'RUB', 90, '2024-01-01 00:00:00.000000', '2024-01-01 00:00:00.000000')
+	`); err != nil {
+		t.Fatalf("failed to insert rate: %v", err)
+	}
+
+	// Ошибки драйвера получаются настоящими запросами: *sqlite.Error нельзя создать вне пакета
+	driverError := func(query string) error {
+		_, err := repo.db.Exec(query)
+		if err == nil {
+			t.Fatalf("query %q succeeded, want error", query)
+		}
+		return err
+	}
+
+	tests := []struct {
+		name     string
+		err      error
+		wantKind error
+		wantMsg  string
+	}{
+		{
+			name: "unique violation",
+			err: driverError(`INSERT INTO exchange_rates (base_currency, quote_currency, rate, valid_from, created_at)
+				VALUES ('USD', 'RUB', 91, '2024-01-01 00:00:00.000000', '2024-01-02 00:00:00.000000')`),
+			wantKind: domain.ErrConflict,
+		},
+		{
+			name: "check violation",
+			err: driverError(`INSERT INTO items (type, amount, category, date, created_at, updated_at)
+				VALUES ('income', -1, 'Salary', '2024-01-01', '2024-01-01', '2024-01-01')`),
+			wantKind: domain.ErrValidation,
+			wantMsg:  "value violates constraint items_amount_check",
+		},
+		{
+			name:     "not null violation",
+			err:      driverError(`INSERT INTO items (type, amount, date, created_at, updated_at) VALUES ('income', 1, '2024-01-01', '2024-01-01', '2024-01-01')`),
+			wantKind: domain.ErrValidation,
+		},
+		{name: "deadline exceeded", err: context.DeadlineExceeded, wantKind: domain.ErrUnavailable},
+		{name: "domain error passes through", err: domain.NewNotFoundError("item not found"), wantKind: domain.ErrNotFound},
+		{name: "undefined table", err: driverError(`SELECT * FROM missing`)},
+		{name: "unknown error", err: errors.New("boom")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := wrapError(tt.err)
+			if !errors.Is(got, tt.err) {
+				t.Errorf("wrapError() = %v, lost original error", got)
+			}
+			if tt.wantKind == nil {
+				if got != tt.err {
+					t.Errorf("wrapError() = %v, want unchanged error", got)
+				}
+				return
+			}
+			if !errors.Is(got, tt.wantKind) {
+				t.Errorf("wrapError() = %v, want kind %v", got, tt.wantKind)
+			}
+			var domainErr *domain.Error
+			if tt.wantMsg != "" && (!errors.As(got, &domainErr) || domainErr.Message != tt.wantMsg) {
+				t.Errorf("wrapError() = %v, want message %q", got, tt.wantMsg)
+			}
+		})
+	}
+
+	if wrapError(nil) != nil {
+		t.Error("wrapError(nil) != nil")
+	}
+}
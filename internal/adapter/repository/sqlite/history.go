@@ -0,0 +1,133 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"strconv"
+	"strings"
+)
+
+// AddHistory сохраняет записи истории одной транзакцией
+func (r *repository) AddHistory(ctx context.Context, entries []*domain.HistoryEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return wrapError(err)
+	}
+	defer tx.Rollback()
+
+	for _, entry := range entries {
+		before, err := itemSnapshot(entry.Before)
+		if err != nil {
+			return err
+		}
+		after, err := itemSnapshot(entry.After)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO item_history (item_id, action, actor, before, after, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, entry.ItemID, entry.Action, entry.Actor, before, after, timestamp(entry.CreatedAt))
+		if err != nil {
+			return wrapError(err)
+		}
+	}
+	return wrapError(tx.Commit())
+}
+
+// itemSnapshot сериализует состояние записи для колонок before и after
+func itemSnapshot(item *domain.Item) (interface{}, error) {
+	if item == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal item snapshot: %w", err)
+	}
+	return string(data), nil
+}
+
+// GetHistory возвращает страницу истории изменений от новых к старым
+func (r *repository) GetHistory(ctx context.Context, filter domain.HistoryFilter) (*domain.HistoryPage, error) {
+	cursor, err := filter.DecodeCursor()
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	var conditions []string
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if filter.ItemID != 0 {
+		conditions = append(conditions, "item_id = "+arg(filter.ItemID))
+	}
+	if filter.Actor != "" {
+		conditions = append(conditions, "actor = "+arg(filter.Actor))
+	}
+	if filter.From != nil {
+		conditions = append(conditions, "created_at >= "+arg(timestamp(*filter.From)))
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "created_at <= "+arg(timestamp(*filter.To)))
+	}
+	if cursor != 0 {
+		conditions = append(conditions, "id < "+arg(cursor))
+	}
+	query := `SELECT id, item_id, action, actor, before, after, created_at FROM item_history`
+	if len(conditions) > 0 {
+		query += "\n\t\tWHERE " + strings.Join(conditions, " AND ")
+	}
+	// Лишняя запись показывает, есть ли следующая страница
+	query += "\n\t\tORDER BY id DESC LIMIT " + arg(filter.Limit+1)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	page := &domain.HistoryPage{Entries: []*domain.HistoryEntry{}}
+	for rows.Next() {
+		entry := &domain.HistoryEntry{}
+		var before, after *string
+		if err := rows.Scan(&entry.ID, &entry.ItemID, &entry.Action, &entry.Actor, &before, &after, &entry.CreatedAt); err != nil {
+			return nil, wrapError(err)
+		}
+		if entry.Before, err = parseSnapshot(before); err != nil {
+			return nil, err
+		}
+		if entry.After, err = parseSnapshot(after); err != nil {
+			return nil, err
+		}
+		page.Entries = append(page.Entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrapError(err)
+	}
+
+	if len(page.Entries) > filter.Limit {
+		page.Entries = page.Entries[:filter.Limit]
+		page.NextCursor = strconv.FormatInt(page.Entries[filter.Limit-1].ID, 10)
+	}
+	return page, nil
+}
+
+// parseSnapshot восстанавливает запись из колонки before или after
+func parseSnapshot(data *string) (*domain.Item, error) {
+	if data == nil {
+		return nil, nil
+	}
+	item := &domain.Item{}
+	if err := json.Unmarshal([]byte(*data), item); err != nil {
+		return nil, fmt.Errorf("failed to parse item snapshot: %w", err)
+	}
+	return item, nil
+}
@@ -0,0 +1,82 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+)
+
+// ReserveIdempotencyKey удаляет истекшие ключи и сохраняет новый. Незавершенный ключ
+// с истекшей арендой перехватывается. Если ключ уже есть, возвращает сохраненную запись.
+func (r *repository) ReserveIdempotencyKey(ctx context.Context, record *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error) {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at < $1`, timestamp(record.CreatedAt)); err != nil {
+		return nil, wrapError(err)
+	}
+
+	// Незавершенный ключ с истекшей арендой перезаписывается: первый запрос
+	// не сохранил ответ вовремя
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO idempotency_keys (key, request_hash, created_at, locked_until, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (key) DO UPDATE
+		SET request_hash = excluded.request_hash,
+			created_at = excluded.created_at,
+			locked_until = excluded.locked_until,
+			expires_at = excluded.expires_at
+		WHERE idempotency_keys.response IS NULL AND idempotency_keys.locked_until < excluded.created_at
+	`, record.Key, record.RequestHash, timestamp(record.CreatedAt), timestamp(record.LockedUntil), timestamp(record.ExpiresAt))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	reserved, err := result.RowsAffected()
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	if reserved == 1 {
+		return nil, nil
+	}
+
+	existing := &domain.IdempotencyRecord{}
+	err = r.db.QueryRowContext(ctx, `
+		SELECT key, request_hash, response, created_at, locked_until, expires_at
+		FROM idempotency_keys
+		WHERE key = $1
+	`, record.Key).Scan(&existing.Key, &existing.RequestHash, &existing.Response, &existing.CreatedAt, &existing.LockedUntil, &existing.ExpiresAt)
+	if err == sql.ErrNoRows {
+		// Ключ удалили между вставкой и чтением: первый запрос завершился ошибкой
+		return nil, domain.NewConflictError("concurrent request with this idempotency key failed, please retry", err)
+	}
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return existing, nil
+}
+
+// CompleteIdempotencyKey сохраняет ответ, если резервирование record не перехвачено.
+// Резервирование узнается по locked_until.
+func (r *repository) CompleteIdempotencyKey(ctx context.Context, record *domain.IdempotencyRecord, response []byte) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE idempotency_keys SET response = $1
+		WHERE key = $2 AND locked_until = $3 AND response IS NULL
+	`, response, record.Key, timestamp(record.LockedUntil))
+	if err != nil {
+		return wrapError(err)
+	}
+	completed, err := result.RowsAffected()
+	if err != nil {
+		return wrapError(err)
+	}
+	if completed == 0 {
+		return domain.ErrIdempotencyKeyTakenOver
+	}
+	return nil
+}
+
+// ReleaseIdempotencyKey удаляет резервирование record, чтобы запрос можно было повторить
+func (r *repository) ReleaseIdempotencyKey(ctx context.Context, record *domain.IdempotencyRecord) error {
+	_, err := r.db.ExecContext(ctx, `
+		DELETE FROM idempotency_keys
+		WHERE key = $1 AND locked_until = $2 AND response IS NULL
+	`, record.Key, timestamp(record.LockedUntil))
+	return wrapError(err)
+}
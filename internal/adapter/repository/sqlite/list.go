@@ -0,0 +1,274 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"github.com/dontpanicw/SalesTracker/internal/adapter/repository/search"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"strconv"
+	"strings"
+	"time"
+
+	"modernc.org/sqlite"
+)
+
+// В SQLite нет websearch_to_tsquery, ts_rank и ts_headline, поэтому поиск
+// выполняется в Go функциями search_rank и search_snippet. search_rank
+// возвращает NULL, если запись не подходит под запрос.
+func init() {
+	sqlite.MustRegisterDeterministicScalarFunction("search_rank", 3, searchFunction(func(match *domain.SearchMatch) driver.Value {
+		return match.Rank
+	}))
+	sqlite.MustRegisterDeterministicScalarFunction("search_snippet", 3, searchFunction(func(match *domain.SearchMatch) driver.Value {
+		return match.Snippet
+	}))
+}
+
+// searchFunction возвращает функцию SQLite (query, category, description),
+// которая сопоставляет запись с запросом и возвращает часть результата
+func searchFunction(result func(match *domain.SearchMatch) driver.Value) func(*sqlite.FunctionContext, []driver.Value) (driver.Value, error) {
+	return func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		query, _ := args[0].(string)
+		category, _ := args[1].(string)
+		description, _ := args[2].(string)
+		match := search.Parse(query).Match(category, description)
+		if match == nil {
+			return nil, nil
+		}
+		return result(match), nil
+	}
+}
+
+// sortColumns сопоставляет поля сортировки с колонками
+var sortColumns = map[string]string{
+	domain.SortByDate:      "date",
+	domain.SortByAmount:    "amount",
+	domain.SortByCategory:  "category",
+	domain.SortByCreatedAt: "created_at",
+}
+
+// itemQuery содержит части запроса списка записей, общие для GetAll и StreamAll
+type itemQuery struct {
+	filter     domain.ItemFilter
+	conditions []string
+	args       []interface{}
+	columns    string // колонки выборки, при поиске — с рангом и фрагментом
+	sortColumn string
+}
+
+// newItemQuery строит условия выборки по фильтру без учета курсора и размера страницы
+func newItemQuery(filter domain.ItemFilter) (*itemQuery, error) {
+	sortColumn, ok := sortColumns[filter.Sort.Field]
+	if filter.Sort.Field == domain.SortByRank && filter.Query != "" {
+		ok = true // выражение ранга зависит от запроса и подставляется ниже
+	}
+	if !ok {
+		return nil, domain.NewValidationError("sort", fmt.Sprintf("unknown sort field '%s'", filter.Sort.Field))
+	}
+
+	q := &itemQuery{filter: filter, columns: itemColumns, sortColumn: sortColumn}
+	if filter.Deleted {
+		q.conditions = append(q.conditions, "deleted_at IS NOT NULL")
+	} else {
+		q.conditions = append(q.conditions, "deleted_at IS NULL")
+	}
+	if filter.From != nil {
+		q.conditions = append(q.conditions, "date >= "+q.arg(timestamp(*filter.From)))
+	}
+	if filter.To != nil {
+		q.conditions = append(q.conditions, "date <= "+q.arg(timestamp(*filter.To)))
+	}
+	if len(filter.Types) > 0 {
+		q.conditions = append(q.conditions, "type IN ("+q.list(filter.Types)+")")
+	}
+	if len(filter.Categories) > 0 {
+		q.conditions = append(q.conditions, "category IN ("+q.list(filter.Categories)+")")
+	}
+	if filter.MinAmount != nil {
+		q.conditions = append(q.conditions, "amount >= "+q.arg(filter.MinAmount.Cents()))
+	}
+	if filter.MaxAmount != nil {
+		q.conditions = append(q.conditions, "amount <= "+q.arg(filter.MaxAmount.Cents()))
+	}
+
+	// При поиске к записи добавляются ранг и фрагмент с подсветкой совпадений
+	if filter.Query != "" {
+		query := q.arg(filter.Query)
+		rankExpr := "search_rank(" + query + ", category, description)"
+		q.conditions = append(q.conditions, rankExpr+" IS NOT NULL")
+		q.columns += `,
+			` + rankExpr + ` as rank,
+			search_snippet(` + query + `, category, description) as snippet`
+		if filter.Sort.Field == domain.SortByRank {
+			q.sortColumn = rankExpr
+		}
+	}
+	return q, nil
+}
+
+// arg добавляет параметр запроса и возвращает его плейсхолдер
+func (q *itemQuery) arg(value interface{}) string {
+	q.args = append(q.args, value)
+	return fmt.Sprintf("$%d", len(q.args))
+}
+
+// list добавляет параметры для условия IN и возвращает их плейсхолдеры
+func (q *itemQuery) list(values []string) string {
+	list := make([]string, len(values))
+	for i, value := range values {
+		list[i] = q.arg(value)
+	}
+	return strings.Join(list, ", ")
+}
+
+// where возвращает условия фильтра, объединенные через AND
+func (q *itemQuery) where() string {
+	if len(q.conditions) == 0 {
+		return ""
+	}
+	return "\n\t\tWHERE " + strings.Join(q.conditions, " AND ")
+}
+
+// after добавляет условие keyset-пагинации: записи после пары (поле сортировки, id) из курсора
+func (q *itemQuery) after(cursor *domain.Cursor) error {
+	value, err := cursorValue(cursor, q.filter.Sort.Field)
+	if err != nil {
+		return err
+	}
+	operator := ">"
+	if q.filter.Sort.Desc {
+		operator = "<"
+	}
+	q.conditions = append(q.conditions, fmt.Sprintf("(%s, id) %s (%s, %s)",
+		q.sortColumn, operator, q.arg(value), q.arg(cursor.ID)))
+	return nil
+}
+
+// cursorValue переводит значение поля сортировки из курсора в формат колонки
+func cursorValue(cursor *domain.Cursor, field string) (interface{}, error) {
+	var value interface{}
+	var err error
+	switch field {
+	case domain.SortByAmount:
+		var amount domain.Money
+		amount, err = domain.ParseMoney(cursor.Value)
+		value = amount.Cents()
+	case domain.SortByCategory:
+		value = cursor.Value
+	case domain.SortByRank:
+		value, err = strconv.ParseFloat(cursor.Value, 64)
+	default:
+		var t time.Time
+		t, err = time.Parse(time.RFC3339Nano, cursor.Value)
+		value = timestamp(t)
+	}
+	if err != nil {
+		return nil, domain.NewValidationError("cursor", "invalid cursor")
+	}
+	return value, nil
+}
+
+// selectSQL возвращает запрос выборки записей в порядке сортировки фильтра
+func (q *itemQuery) selectSQL() string {
+	direction := "ASC"
+	if q.filter.Sort.Desc {
+		direction = "DESC"
+	}
+	return `
+		SELECT ` + q.columns + `
+		FROM items` + q.where() + `
+		ORDER BY ` + q.sortColumn + ` ` + direction + `, id ` + direction
+}
+
+// scan читает запись из результата selectSQL
+func (q *itemQuery) scan(rows *sql.Rows) (*domain.Item, error) {
+	if q.filter.Query == "" {
+		return scanItem(rows)
+	}
+	match := &domain.SearchMatch{}
+	item, err := scanItem(rows, &match.Rank, &match.Snippet)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	item.Match = match
+	return item, nil
+}
+
+// GetAll возвращает страницу записей по фильтру. Пагинация keyset: следующая
+// страница начинается после пары (поле сортировки, id) из курсора.
+func (r *repository) GetAll(ctx context.Context, filter domain.ItemFilter) (*domain.ItemPage, error) {
+	cursor, err := filter.DecodeCursor()
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	q, err := newItemQuery(filter)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	page := &domain.ItemPage{}
+	countQuery := `SELECT COUNT(*) FROM items` + q.where()
+	if err := r.db.QueryRowContext(ctx, countQuery, q.args...).Scan(&page.Total); err != nil {
+		return nil, wrapError(err)
+	}
+
+	if cursor != nil {
+		if err := q.after(cursor); err != nil {
+			return nil, err
+		}
+	}
+	// Запрашиваем на одну запись больше, чтобы узнать, есть ли следующая страница
+	query := q.selectSQL() + `
+		LIMIT ` + q.arg(filter.Limit+1)
+	rows, err := r.db.QueryContext(ctx, query, q.args...)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := q.scan(rows)
+		if err != nil {
+			return nil, wrapError(err)
+		}
+		page.Items = append(page.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrapError(err)
+	}
+
+	if len(page.Items) > filter.Limit {
+		page.Items = page.Items[:filter.Limit]
+		page.NextCursor = domain.NewCursor(page.Items[filter.Limit-1], filter.Sort)
+	}
+	return page, nil
+}
+
+// StreamAll передает в fn все записи по фильтру в порядке сортировки, не загружая
+// их в память целиком. Курсор и размер страницы не учитываются.
+// Ошибка fn прерывает выборку и возвращается как есть.
+func (r *repository) StreamAll(ctx context.Context, filter domain.ItemFilter, fn func(*domain.Item) error) error {
+	q, err := newItemQuery(filter)
+	if err != nil {
+		return wrapError(err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, q.selectSQL(), q.args...)
+	if err != nil {
+		return wrapError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := q.scan(rows)
+		if err != nil {
+			return wrapError(err)
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return wrapError(rows.Err())
+}
@@ -0,0 +1,69 @@
+package sqlite
+
+import (
+	"context"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+)
+
+func (r *repository) CreateRate(ctx context.Context, rate *domain.ExchangeRate) error {
+	query := `
+		INSERT INTO exchange_rates (base_currency, quote_currency, rate, valid_from, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	// Курс хранится целым числом десятимиллиардных долей, см. domain.Rate
+	err := r.db.QueryRowContext(
+		ctx, query,
+		rate.From, rate.To, int64(rate.Rate), timestamp(rate.ValidFrom), timestamp(rate.CreatedAt),
+	).Scan(&rate.ID)
+	if isUniqueViolation(err) {
+		return domain.NewConflictError("exchange rate for this pair and valid_from already exists", err)
+	}
+	return wrapError(err)
+}
+
+func (r *repository) GetRates(ctx context.Context, from, to string) ([]*domain.ExchangeRate, error) {
+	return r.selectRates(ctx, `
+		WHERE ($1 = '' OR base_currency = $1)
+		  AND ($2 = '' OR quote_currency = $2)
+		ORDER BY base_currency, quote_currency, valid_from DESC
+	`, from, to)
+}
+
+// selectRates возвращает курсы, отобранные условием where
+func (r *repository) selectRates(ctx context.Context, where string, args ...interface{}) ([]*domain.ExchangeRate, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, base_currency, quote_currency, rate, valid_from, created_at
+		FROM exchange_rates
+	`+where, args...)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	var rates []*domain.ExchangeRate
+	for rows.Next() {
+		rate := &domain.ExchangeRate{}
+		if err := rows.Scan(&rate.ID, &rate.From, &rate.To, &rate.Rate, &rate.ValidFrom, &rate.CreatedAt); err != nil {
+			return nil, wrapError(err)
+		}
+		rates = append(rates, rate)
+	}
+	return rates, wrapError(rows.Err())
+}
+
+func (r *repository) DeleteRate(ctx context.Context, id int64) error {
+	query := `DELETE FROM exchange_rates WHERE id = $1`
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return wrapError(err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return wrapError(err)
+	}
+	if rows == 0 {
+		return domain.NewNotFoundError("exchange rate not found")
+	}
+	return nil
+}
@@ -0,0 +1,290 @@
+// Package sqlite реализует port.Repository поверх файла SQLite для запуска
+// на одной машине без PostgreSQL. Схема создается миграциями
+// migrations.NewSQLite. Поиск и аналитика, для которых в SQLite нет
+// аналогов websearch_to_tsquery и PERCENTILE_CONT, считаются в Go.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"github.com/dontpanicw/SalesTracker/internal/port"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// timeLayout — формат времени в колонках TIMESTAMP: UTC с микросекундами,
+// поэтому строки сравниваются в том же порядке, что и моменты времени
+const timeLayout = "2006-01-02 15:04:05.000000"
+
+// pragmas настраивают каждое соединение: ожидание блокировки вместо ошибки
+// SQLITE_BUSY, WAL для чтения во время записи и немедленная блокировка
+// на запись в транзакциях, чтобы они не падали при повышении блокировки
+const pragmas = "_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_txlock=immediate"
+
+type repository struct {
+	db *sql.DB
+}
+
+// New открывает базу SQLite в файле path. Файл создается, если его нет.
+func New(path string) (port.Repository, error) {
+	db, err := sql.Open("sqlite", dsn(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return &repository{db: db}, nil
+}
+
+// dsn добавляет к пути параметры соединения
+func dsn(path string) string {
+	if strings.Contains(path, "?") {
+		return path + "&" + pragmas
+	}
+	return path + "?" + pragmas
+}
+
+// timestamp переводит время в формат колонок TIMESTAMP
+func timestamp(t time.Time) string {
+	return t.UTC().Round(time.Microsecond).Format(timeLayout)
+}
+
+// cents — приемник суммы, которая хранится в целых копейках
+type cents struct {
+	money *domain.Money
+}
+
+func (c cents) Scan(src interface{}) error {
+	value, ok := src.(int64)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into amount in cents", src)
+	}
+	*c.money = domain.Money(value)
+	return nil
+}
+
+// itemColumns — колонки записи в порядке полей, которые читает scanItem
+const itemColumns = `id, type, amount, currency, category, description, date, created_at, updated_at, version, deleted_at`
+
+// rowScanner — общий интерфейс *sql.Row и *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanItem читает колонки itemColumns и дополнительные приемники extra
+func scanItem(row rowScanner, extra ...interface{}) (*domain.Item, error) {
+	item := &domain.Item{}
+	dest := append([]interface{}{
+		&item.ID, &item.Type, cents{&item.Amount}, &item.Currency, &item.Category,
+		&item.Description, &item.Date, &item.CreatedAt, &item.UpdatedAt, &item.Version, &item.DeletedAt,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// execer — общий интерфейс *sql.DB и *sql.Tx
+type execer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// insertItem сохраняет запись и заполняет ID и версию
+func insertItem(ctx context.Context, db execer, item *domain.Item) error {
+	query := `
+		INSERT INTO items (type, amount, currency, category, description, date, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, version
+	`
+	return db.QueryRowContext(
+		ctx, query,
+		item.Type, item.Amount.Cents(), item.Currency, item.Category, item.Description, timestamp(item.Date),
+		timestamp(item.CreatedAt), timestamp(item.UpdatedAt),
+	).Scan(&item.ID, &item.Version)
+}
+
+func (r *repository) Create(ctx context.Context, item *domain.Item) error {
+	return wrapError(insertItem(ctx, r.db, item))
+}
+
+// CreateMany сохраняет записи одной транзакцией
+func (r *repository) CreateMany(ctx context.Context, items []*domain.Item) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return wrapError(err)
+	}
+	defer tx.Rollback()
+
+	for _, item := range items {
+		if err := insertItem(ctx, tx, item); err != nil {
+			return wrapError(err)
+		}
+	}
+	return wrapError(tx.Commit())
+}
+
+func (r *repository) GetByID(ctx context.Context, id int64) (*domain.Item, error) {
+	query := `
+		SELECT ` + itemColumns + `
+		FROM items
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	item, err := scanItem(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.NewNotFoundError("item not found")
+	}
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return item, nil
+}
+
+// placeholders возвращает список плейсхолдеров $from, $from+1, ... для n параметров
+func placeholders(from, n int) string {
+	list := make([]string, n)
+	for i := range list {
+		list[i] = fmt.Sprintf("$%d", from+i)
+	}
+	return strings.Join(list, ", ")
+}
+
+// int64Args переводит идентификаторы в параметры запроса
+func int64Args(ids []int64) []interface{} {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return args
+}
+
+// GetByIDs возвращает действующие записи с указанными id, отсутствующие пропускаются
+func (r *repository) GetByIDs(ctx context.Context, ids []int64) ([]*domain.Item, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	query := `
+		SELECT ` + itemColumns + `
+		FROM items
+		WHERE id IN (` + placeholders(1, len(ids)) + `) AND deleted_at IS NULL
+	`
+	rows, err := r.db.QueryContext(ctx, query, int64Args(ids)...)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	var items []*domain.Item
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, wrapError(err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrapError(err)
+	}
+	return items, nil
+}
+
+// updateItem сохраняет запись с проверкой версии и заполняет CreatedAt и новую версию.
+// Если запись не изменена, возвращает sql.ErrNoRows.
+func updateItem(ctx context.Context, db execer, item *domain.Item) error {
+	query := `
+		UPDATE items
+		SET type = $1, amount = $2, currency = $3, category = $4, description = $5, date = $6,
+			updated_at = $7, version = version + 1
+		WHERE id = $8 AND deleted_at IS NULL AND ($9 = 0 OR version = $9)
+		RETURNING created_at, version
+	`
+	return db.QueryRowContext(
+		ctx, query,
+		item.Type, item.Amount.Cents(), item.Currency, item.Category, item.Description, timestamp(item.Date),
+		timestamp(item.UpdatedAt), item.ID, item.Version,
+	).Scan(&item.CreatedAt, &item.Version)
+}
+
+// Update сохраняет запись и заполняет CreatedAt и новую версию значениями из базы
+func (r *repository) Update(ctx context.Context, item *domain.Item) error {
+	err := updateItem(ctx, r.db, item)
+	if err == sql.ErrNoRows {
+		return missingItemError(ctx, r.db, item.ID, false)
+	}
+	return wrapError(err)
+}
+
+// deleteItem переносит запись в корзину с проверкой версии.
+// Если запись не изменена, возвращает sql.ErrNoRows.
+func deleteItem(ctx context.Context, db execer, id, version int64, deletedAt time.Time) error {
+	query := `
+		UPDATE items
+		SET deleted_at = $3, version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL AND ($2 = 0 OR version = $2)
+		RETURNING id
+	`
+	return db.QueryRowContext(ctx, query, id, version, timestamp(deletedAt)).Scan(&id)
+}
+
+// Delete переносит запись в корзину: запись остается в базе с отметкой deleted_at
+func (r *repository) Delete(ctx context.Context, id int64, version int64) error {
+	err := deleteItem(ctx, r.db, id, version, time.Now())
+	if err == sql.ErrNoRows {
+		return missingItemError(ctx, r.db, id, false)
+	}
+	return wrapError(err)
+}
+
+// Restore возвращает запись из корзины
+func (r *repository) Restore(ctx context.Context, id int64, version int64) (*domain.Item, error) {
+	query := `
+		UPDATE items
+		SET deleted_at = NULL, version = version + 1
+		WHERE id = $1 AND deleted_at IS NOT NULL AND ($2 = 0 OR version = $2)
+		RETURNING ` + itemColumns
+	item, err := scanItem(r.db.QueryRowContext(ctx, query, id, version))
+	if err == sql.ErrNoRows {
+		return nil, missingItemError(ctx, r.db, id, true)
+	}
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return item, nil
+}
+
+// PurgeDeleted окончательно удаляет записи, перенесенные в корзину раньше before
+func (r *repository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM items WHERE deleted_at < $1`, timestamp(before))
+	if err != nil {
+		return 0, wrapError(err)
+	}
+	purged, err := result.RowsAffected()
+	return purged, wrapError(err)
+}
+
+// missingItemError объясняет, почему условное изменение не затронуло ни одной строки:
+// записи нет или ее версия не совпала с ожидаемой. deleted указывает,
+// где искать запись: в корзине или среди действующих.
+func missingItemError(ctx context.Context, db execer, id int64, deleted bool) error {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM items WHERE id = $1 AND (deleted_at IS NOT NULL) = $2)`
+	err := db.QueryRowContext(ctx, query, id, deleted).Scan(&exists)
+	if err != nil {
+		return wrapError(err)
+	}
+	if exists {
+		return domain.ErrVersionMismatch
+	}
+	return domain.NewNotFoundError("item not found")
+}
+
+func (r *repository) Close() error {
+	return r.db.Close()
+}
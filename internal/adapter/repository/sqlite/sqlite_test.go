@@ -0,0 +1,36 @@
+package sqlite
+
+import (
+	"context"
+	"github.com/dontpanicw/SalesTracker/internal/adapter/repository/repotest"
+	"github.com/dontpanicw/SalesTracker/internal/port"
+	"github.com/dontpanicw/SalesTracker/pkg/migrations"
+	"path/filepath"
+	"testing"
+)
+
+// newTestRepository создает репозиторий над новой базой во временном каталоге
+// и применяет к ней миграции SQLite
+func newTestRepository(t *testing.T) *repository {
+	repo, err := New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	r := repo.(*repository)
+	t.Cleanup(func() { r.Close() })
+
+	migrator, err := migrations.NewSQLite(r.db)
+	if err != nil {
+		t.Fatalf("migrations.NewSQLite() error = %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("failed to apply migrations: %v", err)
+	}
+	return r
+}
+
+func TestRepository(t *testing.T) {
+	repotest.RunRepositorySuite(t, func(t *testing.T) port.Repository {
+		return newTestRepository(t)
+	})
+}
@@ -1,18 +1,26 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"github.com/dontpanicw/SalesTracker/config"
+	"github.com/dontpanicw/SalesTracker/internal/adapter/repository/memory"
 	"github.com/dontpanicw/SalesTracker/internal/adapter/repository/postgres"
+	"github.com/dontpanicw/SalesTracker/internal/adapter/repository/sqlite"
+	"github.com/dontpanicw/SalesTracker/internal/port"
 	"github.com/dontpanicw/SalesTracker/internal/usecases"
 	"github.com/dontpanicw/SalesTracker/pkg/migrations"
+	"io"
 	"log"
+	"os"
+	"time"
 
 	httpServer "github.com/dontpanicw/SalesTracker/internal/input/http"
 )
 
 type App struct {
 	config *config.Config
+	stdout io.Writer // вывод команд командной строки
 }
 
 func New() (*App, error) {
@@ -21,27 +29,123 @@ func New() (*App, error) {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	return &App{config: cfg}, nil
+	return &App{config: cfg, stdout: os.Stdout}, nil
 }
 
-func (a *App) Run() error {
-	// Запуск миграций
-	if err := migrations.Run(a.config.DatabaseDSN); err != nil {
-		return fmt.Errorf("failed to run migrations: %w", err)
-	}
+// Run применяет миграции и запускает HTTP сервер до отмены ctx
+func (a *App) Run(ctx context.Context) error {
+	return a.serve(ctx, true)
+}
 
-	// Инициализация репозитория
-	repo, err := postgres.New(a.config.DatabaseDSN)
+// serve запускает HTTP сервер и очистку корзины. После отмены ctx
+// сервер завершает текущие запросы, а соединения с базой закрываются.
+func (a *App) serve(ctx context.Context, migrate bool) error {
+	// Хранилище открывается до миграций: подключение к PostgreSQL
+	// повторяется, пока база не станет доступна
+	uc, closeRepo, err := a.openUseCases()
 	if err != nil {
-		return fmt.Errorf("failed to create repository: %w", err)
+		return err
 	}
+	defer closeRepo()
 
-	// Инициализация use cases
-	uc := usecases.New(repo)
+	// Запуск миграций; хранилищу в памяти они не нужны
+	if migrate {
+		switch a.config.DatabaseDriver {
+		case config.DriverPostgres:
+			err = migrations.Run(a.config.DatabaseDSN)
+		case config.DriverSQLite:
+			err = migrations.RunSQLite(a.config.DatabaseDSN)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to run migrations: %w", err)
+		}
+	}
+
+	// Очистка корзины в фоне; соединения с базой закрываются после ее остановки
+	purgeDone := make(chan struct{})
+	go func() {
+		defer close(purgeDone)
+		purgeTrash(ctx, uc, a.config.PurgeInterval)
+	}()
+	defer func() { <-purgeDone }()
 
 	// Запуск HTTP сервера
 	server := httpServer.NewServer(uc, a.config.ServerPort)
 
 	log.Printf("Starting server on port %s", a.config.ServerPort)
-	return server.Start()
+	if err := server.Start(ctx); err != nil {
+		return err
+	}
+	log.Printf("Server stopped")
+	return nil
+}
+
+// openRepository создает хранилище, выбранное в DB_DRIVER
+func (a *App) openRepository() (port.Repository, error) {
+	switch a.config.DatabaseDriver {
+	case config.DriverMemory:
+		log.Printf("Using in-memory storage, data will be lost on shutdown")
+		return memory.New(), nil
+	case config.DriverSQLite:
+		return sqlite.New(a.config.DatabaseDSN)
+	default:
+		return postgres.New(
+			a.config.DatabaseDSN,
+			postgres.WithPool(a.config.DBMaxOpenConns, a.config.DBMaxIdleConns, a.config.DBConnMaxLifetime),
+			postgres.WithQueryTimeout(a.config.DBQueryTimeout),
+			postgres.WithConnectRetry(a.config.DBConnectTimeout, a.config.DBConnectDelay),
+		)
+	}
+}
+
+// openUseCases подключается к базе и собирает use cases.
+// Возвращаемая функция закрывает соединения с базой.
+func (a *App) openUseCases() (port.UseCases, func() error, error) {
+	// Инициализация репозитория
+	repo, err := a.openRepository()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create repository: %w", err)
+	}
+	closeRepo := func() error {
+		if closer, ok := repo.(io.Closer); ok {
+			return closer.Close()
+		}
+		return nil
+	}
+
+	// Инициализация use cases
+	opts := []usecases.Option{
+		usecases.WithIdempotencyTTL(a.config.IdempotencyTTL),
+		usecases.WithIdempotencyLease(a.config.IdempotencyLease),
+		usecases.WithTrashRetention(a.config.TrashRetention),
+	}
+	// Изменения и их история сохраняются атомарно, если хранилище поддерживает транзакции
+	if txManager, ok := repo.(port.TxManager); ok {
+		opts = append(opts, usecases.WithTxManager(txManager))
+	}
+	if provider, ok := repo.(port.PoolStatsProvider); ok {
+		opts = append(opts, usecases.WithPoolStats(provider))
+	}
+	uc := usecases.New(repo, opts...)
+	return uc, closeRepo, nil
+}
+
+// purgeTrash сразу и затем раз в interval до отмены ctx окончательно удаляет
+// записи, которые пробыли в корзине дольше срока хранения
+func purgeTrash(ctx context.Context, uc port.UseCases, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		purged, err := uc.PurgeTrash(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Failed to purge trash: %v", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d items from trash", purged)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
 }
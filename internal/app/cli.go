@@ -0,0 +1,586 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/dontpanicw/SalesTracker/config"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"github.com/dontpanicw/SalesTracker/internal/input/itemfile"
+	"github.com/dontpanicw/SalesTracker/pkg/migrations"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// ErrUsage возвращается при неверных аргументах командной строки.
+// Сообщение об ошибке и справка к этому моменту уже выведены.
+var ErrUsage = errors.New("invalid usage")
+
+// maxSeedItems ограничивает количество записей, генерируемых командой seed за раз
+const maxSeedItems = 100000
+
+// cliActor — автор изменений, сделанных из командной строки, в истории записей
+const cliActor = "cli"
+
+// command — команда командной строки
+type command struct {
+	name    string
+	args    string // синтаксис аргументов для справки
+	summary string
+	run     func(a *App, ctx context.Context, fs *flag.FlagSet, args []string) error
+}
+
+var commands = []command{
+	{"serve", "[-migrate=false]", "start the HTTP server", (*App).runServe},
+	{"migrate", "[up | down [-steps N] | status]", "apply, roll back or list database migrations", (*App).runMigrate},
+	{"seed", "[-n N] [-days N] [-seed N] [-currency CODE]", "generate fake items for demos", (*App).runSeed},
+	{"import", "[flags] FILE", "import items from a CSV or JSON file, - for stdin", (*App).runImport},
+	{"export", "[flags] FILE", "export items to a CSV or JSON file, - for stdout", (*App).runExport},
+	{"report", "[-from DATE] [-to DATE] [-group-by FIELDS] [-format text|json]", "print analytics for a period", (*App).runReport},
+}
+
+// Execute выполняет команду из аргументов командной строки.
+// Без аргументов запускается сервер, как раньше.
+func (a *App) Execute(ctx context.Context, args []string) error {
+	name := "serve"
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+	switch name {
+	case "help", "-h", "-help", "--help":
+		a.printUsage(a.stdout)
+		return nil
+	}
+	for _, cmd := range commands {
+		if cmd.name == name {
+			err := cmd.run(a, domain.WithActor(ctx, cliActor), newFlagSet(cmd), args)
+			if errors.Is(err, flag.ErrHelp) {
+				return nil
+			}
+			return err
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+	a.printUsage(os.Stderr)
+	return ErrUsage
+}
+
+// printUsage выводит список команд
+func (a *App) printUsage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %s <command> [arguments]\n\nCommands:\n", programName())
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(tw, "  %s\t%s\n", cmd.name, cmd.summary)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "\nRun '%s <command> -h' for command flags. Without a command the server is started.\n", programName())
+}
+
+// programName возвращает имя исполняемого файла для справки
+func programName() string {
+	return filepath.Base(os.Args[0])
+}
+
+// newFlagSet создает набор флагов команды со справкой в общем формате
+func newFlagSet(cmd command) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s %s\n\n%s.\n\n", programName(), cmd.name, cmd.args, cmd.summary)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags разбирает флаги команды. Ошибки разбора flag уже выводит сам.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return ErrUsage
+	}
+	return nil
+}
+
+// usageError выводит сообщение и справку команды
+func usageError(fs *flag.FlagSet, format string, args ...interface{}) error {
+	fmt.Fprintf(fs.Output(), format+"\n\n", args...)
+	fs.Usage()
+	return ErrUsage
+}
+
+func (a *App) runServe(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	migrate := fs.Bool("migrate", true, "apply pending migrations before start")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usageError(fs, "unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	return a.serve(ctx, *migrate)
+}
+
+func (a *App) runMigrate(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	action := "up"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		action, args = args[0], args[1:]
+	}
+
+	steps := fs.Int("steps", 1, "number of migrations to roll back with down")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usageError(fs, "unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	if action != "up" && action != "down" && action != "status" {
+		return usageError(fs, "unknown migrate action %q: want up, down or status", action)
+	}
+	if *steps < 1 {
+		return usageError(fs, "-steps must be positive")
+	}
+
+	newMigrator := migrations.New
+	switch a.config.DatabaseDriver {
+	case config.DriverPostgres:
+	case config.DriverSQLite:
+		newMigrator = migrations.NewSQLite
+	default:
+		return fmt.Errorf("migrations are not supported for DB_DRIVER=%s", a.config.DatabaseDriver)
+	}
+
+	db, err := sql.Open(a.config.DatabaseDriver, a.config.DatabaseDSN)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+	if err := db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	migrator, err := newMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			fmt.Fprintf(a.stdout, "Applied migration %03d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Fprintln(a.stdout, "No pending migrations")
+		}
+		return err
+	case "down":
+		rolledBack, err := migrator.Down(ctx, *steps)
+		for _, migration := range rolledBack {
+			fmt.Fprintf(a.stdout, "Rolled back migration %03d_%s\n", migration.Version, migration.Name)
+		}
+		return err
+	}
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED AT")
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%03d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+	}
+	return tw.Flush()
+}
+
+func (a *App) runSeed(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	count := fs.Int("n", 100, "number of items to generate")
+	days := fs.Int("days", 90, "spread item dates over this many past days")
+	seed := fs.Uint64("seed", 0, "random seed for a reproducible data set, 0 for a random one")
+	currency := fs.String("currency", domain.DefaultCurrency, "currency of generated items")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *count < 1 || *count > maxSeedItems {
+		return usageError(fs, "-n must be between 1 and %d", maxSeedItems)
+	}
+	if *days < 1 {
+		return usageError(fs, "-days must be positive")
+	}
+	if *seed == 0 {
+		*seed = rand.Uint64()
+	}
+
+	items := seedItems(rand.New(rand.NewPCG(*seed, *seed)), *count, *days, *currency, time.Now())
+	rows := make([]domain.ImportRow, len(items))
+	for i, item := range items {
+		rows[i] = domain.ImportRow{Line: i + 1, Item: item}
+	}
+
+	uc, closeRepo, err := a.openUseCases()
+	if err != nil {
+		return err
+	}
+	defer closeRepo()
+
+	report, err := uc.ImportItems(ctx, rows, false)
+	if err != nil {
+		return err
+	}
+	if len(report.Errors) > 0 {
+		return fmt.Errorf("generated item is invalid: line %d: %s", report.Errors[0].Line, report.Errors[0].Error)
+	}
+	fmt.Fprintf(a.stdout, "Seeded %d items (seed %d)\n", report.Imported, *seed)
+	return nil
+}
+
+func (a *App) runImport(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	format := fs.String("format", "", "file format: csv or json, by default taken from the file extension")
+	delimiter := fs.String("delimiter", "", "CSV delimiter: a single character or tab, comma by default")
+	header := fs.Bool("header", true, "CSV file has a header row")
+	dateFormat := fs.String("date-format", "", "CSV date format in Go notation, RFC 3339 or 2006-01-02 by default")
+	dryRun := fs.Bool("dry-run", false, "only validate the file, do not save items")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usageError(fs, "exactly one FILE is required")
+	}
+	path := fs.Arg(0)
+	fileFormat, err := fileFormat(path, *format)
+	if err != nil {
+		return usageError(fs, "%v", err)
+	}
+	comma, err := itemfile.ParseDelimiter(*delimiter)
+	if err != nil {
+		return usageError(fs, "%v", err)
+	}
+
+	src := io.Reader(os.Stdin)
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		src = file
+	}
+
+	var rows []domain.ImportRow
+	if fileFormat == "json" {
+		rows, err = itemfile.ReadJSON(src)
+	} else {
+		rows, err = itemfile.ReadCSV(src, itemfile.CSVOptions{Delimiter: comma, HasHeader: *header, DateFormat: *dateFormat})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	uc, closeRepo, err := a.openUseCases()
+	if err != nil {
+		return err
+	}
+	defer closeRepo()
+
+	report, err := uc.ImportItems(ctx, rows, *dryRun)
+	if err != nil {
+		return err
+	}
+	printImportReport(a.stdout, report)
+	if len(report.Errors) > 0 {
+		return fmt.Errorf("%d of %d rows were rejected", len(report.Errors), report.Total)
+	}
+	return nil
+}
+
+// printImportReport выводит итоги импорта и ошибки по строкам
+func printImportReport(w io.Writer, report *domain.ImportReport) {
+	for _, rowErr := range report.Errors {
+		fmt.Fprintf(w, "line %d: %s\n", rowErr.Line, rowErr.Error)
+	}
+	if report.DryRun {
+		fmt.Fprintf(w, "Checked %d rows: %d valid, %d rejected (dry run, nothing saved)\n", report.Total, report.Valid, len(report.Errors))
+		return
+	}
+	fmt.Fprintf(w, "Imported %d of %d rows, %d rejected\n", report.Imported, report.Total, len(report.Errors))
+}
+
+func (a *App) runExport(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	format := fs.String("format", "", "file format: csv or json, by default taken from the file extension, csv for stdout")
+	delimiter := fs.String("delimiter", "", "CSV delimiter: a single character or tab, comma by default")
+	from := fs.String("from", "", "export items dated from DATE (RFC 3339 or 2006-01-02)")
+	to := fs.String("to", "", "export items dated up to DATE inclusive")
+	types := fs.String("type", "", "comma-separated item types")
+	var categories stringList
+	fs.Var(&categories, "category", "item category, can be repeated")
+	query := fs.String("q", "", "full-text search query")
+	sort := fs.String("sort", "", "sort order, as the sort parameter of GET /api/items")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usageError(fs, "exactly one FILE is required")
+	}
+	path := fs.Arg(0)
+	if path == "-" && *format == "" {
+		*format = "csv"
+	}
+	fileFormat, err := fileFormat(path, *format)
+	if err != nil {
+		return usageError(fs, "%v", err)
+	}
+	comma, err := itemfile.ParseDelimiter(*delimiter)
+	if err != nil {
+		return usageError(fs, "%v", err)
+	}
+
+	filter := domain.ItemFilter{Categories: categories, Query: *query}
+	if filter.From, err = parseDateFlag(*from, false); err != nil {
+		return usageError(fs, "invalid -from: %v", err)
+	}
+	if filter.To, err = parseDateFlag(*to, true); err != nil {
+		return usageError(fs, "invalid -to: %v", err)
+	}
+	for _, t := range strings.Split(*types, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			filter.Types = append(filter.Types, t)
+		}
+	}
+	if filter.Sort, err = domain.ParseItemSort(*sort); err != nil {
+		return usageError(fs, "%v", err)
+	}
+
+	uc, closeRepo, err := a.openUseCases()
+	if err != nil {
+		return err
+	}
+	defer closeRepo()
+
+	dst := a.stdout
+	if path != "-" {
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		dst = file
+	}
+
+	count := 0
+	if fileFormat == "json" {
+		jw := itemfile.NewJSONWriter(dst)
+		err = uc.ExportItems(ctx, filter, func(item *domain.Item) error {
+			count++
+			return jw.Write(item)
+		})
+		if err == nil {
+			err = jw.Close()
+		}
+	} else {
+		cw := itemfile.NewCSVWriter(dst, comma)
+		err = uc.ExportItems(ctx, filter, func(item *domain.Item) error {
+			count++
+			return cw.Write(item)
+		})
+		if err == nil {
+			err = cw.Close()
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("export failed after %d items: %w", count, err)
+	}
+	if path != "-" {
+		fmt.Fprintf(a.stdout, "Exported %d items to %s\n", count, path)
+	}
+	return nil
+}
+
+func (a *App) runReport(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	from := fs.String("from", "", "period start (RFC 3339 or 2006-01-02), 30 days before -to by default")
+	to := fs.String("to", "", "period end inclusive, now by default")
+	currency := fs.String("currency", "", "report currency, "+domain.DefaultCurrency+" by default")
+	groupBy := fs.String("group-by", "", "group by fields: category, type and one of day, week, month, quarter, year")
+	percentiles := fs.String("percentiles", "", "comma-separated extra percentiles, e.g. 0.25,0.75")
+	format := fs.String("format", "text", "output format: text or json")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usageError(fs, "unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	if *format != "text" && *format != "json" {
+		return usageError(fs, "unknown format %q: want text or json", *format)
+	}
+
+	query := domain.AnalyticsQuery{To: time.Now(), Currency: *currency}
+	end, err := parseDateFlag(*to, true)
+	if err != nil {
+		return usageError(fs, "invalid -to: %v", err)
+	}
+	if end != nil {
+		query.To = *end
+	}
+	query.From = query.To.AddDate(0, 0, -30)
+	start, err := parseDateFlag(*from, false)
+	if err != nil {
+		return usageError(fs, "invalid -from: %v", err)
+	}
+	if start != nil {
+		query.From = *start
+	}
+	if query.Percentiles, err = domain.ParsePercentiles(*percentiles); err != nil {
+		return usageError(fs, "%v", err)
+	}
+	if *groupBy != "" {
+		if query.GroupBy, err = domain.ParseGroupBy(*groupBy); err != nil {
+			return usageError(fs, "%v", err)
+		}
+	}
+
+	uc, closeRepo, err := a.openUseCases()
+	if err != nil {
+		return err
+	}
+	defer closeRepo()
+
+	if query.GroupBy != nil {
+		groups, err := uc.GetGroupedAnalytics(ctx, query)
+		if err != nil {
+			return err
+		}
+		if *format == "json" {
+			return writeJSON(a.stdout, groups)
+		}
+		return printGroups(a.stdout, query, groups)
+	}
+
+	report, err := uc.GetAnalytics(ctx, query)
+	if err != nil {
+		return err
+	}
+	if *format == "json" {
+		return writeJSON(a.stdout, report)
+	}
+	return printReport(a.stdout, query, report)
+}
+
+// printReport выводит аналитику доходов и расходов таблицей
+func printReport(w io.Writer, query domain.AnalyticsQuery, report *domain.AnalyticsReport) error {
+	fmt.Fprintf(w, "Period %s - %s, currency %s\n\n",
+		query.From.Format(time.RFC3339), query.To.Format(time.RFC3339), report.Currency)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	in, out := report.Income, report.Expense
+	fmt.Fprintln(tw, "\tINCOME\tEXPENSE\t")
+	fmt.Fprintf(tw, "count\t%d\t%d\t\n", in.Count, out.Count)
+	rows := []struct {
+		name    string
+		in, out domain.Money
+	}{
+		{"sum", in.Sum, out.Sum},
+		{"avg", in.Avg, out.Avg},
+		{"median", in.Median, out.Median},
+		{"p90", in.Percentile, out.Percentile},
+		{"min", in.Min, out.Min},
+		{"max", in.Max, out.Max},
+		{"stddev", in.StdDev, out.StdDev},
+	}
+	for _, row := range rows {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t\n", row.name, row.in, row.out)
+	}
+	for _, p := range query.Percentiles {
+		key := domain.PercentileKey(p)
+		fmt.Fprintf(tw, "p%s\t%s\t%s\t\n", key, in.Percentiles[key], out.Percentiles[key])
+	}
+	fmt.Fprintf(tw, "balance\t%s\t\t\n", report.Balance)
+	return tw.Flush()
+}
+
+// printGroups выводит сгруппированную аналитику таблицей: колонки группировки,
+// доходы, расходы и их разница, затем статистика
+func printGroups(w io.Writer, query domain.AnalyticsQuery, groups []*domain.AnalyticsGroup) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\tINCOME\tEXPENSE\tNET\tCOUNT\tSUM\tAVG\tMEDIAN\tMIN\tMAX\n", strings.ToUpper(strings.Join(query.GroupBy, "\t")))
+	for _, group := range groups {
+		for _, field := range query.GroupBy {
+			value := ""
+			switch {
+			case field == domain.GroupByCategory && group.Category != nil:
+				value = *group.Category
+			case field == domain.GroupByType && group.Type != nil:
+				value = *group.Type
+			case domain.IsPeriodGroup(field) && group.Period != nil:
+				value = group.Period.Format("2006-01-02")
+			}
+			fmt.Fprintf(tw, "%s\t", value)
+		}
+		a := group.Analytics
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\n", group.Income, group.Expense, group.Net, a.Count, a.Sum, a.Avg, a.Median, a.Min, a.Max)
+	}
+	return tw.Flush()
+}
+
+// writeJSON выводит значение в JSON с отступами
+func writeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// fileFormat возвращает формат файла: явно заданный или по расширению
+func fileFormat(path, format string) (string, error) {
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+		if format != "csv" && format != "json" {
+			return "", fmt.Errorf("cannot infer the format of %s, use -format csv or -format json", path)
+		}
+	}
+	if format != "csv" && format != "json" {
+		return "", fmt.Errorf("unknown format %q: want csv or json", format)
+	}
+	return format, nil
+}
+
+// parseDateFlag разбирает дату в RFC 3339 или 2006-01-02. Пустая строка — nil.
+// Для конца периода дата без времени означает последнюю микросекунду этого дня:
+// хранилища сохраняют время с точностью до микросекунды.
+func parseDateFlag(value string, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fmt.Errorf("want RFC 3339 or 2006-01-02, got %q", value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Microsecond)
+	}
+	return &t, nil
+}
+
+// stringList — флаг, который можно указать несколько раз
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
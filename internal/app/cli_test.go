@@ -0,0 +1,209 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/dontpanicw/SalesTracker/config"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApp_Execute_Usage(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr error
+		wantOut string
+	}{
+		{name: "help", args: []string{"help"}, wantOut: "Commands:"},
+		{name: "command help", args: []string{"report", "-h"}},
+		{name: "unknown command", args: []string{"bogus"}, wantErr: ErrUsage},
+		{name: "unknown flag", args: []string{"seed", "-bogus"}, wantErr: ErrUsage},
+		{name: "missing file", args: []string{"import"}, wantErr: ErrUsage},
+		{name: "unknown file format", args: []string{"export", "items.txt"}, wantErr: ErrUsage},
+		{name: "unknown migrate action", args: []string{"migrate", "sideways"}, wantErr: ErrUsage},
+		{name: "invalid date", args: []string{"report", "-from", "yesterday"}, wantErr: ErrUsage},
+		{name: "invalid group by", args: []string{"report", "-group-by", "color"}, wantErr: ErrUsage},
+		{name: "invalid seed count", args: []string{"seed", "-n", "0"}, wantErr: ErrUsage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			a := &App{stdout: &out}
+
+			err := a.Execute(context.Background(), tt.args)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Execute() error = %v, want %v", err, tt.wantErr)
+			}
+			if !strings.Contains(out.String(), tt.wantOut) {
+				t.Errorf("Execute() output = %q, want %q", out.String(), tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestApp_Execute_Memory(t *testing.T) {
+	cfg := &config.Config{
+		DatabaseDriver: config.DriverMemory,
+		IdempotencyTTL: time.Hour,
+		TrashRetention: time.Hour,
+		PurgeInterval:  time.Hour,
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+		wantOut string
+	}{
+		{name: "seed", args: []string{"seed", "-n", "20", "-seed", "1"}, wantOut: "Seeded 20 items (seed 1)"},
+		{name: "report", args: []string{"report", "-format", "json"}, wantOut: `"currency": "RUB"`},
+		{name: "migrate", args: []string{"migrate", "status"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			a := &App{config: cfg, stdout: &out}
+
+			err := a.Execute(context.Background(), tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !strings.Contains(out.String(), tt.wantOut) {
+				t.Errorf("Execute() output = %q, want %q", out.String(), tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestFileFormat(t *testing.T) {
+	tests := []struct {
+		path    string
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{path: "items.csv", want: "csv"},
+		{path: "ITEMS.JSON", want: "json"},
+		{path: "items.txt", format: "json", want: "json"},
+		{path: "items.txt", wantErr: true},
+		{path: "items.csv", format: "xml", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := fileFormat(tt.path, tt.format)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("fileFormat(%q, %q) = %q, %v", tt.path, tt.format, got, err)
+		}
+	}
+}
+
+func TestParseDateFlag(t *testing.T) {
+	tests := []struct {
+		value    string
+		endOfDay bool
+		want     time.Time
+		wantErr  bool
+	}{
+		{value: "2024-01-15", want: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{value: "2024-01-15", endOfDay: true, want: time.Date(2024, 1, 15, 23, 59, 59, 999999000, time.UTC)},
+		{value: "2024-01-15T10:00:00Z", endOfDay: true, want: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)},
+		{value: "15.01.2024", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseDateFlag(tt.value, tt.endOfDay)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("parseDateFlag(%q) error = %v", tt.value, err)
+		}
+		if err == nil && !got.Equal(tt.want) {
+			t.Errorf("parseDateFlag(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	// Запись в последнюю секунду дня входит в период, запись следующего дня — нет
+	to, err := parseDateFlag("2024-01-15", true)
+	if err != nil {
+		t.Fatalf("parseDateFlag() error = %v", err)
+	}
+	if last := time.Date(2024, 1, 15, 23, 59, 59, 500000000, time.UTC); to.Before(last) {
+		t.Errorf("parseDateFlag() end of day %v excludes %v", to, last)
+	}
+	if next := time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC); !to.Before(next) {
+		t.Errorf("parseDateFlag() end of day %v includes %v", to, next)
+	}
+
+	if got, err := parseDateFlag("", false); got != nil || err != nil {
+		t.Errorf("parseDateFlag(\"\") = %v, %v, want nil", got, err)
+	}
+}
+
+func TestApp_Execute_SQLite(t *testing.T) {
+	cfg := &config.Config{
+		DatabaseDriver: config.DriverSQLite,
+		DatabaseDSN:    filepath.Join(t.TempDir(), "salestracker.db"),
+		IdempotencyTTL: time.Hour,
+		TrashRetention: time.Hour,
+		PurgeInterval:  time.Hour,
+	}
+
+	// Шаги выполняются по порядку над одним файлом базы
+	tests := []struct {
+		name    string
+		args    []string
+		wantOut string
+	}{
+		{name: "migrate up", args: []string{"migrate", "up"}, wantOut: "Applied migration 001_create_tables"},
+		{name: "migrate status", args: []string{"migrate", "status"}, wantOut: "create_tables  20"},
+		{name: "seed", args: []string{"seed", "-n", "20", "-seed", "1"}, wantOut: "Seeded 20 items (seed 1)"},
+		{name: "report", args: []string{"report", "-format", "json"}, wantOut: `"currency": "RUB"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			a := &App{config: cfg, stdout: &out}
+
+			if err := a.Execute(context.Background(), tt.args); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if !strings.Contains(out.String(), tt.wantOut) {
+				t.Errorf("Execute() output = %q, want %q", out.String(), tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestApp_Execute_ServeShutdown(t *testing.T) {
+	cfg := &config.Config{
+		DatabaseDriver: config.DriverMemory,
+		ServerPort:     "0",
+		IdempotencyTTL: time.Hour,
+		TrashRetention: time.Hour,
+		PurgeInterval:  time.Hour,
+	}
+	a := &App{config: cfg, stdout: &bytes.Buffer{}}
+
+	// Отмена контекста, как при SIGINT или SIGTERM, останавливает сервер
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- a.Execute(ctx, []string{"serve"})
+	}()
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Execute() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Execute() did not stop after context cancellation")
+	}
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"math/rand/v2"
+	"sort"
+	"time"
+)
+
+// seedCategory описывает категорию демонстрационных записей
+type seedCategory struct {
+	itemType     string
+	name         string
+	min, max     int64 // диапазон суммы в целых единицах валюты
+	weight       int   // относительная частота записей категории
+	descriptions []string
+}
+
+// seedCategories — типичные доходы и расходы частного лица: частые мелкие
+// траты и редкие крупные поступления
+var seedCategories = []seedCategory{
+	{domain.TypeIncome, "Salary", 90000, 160000, 3, []string{"Monthly salary", "Salary advance", "Salary"}},
+	{domain.TypeIncome, "Freelance", 5000, 45000, 2, []string{"Website redesign", "Logo for a client", "Consulting call", "Code review"}},
+	{domain.TypeIncome, "Investments", 500, 15000, 1, []string{"Dividends", "Bond coupon", "Deposit interest"}},
+	{domain.TypeIncome, "Gifts", 1000, 10000, 1, []string{"Birthday gift", "Cashback", ""}},
+	{domain.TypeExpense, "Groceries", 300, 6000, 20, []string{"Supermarket", "Farmers market", "Bakery", "Grocery delivery", ""}},
+	{domain.TypeExpense, "Cafe", 250, 3500, 10, []string{"Coffee", "Lunch", "Dinner with friends", "Pizza", ""}},
+	{domain.TypeExpense, "Transport", 60, 1500, 12, []string{"Metro", "Taxi", "Bus", "Fuel", "Parking"}},
+	{domain.TypeExpense, "Rent", 35000, 55000, 2, []string{"Apartment rent"}},
+	{domain.TypeExpense, "Utilities", 2500, 8000, 3, []string{"Electricity", "Water", "Internet", "Mobile plan"}},
+	{domain.TypeExpense, "Entertainment", 400, 5000, 5, []string{"Cinema", "Concert tickets", "Streaming subscription", "Board games"}},
+	{domain.TypeExpense, "Health", 500, 9000, 3, []string{"Pharmacy", "Dentist", "Gym membership", "Doctor visit"}},
+	{domain.TypeExpense, "Shopping", 800, 20000, 5, []string{"Clothes", "Shoes", "Electronics", "Home goods", "Books"}},
+}
+
+// seedItems генерирует count записей с датами за последние days дней до now.
+// Записи упорядочены по дате; при одинаковом rng результат одинаков.
+func seedItems(rng *rand.Rand, count, days int, currency string, now time.Time) []*domain.Item {
+	totalWeight := 0
+	for _, category := range seedCategories {
+		totalWeight += category.weight
+	}
+
+	period := time.Duration(days) * 24 * time.Hour
+	items := make([]*domain.Item, count)
+	for i := range items {
+		category := pickCategory(rng.IntN(totalWeight))
+
+		// Крупные суммы обычно круглые, мелкие — с копейками
+		units := category.min + rng.Int64N(category.max-category.min+1)
+		var cents int64
+		if category.max >= 10000 {
+			units -= units % 100
+		} else {
+			cents = rng.Int64N(100)
+		}
+
+		items[i] = &domain.Item{
+			Type:        category.itemType,
+			Amount:      domain.NewMoney(units, cents),
+			Currency:    currency,
+			Category:    category.name,
+			Description: category.descriptions[rng.IntN(len(category.descriptions))],
+			Date:        now.Add(-time.Duration(rng.Int64N(int64(period)))).Truncate(time.Minute),
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool { return items[i].Date.Before(items[j].Date) })
+	return items
+}
+
+// pickCategory возвращает категорию по числу от 0 до суммы весов
+func pickCategory(n int) seedCategory {
+	for _, category := range seedCategories {
+		if n < category.weight {
+			return category
+		}
+		n -= category.weight
+	}
+	return seedCategories[len(seedCategories)-1]
+}
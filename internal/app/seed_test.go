@@ -0,0 +1,40 @@
+package app
+
+import (
+	"math/rand/v2"
+	"testing"
+	"time"
+)
+
+func TestSeedItems(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	items := seedItems(rand.New(rand.NewPCG(1, 1)), 500, 30, "RUB", now)
+	if len(items) != 500 {
+		t.Fatalf("seedItems() returned %d items, want 500", len(items))
+	}
+
+	types := make(map[string]int)
+	for i, item := range items {
+		item.Normalize()
+		if err := item.Validate(); err != nil {
+			t.Fatalf("seedItems() item %+v is invalid: %v", item, err)
+		}
+		if item.Date.After(now) || item.Date.Before(now.AddDate(0, 0, -30)) {
+			t.Errorf("seedItems() date %v is outside the period", item.Date)
+		}
+		if i > 0 && item.Date.Before(items[i-1].Date) {
+			t.Fatalf("seedItems() items are not sorted by date")
+		}
+		types[item.Type]++
+	}
+	if types["income"] == 0 || types["expense"] <= types["income"] {
+		t.Errorf("seedItems() types = %v, want mostly expenses", types)
+	}
+
+	again := seedItems(rand.New(rand.NewPCG(1, 1)), 500, 30, "RUB", now)
+	for i := range items {
+		if *items[i] != *again[i] {
+			t.Fatalf("seedItems() with the same seed differs at %d: %+v != %+v", i, items[i], again[i])
+		}
+	}
+}
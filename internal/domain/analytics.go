@@ -0,0 +1,217 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxPercentiles ограничивает количество перцентилей в одном запросе
+const MaxPercentiles = 20
+
+// AnalyticsQuery описывает параметры запроса аналитики
+type AnalyticsQuery struct {
+	From        time.Time
+	To          time.Time
+	GroupBy     []string  // используется только в группированной аналитике
+	Percentiles []float64 // дополнительные перцентили, значения от 0 до 1
+	Currency    string    // валюта отчета, суммы пересчитываются по курсу на дату записи
+}
+
+// TimeSeriesQuery описывает параметры запроса временного ряда
+type TimeSeriesQuery struct {
+	From     time.Time
+	To       time.Time
+	Interval string
+	Location *time.Location // часовой пояс границ интервалов
+	Currency string
+}
+
+// Поля группировки аналитики
+const (
+	GroupByCategory = "category"
+	GroupByType     = "type"
+	GroupByDay      = "day"
+	GroupByWeek     = "week"
+	GroupByMonth    = "month"
+	GroupByQuarter  = "quarter"
+	GroupByYear     = "year"
+)
+
+// Интервалы временного ряда
+const (
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+)
+
+// periodGroups перечисляет группировки по времени, из них допускается только одна
+var periodGroups = map[string]bool{
+	GroupByDay:     true,
+	GroupByWeek:    true,
+	GroupByMonth:   true,
+	GroupByQuarter: true,
+	GroupByYear:    true,
+}
+
+// AnalyticsGroup представляет аналитику по одной группе записей.
+// Заполнены только те поля группировки, по которым выполнялся запрос.
+// Если группировки по типу нет, в группу попадают и доходы, и расходы:
+// их суммы возвращаются отдельно в Income и Expense, а статистика — по всем записям группы.
+type AnalyticsGroup struct {
+	Category *string    `json:"category,omitempty"`
+	Type     *string    `json:"type,omitempty"`
+	Period   *time.Time `json:"period,omitempty"` // начало периода
+	Income   Money      `json:"income"`
+	Expense  Money      `json:"expense"`
+	Net      Money      `json:"net"` // доходы минус расходы
+	Analytics
+}
+
+// TimeSeriesPoint представляет один интервал временного ряда.
+// Интервалы без записей возвращаются с нулевыми значениями.
+type TimeSeriesPoint struct {
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Income  Money     `json:"income"`
+	Expense Money     `json:"expense"`
+	Net     Money     `json:"net"` // доходы минус расходы
+}
+
+// IsPeriodGroup сообщает, является ли поле группировкой по времени
+func IsPeriodGroup(field string) bool {
+	return periodGroups[field]
+}
+
+// ParseGroupBy разбирает список полей группировки вида "category,month"
+func ParseGroupBy(value string) ([]string, error) {
+	var fields []string
+	seen := make(map[string]bool)
+	hasPeriod := false
+
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(strings.ToLower(field))
+		if field == "" {
+			continue
+		}
+		if field != GroupByCategory && field != GroupByType && !IsPeriodGroup(field) {
+			return nil, NewValidationError("group_by", fmt.Sprintf("unknown group_by field '%s'", field))
+		}
+		if seen[field] {
+			return nil, NewValidationError("group_by", fmt.Sprintf("duplicate group_by field '%s'", field))
+		}
+		if IsPeriodGroup(field) {
+			if hasPeriod {
+				return nil, NewValidationError("group_by", "only one of day, week, month, quarter, year can be used in group_by")
+			}
+			hasPeriod = true
+		}
+		seen[field] = true
+		fields = append(fields, field)
+	}
+
+	if len(fields) == 0 {
+		return nil, NewValidationError("group_by", "group_by is required")
+	}
+	return fields, nil
+}
+
+// MaxTimeSeriesPoints ограничивает число интервалов временного ряда, чтобы
+// запрос за большой период не строил ответ неограниченного размера
+const MaxTimeSeriesPoints = 1000
+
+// PointCount возвращает число интервалов ряда от From до To. Границы интервалов
+// считаются в часовом поясе Location, nil — UTC. Интервал должен быть проверен ValidateInterval.
+func (q TimeSeriesQuery) PointCount() int {
+	loc := q.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	// Календарные даты переносятся в UTC, чтобы переходы на летнее время не сдвигали счет суток
+	date := func(t time.Time) time.Time {
+		year, month, day := t.In(loc).Date()
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	from, to := date(q.From), date(q.To)
+	if to.Before(from) {
+		return 0
+	}
+
+	// Разница в сутках через Unix-время: time.Duration не вмещает больше 292 лет
+	const day = 24 * 60 * 60
+	switch q.Interval {
+	case IntervalMonth:
+		return (to.Year()-from.Year())*12 + int(to.Month()-from.Month()) + 1
+	case IntervalWeek:
+		// Недели начинаются с понедельника
+		monday := func(t time.Time) time.Time {
+			return t.AddDate(0, 0, -((int(t.Weekday()) + 6) % 7))
+		}
+		return int((monday(to).Unix()-monday(from).Unix())/(7*day)) + 1
+	default:
+		return int((to.Unix()-from.Unix())/day) + 1
+	}
+}
+
+// ValidateInterval проверяет интервал временного ряда
+func ValidateInterval(interval string) error {
+	switch interval {
+	case IntervalDay, IntervalWeek, IntervalMonth:
+		return nil
+	}
+	return NewValidationError("interval", "interval must be 'day', 'week' or 'month'")
+}
+
+// ParsePercentiles разбирает список перцентилей вида "0.25,0.5,0.99"
+func ParsePercentiles(value string) ([]float64, error) {
+	var percentiles []float64
+	seen := make(map[float64]bool)
+
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		p, err := strconv.ParseFloat(part, 64)
+		if err != nil || math.IsNaN(p) || math.IsInf(p, 0) {
+			return nil, NewValidationError("percentiles", fmt.Sprintf("invalid percentile '%s'", part))
+		}
+		if p < 0 || p > 1 {
+			return nil, NewValidationError("percentiles", fmt.Sprintf("percentile '%s' must be between 0 and 1", part))
+		}
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		percentiles = append(percentiles, p)
+	}
+
+	if len(percentiles) > MaxPercentiles {
+		return nil, NewValidationError("percentiles", fmt.Sprintf("at most %d percentiles can be requested", MaxPercentiles))
+	}
+	return percentiles, nil
+}
+
+// PercentileKey возвращает ключ перцентиля в ответе, например "0.95"
+func PercentileKey(p float64) string {
+	return strconv.FormatFloat(p, 'f', -1, 64)
+}
+
+// NewPercentileMap сопоставляет запрошенные перцентили с вычисленными значениями,
+// округленными до копеек
+func NewPercentileMap(percentiles, values []float64) map[string]Money {
+	if len(percentiles) == 0 {
+		return nil
+	}
+	result := make(map[string]Money, len(percentiles))
+	for i, p := range percentiles {
+		var value Money
+		if i < len(values) {
+			value = MoneyFromFloat(values[i])
+		}
+		result[PercentileKey(p)] = value
+	}
+	return result
+}
@@ -0,0 +1,177 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseGroupBy(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "single field",
+			value: "category",
+			want:  []string{"category"},
+		},
+		{
+			name:  "combination with period",
+			value: "type, Month,category",
+			want:  []string{"type", "month", "category"},
+		},
+		{
+			name:    "empty value",
+			value:   " , ",
+			wantErr: true,
+		},
+		{
+			name:    "unknown field",
+			value:   "amount",
+			wantErr: true,
+		},
+		{
+			name:    "duplicate field",
+			value:   "type,type",
+			wantErr: true,
+		},
+		{
+			name:    "two periods",
+			value:   "day,month",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseGroupBy(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseGroupBy() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseGroupBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateInterval(t *testing.T) {
+	for _, interval := range []string{"day", "week", "month"} {
+		if err := ValidateInterval(interval); err != nil {
+			t.Errorf("ValidateInterval(%q) error = %v", interval, err)
+		}
+	}
+	for _, interval := range []string{"", "hour", "quarter", "Day"} {
+		if err := ValidateInterval(interval); err == nil {
+			t.Errorf("ValidateInterval(%q) expected error", interval)
+		}
+	}
+}
+
+func TestParsePercentiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    []float64
+		wantErr bool
+	}{
+		{
+			name:  "several percentiles",
+			value: "0.25, 0.5,0.75,0.95,0.99",
+			want:  []float64{0.25, 0.5, 0.75, 0.95, 0.99},
+		},
+		{
+			name:  "empty value",
+			value: "",
+			want:  nil,
+		},
+		{
+			name:  "duplicates are ignored",
+			value: "0.5,0.5,1",
+			want:  []float64{0.5, 1},
+		},
+		{
+			name:    "out of range",
+			value:   "95",
+			wantErr: true,
+		},
+		{
+			name:    "not a number",
+			value:   "p95",
+			wantErr: true,
+		},
+		{
+			name:    "NaN",
+			value:   "NaN",
+			wantErr: true,
+		},
+		{
+			name:    "infinity",
+			value:   "0.5,-Inf",
+			wantErr: true,
+		},
+		{
+			name:    "too many",
+			value:   "0,0.01,0.02,0.03,0.04,0.05,0.06,0.07,0.08,0.09,0.1,0.11,0.12,0.13,0.14,0.15,0.16,0.17,0.18,0.19,0.2",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePercentiles(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParsePercentiles() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParsePercentiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPercentileMap(t *testing.T) {
+	got := NewPercentileMap([]float64{0.25, 0.99}, []float64{100, 990.004})
+	want := map[string]Money{"0.25": NewMoney(100, 0), "0.99": NewMoney(990, 0)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewPercentileMap() = %v, want %v", got, want)
+	}
+
+	if got := NewPercentileMap(nil, nil); got != nil {
+		t.Errorf("NewPercentileMap() without percentiles = %v, want nil", got)
+	}
+}
+
+func TestTimeSeriesQuery_PointCount(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	tests := []struct {
+		name     string
+		from, to time.Time
+		interval string
+		loc      *time.Location
+		want     int
+	}{
+		{name: "same day", from: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), to: time.Date(2024, 3, 1, 20, 0, 0, 0, time.UTC), interval: IntervalDay, want: 1},
+		{name: "days", from: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), to: time.Date(2024, 3, 3, 23, 59, 59, 0, time.UTC), interval: IntervalDay, want: 3},
+		{name: "days in time zone", from: time.Date(2024, 3, 1, 22, 0, 0, 0, time.UTC), to: time.Date(2024, 3, 2, 1, 0, 0, 0, time.UTC), interval: IntervalDay, loc: moscow, want: 1},
+		// 2024-03-03 — воскресенье, 2024-03-04 — понедельник
+		{name: "weeks", from: time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC), to: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), interval: IntervalWeek, want: 2},
+		{name: "months", from: time.Date(2023, 11, 30, 0, 0, 0, 0, time.UTC), to: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), interval: IntervalMonth, want: 4},
+		{name: "whole calendar", from: time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC), to: time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC), interval: IntervalDay, want: 3651695},
+		{name: "reversed", from: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), to: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), interval: IntervalDay, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := TimeSeriesQuery{From: tt.from, To: tt.to, Interval: tt.interval, Location: tt.loc}
+			if got := q.PointCount(); got != tt.want {
+				t.Errorf("PointCount() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
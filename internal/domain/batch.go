@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Операции пакетного изменения записей
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+)
+
+// MaxBatchOperations — максимальное число операций в одном пакете
+const MaxBatchOperations = 10000
+
+// Статусы операций в отчете
+const (
+	BatchStatusOK      = "ok"
+	BatchStatusFailed  = "failed"
+	BatchStatusSkipped = "skipped" // не выполнена, потому что пакет отменен из-за других ошибок
+)
+
+// BatchOperation — одна операция пакета
+type BatchOperation struct {
+	Op      string `json:"op"`                // create, update или delete
+	ID      int64  `json:"id,omitempty"`      // для update и delete
+	Version int64  `json:"version,omitempty"` // ожидаемая версия для update и delete, 0 — без проверки
+	Item    *Item  `json:"item,omitempty"`    // для create и update
+}
+
+// ItemRef ссылается на запись с ожидаемой версией
+type ItemRef struct {
+	ID      int64
+	Version int64 // 0 — без проверки версии
+}
+
+// ItemBatch — изменения, которые хранилище применяет в одной транзакции
+type ItemBatch struct {
+	Create []*Item
+	Update []*Item // Version — ожидаемая версия, после применения — новая
+	Delete []ItemRef
+}
+
+// BatchResult — результат одной операции пакета
+type BatchResult struct {
+	Index  int          `json:"index"` // номер операции в запросе, начиная с 0
+	Op     string       `json:"op"`
+	Status string       `json:"status"`
+	Item   *Item        `json:"item,omitempty"` // созданная или обновленная запись
+	Error  string       `json:"error,omitempty"`
+	Fields []FieldError `json:"fields,omitempty"`
+	Err    error        `json:"-"`
+}
+
+// BatchReport представляет результат выполнения пакета
+type BatchReport struct {
+	Partial   bool          `json:"partial"`
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"`
+	Results   []BatchResult `json:"results"`
+}
+
+// Fail отмечает операцию с номером index как неуспешную
+func (r *BatchReport) Fail(index int, err error) {
+	result := &r.Results[index]
+	result.Status = BatchStatusFailed
+	result.Item = nil
+	result.Err = err
+	result.Error = err.Error()
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		result.Fields = verr.Errors
+	}
+}
+
+// FirstError возвращает ошибку первой неуспешной операции или nil
+func (r *BatchReport) FirstError() error {
+	for _, result := range r.Results {
+		if result.Err != nil {
+			return result.Err
+		}
+	}
+	return nil
+}
+
+// Finish подсчитывает итоги. Если пакет выполняется целиком и есть ошибки,
+// остальные операции отмечаются как пропущенные.
+func (r *BatchReport) Finish() {
+	r.Succeeded, r.Failed = 0, 0
+	for _, result := range r.Results {
+		if result.Status == BatchStatusFailed {
+			r.Failed++
+		}
+	}
+	for i := range r.Results {
+		result := &r.Results[i]
+		switch {
+		case result.Status == BatchStatusFailed:
+		case !r.Partial && r.Failed > 0:
+			result.Status = BatchStatusSkipped
+			result.Item = nil
+		default:
+			result.Status = BatchStatusOK
+			r.Succeeded++
+		}
+	}
+}
+
+// Validate проверяет операцию без записи: тип, идентификатор и данные записи
+func (o *BatchOperation) Validate() error {
+	if o.Version < 0 {
+		return NewValidationError("version", "version must not be negative")
+	}
+	switch o.Op {
+	case BatchCreate:
+		if o.Item == nil {
+			return NewValidationError("item", "item is required for create")
+		}
+	case BatchUpdate:
+		if o.ID <= 0 {
+			return NewValidationError("id", "id is required for update")
+		}
+		if o.Item == nil {
+			return NewValidationError("item", "item is required for update")
+		}
+	case BatchDelete:
+		if o.ID <= 0 {
+			return NewValidationError("id", "id is required for delete")
+		}
+		return nil
+	default:
+		return NewValidationError("op", fmt.Sprintf("op must be one of %s, %s, %s", BatchCreate, BatchUpdate, BatchDelete))
+	}
+	o.Item.Normalize()
+	return o.Item.Validate()
+}
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultCurrency — валюта записей и отчетов, если она не указана явно
+const DefaultCurrency = "RUB"
+
+// iso4217 содержит действующие коды валют ISO 4217
+var iso4217 = makeCurrencySet(`
+	AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND BOB BRL
+	BSD BTN BWP BYN BZD CAD CDF CHF CLP CNY COP CRC CUP CVE CZK DJF DKK DOP DZD EGP
+	ERN ETB EUR FJD FKP GBP GEL GHS GIP GMD GNF GTQ GYD HKD HNL HTG HUF IDR ILS INR
+	IQD IRR ISK JMD JOD JPY KES KGS KHR KMF KPW KRW KWD KYD KZT LAK LBP LKR LRD LSL
+	LYD MAD MDL MGA MKD MMK MNT MOP MRU MUR MVR MWK MXN MYR MZN NAD NGN NIO NOK NPR
+	NZD OMR PAB PEN PGK PHP PKR PLN PYG QAR RON RSD RUB RWF SAR SBD SCR SDG SEK SGD
+	SHP SLE SOS SRD SSP STN SVC SYP SZL THB TJS TMT TND TOP TRY TTD TWD TZS UAH UGX
+	USD UYU UZS VES VND VUV WST XAF XCD XCG XOF XPF YER ZAR ZMW ZWG
+`)
+
+// ExchangeRate представляет курс обмена валюты, действующий с даты ValidFrom
+// до появления следующего курса по той же паре
+type ExchangeRate struct {
+	ID        int64     `json:"id"`
+	From      string    `json:"from"` // базовая валюта
+	To        string    `json:"to"`   // валюта котировки
+	Rate      Rate      `json:"rate"` // сколько единиц To стоит одна единица From
+	ValidFrom time.Time `json:"valid_from"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IsValidCurrency проверяет, что код является действующим кодом ISO 4217
+func IsValidCurrency(code string) bool {
+	return iso4217[code]
+}
+
+// NormalizeCurrency приводит код валюты к верхнему регистру
+func NormalizeCurrency(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Validate проверяет корректность курса
+func (r *ExchangeRate) Validate() error {
+	if !IsValidCurrency(r.From) {
+		return NewValidationError("from", "from and to must be ISO 4217 currency codes")
+	}
+	if !IsValidCurrency(r.To) {
+		return NewValidationError("to", "from and to must be ISO 4217 currency codes")
+	}
+	if r.From == r.To {
+		return NewValidationError("to", "from and to currencies must differ")
+	}
+	if r.Rate <= 0 {
+		return NewValidationError("rate", "rate must be positive")
+	}
+	if r.ValidFrom.IsZero() {
+		return NewValidationError("valid_from", "valid_from is required")
+	}
+	return nil
+}
+
+func makeCurrencySet(codes string) map[string]bool {
+	set := make(map[string]bool)
+	for _, code := range strings.Fields(codes) {
+		set[code] = true
+	}
+	return set
+}
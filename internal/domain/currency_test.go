@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsValidCurrency(t *testing.T) {
+	for _, code := range []string{"RUB", "USD", "EUR", "KZT"} {
+		if !IsValidCurrency(code) {
+			t.Errorf("IsValidCurrency(%q) = false, want true", code)
+		}
+	}
+	for _, code := range []string{"", "usd", "XYZ", "RUBL"} {
+		if IsValidCurrency(code) {
+			t.Errorf("IsValidCurrency(%q) = true, want false", code)
+		}
+	}
+}
+
+func TestExchangeRate_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rate    ExchangeRate
+		wantErr bool
+	}{
+		{
+			name: "valid rate",
+			rate: ExchangeRate{From: "USD", To: "RUB", Rate: MustParseRate("92.5"), ValidFrom: time.Now()},
+		},
+		{
+			name:    "unknown currency",
+			rate:    ExchangeRate{From: "USD", To: "ABC", Rate: MustParseRate("1"), ValidFrom: time.Now()},
+			wantErr: true,
+		},
+		{
+			name:    "same currencies",
+			rate:    ExchangeRate{From: "USD", To: "USD", Rate: MustParseRate("1"), ValidFrom: time.Now()},
+			wantErr: true,
+		},
+		{
+			name:    "non-positive rate",
+			rate:    ExchangeRate{From: "USD", To: "RUB", Rate: MustParseRate("0"), ValidFrom: time.Now()},
+			wantErr: true,
+		},
+		{
+			name:    "missing valid_from",
+			rate:    ExchangeRate{From: "USD", To: "RUB", Rate: MustParseRate("92.5")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rate.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ExchangeRate.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,43 +1,119 @@
 package domain
 
 import (
-	"errors"
+	"fmt"
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
+// Типы записей
+const (
+	TypeIncome  = "income"
+	TypeExpense = "expense"
+)
+
+// MaxDescriptionLength — максимальная длина описания записи в символах
+const MaxDescriptionLength = 1000
+
+// MaxCategoryLength — максимальная длина категории, как у колонки VARCHAR(100)
+const MaxCategoryLength = 100
+
+// MaxDateAhead — насколько дата записи может опережать текущее время.
+// Запланированные платежи допустимы, даты через десятилетия — скорее опечатка.
+const MaxDateAhead = 10 * 365 * 24 * time.Hour
+
+// DefaultTrashRetention — сколько удаленная запись хранится в корзине до окончательного удаления
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
 // Item представляет финансовую транзакцию или запись
 type Item struct {
-	ID        int64     `json:"id"`
-	Type      string    `json:"type"`      // "income" или "expense"
-	Amount    float64   `json:"amount"`
-	Category  string    `json:"category"`
-	Date      time.Time `json:"date"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          int64        `json:"id"`
+	Type        string       `json:"type"` // "income" или "expense"
+	Amount      Money        `json:"amount"`
+	Currency    string       `json:"currency"` // код ISO 4217
+	Category    string       `json:"category"`
+	Description string       `json:"description"` // произвольное примечание
+	Date        time.Time    `json:"date"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+	Version     int64        `json:"version"`              // увеличивается при каждом изменении записи
+	DeletedAt   *time.Time   `json:"deleted_at,omitempty"` // время удаления для записей в корзине
+	Match       *SearchMatch `json:"match,omitempty"`      // заполняется только при полнотекстовом поиске
+}
+
+// SearchMatch описывает совпадение записи с поисковым запросом
+type SearchMatch struct {
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"` // фрагмент текста, найденные слова выделены тегом <mark>
 }
 
 // Analytics представляет агрегированную аналитику
 type Analytics struct {
-	Sum        float64 `json:"sum"`
-	Avg        float64 `json:"avg"`
-	Count      int64   `json:"count"`
-	Median     float64 `json:"median"`
-	Percentile float64 `json:"percentile_90"`
+	Sum         Money            `json:"sum"`
+	Avg         Money            `json:"avg"`
+	Count       int64            `json:"count"`
+	Median      Money            `json:"median"`
+	Percentile  Money            `json:"percentile_90"`
+	Min         Money            `json:"min"`
+	Max         Money            `json:"max"`
+	StdDev      Money            `json:"stddev"`   // стандартное отклонение по генеральной совокупности
+	Variance    float64          `json:"variance"` // дисперсия по генеральной совокупности, в квадратах денежных единиц
+	Percentiles map[string]Money `json:"percentiles,omitempty"`
 }
 
-// Validate проверяет корректность данных
+// AnalyticsReport представляет аналитику за период с разбивкой на доходы и расходы
+type AnalyticsReport struct {
+	Income   Analytics `json:"income"`
+	Expense  Analytics `json:"expense"`
+	Balance  Money     `json:"balance"`  // доходы минус расходы
+	Currency string    `json:"currency"` // валюта отчета
+}
+
+// Normalize приводит код валюты к верхнему регистру, подставляет валюту по умолчанию,
+// убирает пробелы по краям категории и описания и переводит дату в UTC: хранилища
+// сохраняют дату без часового пояса, а интервалы аналитики считаются от UTC
+func (i *Item) Normalize() {
+	i.Date = i.Date.UTC()
+	i.Currency = NormalizeCurrency(i.Currency)
+	if i.Currency == "" {
+		i.Currency = DefaultCurrency
+	}
+	i.Category = strings.TrimSpace(i.Category)
+	i.Description = strings.TrimSpace(i.Description)
+}
+
+// Validate проверяет корректность данных и возвращает *ValidationError
+// со всеми найденными нарушениями
 func (i *Item) Validate() error {
-	if i.Amount < 0 {
-		return errors.New("amount cannot be negative")
+	verr := &ValidationError{}
+
+	switch {
+	case i.Amount < 0:
+		verr.Add("amount", CodeOutOfRange, "amount cannot be negative")
+	case i.Amount > MaxAmount:
+		verr.Add("amount", CodeOutOfRange, fmt.Sprintf("amount must not exceed %s", MaxAmount))
+	}
+	if i.Type != TypeIncome && i.Type != TypeExpense {
+		verr.Add("type", CodeInvalid, "type must be 'income' or 'expense'")
+	}
+	switch {
+	case i.Category == "":
+		verr.Add("category", CodeRequired, "category is required")
+	case utf8.RuneCountInString(i.Category) > MaxCategoryLength:
+		verr.Add("category", CodeTooLong, fmt.Sprintf("category must not exceed %d characters", MaxCategoryLength))
 	}
-	if i.Type != "income" && i.Type != "expense" {
-		return errors.New("type must be 'income' or 'expense'")
+	switch {
+	case i.Date.IsZero():
+		verr.Add("date", CodeRequired, "date is required")
+	case i.Date.After(time.Now().Add(MaxDateAhead)):
+		verr.Add("date", CodeOutOfRange, "date must not be more than 10 years in the future")
 	}
-	if i.Category == "" {
-		return errors.New("category is required")
+	if !IsValidCurrency(i.Currency) {
+		verr.Add("currency", CodeInvalid, "currency must be an ISO 4217 code")
 	}
-	if i.Date.IsZero() {
-		return errors.New("date is required")
+	if utf8.RuneCountInString(i.Description) > MaxDescriptionLength {
+		verr.Add("description", CodeTooLong, fmt.Sprintf("description must not exceed %d characters", MaxDescriptionLength))
 	}
-	return nil
+	return verr.Err()
 }
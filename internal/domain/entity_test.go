@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"errors"
+	"strings"
 	"testing"
 	"time"
 )
@@ -16,9 +18,10 @@ func TestItem_Validate(t *testing.T) {
 			name: "valid income item",
 			item: Item{
 				Type:     "income",
-				Amount:   1000.50,
+				Amount:   NewMoney(1000, 50),
 				Category: "Salary",
 				Date:     time.Now(),
+				Currency: "RUB",
 			},
 			wantErr: false,
 		},
@@ -26,9 +29,10 @@ func TestItem_Validate(t *testing.T) {
 			name: "valid expense item",
 			item: Item{
 				Type:     "expense",
-				Amount:   500.00,
+				Amount:   NewMoney(500, 0),
 				Category: "Food",
 				Date:     time.Now(),
+				Currency: "USD",
 			},
 			wantErr: false,
 		},
@@ -36,8 +40,9 @@ func TestItem_Validate(t *testing.T) {
 			name: "negative amount",
 			item: Item{
 				Type:     "income",
-				Amount:   -100.00,
+				Amount:   NewMoney(-100, 0),
 				Category: "Salary",
+				Currency: "RUB",
 				Date:     time.Now(),
 			},
 			wantErr: true,
@@ -47,8 +52,9 @@ func TestItem_Validate(t *testing.T) {
 			name: "invalid type",
 			item: Item{
 				Type:     "invalid",
-				Amount:   100.00,
+				Amount:   NewMoney(100, 0),
 				Category: "Test",
+				Currency: "RUB",
 				Date:     time.Now(),
 			},
 			wantErr: true,
@@ -58,8 +64,9 @@ func TestItem_Validate(t *testing.T) {
 			name: "empty category",
 			item: Item{
 				Type:     "income",
-				Amount:   100.00,
+				Amount:   NewMoney(100, 0),
 				Category: "",
+				Currency: "RUB",
 				Date:     time.Now(),
 			},
 			wantErr: true,
@@ -69,13 +76,86 @@ func TestItem_Validate(t *testing.T) {
 			name: "zero date",
 			item: Item{
 				Type:     "income",
-				Amount:   100.00,
+				Amount:   NewMoney(100, 0),
 				Category: "Test",
+				Currency: "RUB",
 				Date:     time.Time{},
 			},
 			wantErr: true,
 			errMsg:  "date is required",
 		},
+		{
+			name: "unknown currency",
+			item: Item{
+				Type:     "income",
+				Amount:   NewMoney(100, 0),
+				Category: "Test",
+				Date:     time.Now(),
+				Currency: "ABC",
+			},
+			wantErr: true,
+			errMsg:  "currency must be an ISO 4217 code",
+		},
+		{
+			name: "description too long",
+			item: Item{
+				Type:        "expense",
+				Amount:      NewMoney(100, 0),
+				Category:    "Test",
+				Description: strings.Repeat("я", MaxDescriptionLength+1),
+				Date:        time.Now(),
+				Currency:    "RUB",
+			},
+			wantErr: true,
+			errMsg:  "description must not exceed 1000 characters",
+		},
+		{
+			name: "category too long",
+			item: Item{
+				Type:     "expense",
+				Amount:   NewMoney(100, 0),
+				Category: strings.Repeat("к", MaxCategoryLength+1),
+				Currency: "RUB",
+				Date:     time.Now(),
+			},
+			wantErr: true,
+			errMsg:  "category must not exceed 100 characters",
+		},
+		{
+			name: "category of max length",
+			item: Item{
+				Type:     "expense",
+				Amount:   NewMoney(100, 0),
+				Category: strings.Repeat("к", MaxCategoryLength),
+				Currency: "RUB",
+				Date:     time.Now(),
+			},
+			wantErr: false,
+		},
+		{
+			name: "amount exceeds column precision",
+			item: Item{
+				Type:     "income",
+				Amount:   MaxAmount + 1,
+				Category: "Salary",
+				Currency: "RUB",
+				Date:     time.Now(),
+			},
+			wantErr: true,
+			errMsg:  "amount must not exceed 9999999999999.99",
+		},
+		{
+			name: "date far in the future",
+			item: Item{
+				Type:     "income",
+				Amount:   NewMoney(100, 0),
+				Category: "Salary",
+				Currency: "RUB",
+				Date:     time.Now().AddDate(20, 0, 0),
+			},
+			wantErr: true,
+			errMsg:  "date must not be more than 10 years in the future",
+		},
 	}
 
 	for _, tt := range tests {
@@ -91,3 +171,52 @@ func TestItem_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestItem_Validate_AllErrors(t *testing.T) {
+	item := Item{Type: "income", Amount: NewMoney(-5, 0), Category: "", Currency: "RUB", Date: time.Now()}
+	err := item.Validate()
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) || !errors.Is(err, ErrValidation) {
+		t.Fatalf("Validate() error = %v, want *ValidationError", err)
+	}
+	want := []FieldError{
+		{Field: "amount", Code: CodeOutOfRange, Message: "amount cannot be negative"},
+		{Field: "category", Code: CodeRequired, Message: "category is required"},
+	}
+	if len(verr.Errors) != len(want) {
+		t.Fatalf("Validate() errors = %+v, want %+v", verr.Errors, want)
+	}
+	for i := range want {
+		if verr.Errors[i] != want[i] {
+			t.Errorf("Validate() errors[%d] = %+v, want %+v", i, verr.Errors[i], want[i])
+		}
+	}
+}
+
+func TestItem_Normalize(t *testing.T) {
+	item := Item{}
+	item.Normalize()
+	if item.Currency != DefaultCurrency {
+		t.Errorf("Normalize() Currency = %v, want %v", item.Currency, DefaultCurrency)
+	}
+
+	item = Item{Currency: " usd "}
+	item.Normalize()
+	if item.Currency != "USD" {
+		t.Errorf("Normalize() Currency = %v, want USD", item.Currency)
+	}
+
+	item = Item{Category: " Еда  ", Description: "  обед с коллегами \n"}
+	item.Normalize()
+	if item.Category != "Еда" || item.Description != "обед с коллегами" {
+		t.Errorf("Normalize() Category = %q, Description = %q, want trimmed", item.Category, item.Description)
+	}
+
+	moscow := time.FixedZone("MSK", 3*60*60)
+	item = Item{Date: time.Date(2024, 1, 1, 1, 0, 0, 0, moscow)}
+	item.Normalize()
+	if item.Date.Location() != time.UTC || !item.Date.Equal(time.Date(2023, 12, 31, 22, 0, 0, 0, time.UTC)) {
+		t.Errorf("Normalize() Date = %v, want the same instant in UTC", item.Date)
+	}
+}
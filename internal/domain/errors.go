@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+// Категории ошибок. Вызывающий код проверяет их через errors.Is
+// и не зависит от того, какое хранилище или слой вернул ошибку.
+var (
+	ErrNotFound    = errors.New("not found")
+	ErrValidation  = errors.New("validation failed")
+	ErrConflict    = errors.New("conflict")
+	ErrUnavailable = errors.New("service unavailable")
+)
+
+// ErrVersionMismatch возвращается, если запись изменилась после того,
+// как клиент ее прочитал. Относится к категории ErrConflict.
+var ErrVersionMismatch error = &Error{Kind: ErrConflict, Message: "item was modified by another request"}
+
+// Error — ошибка предметной области: категория, сообщение для клиента
+// и, если есть, исходная ошибка
+type Error struct {
+	Kind    error  // одна из ErrNotFound, ErrValidation, ErrConflict, ErrUnavailable
+	Message string // сообщение, которое можно показать клиенту
+	Field   string // для ошибок валидации — поле, к которому относится ошибка
+	Err     error  // исходная ошибка
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" && e.Err != nil {
+		return e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap позволяет проверять через errors.Is и категорию, и исходную ошибку
+func (e *Error) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// NewNotFoundError создает ошибку отсутствующего объекта
+func NewNotFoundError(message string) error {
+	return &Error{Kind: ErrNotFound, Message: message}
+}
+
+// NewValidationError создает ошибку валидации значения поля field
+func NewValidationError(field, message string) error {
+	return &Error{Kind: ErrValidation, Field: field, Message: message}
+}
+
+// NewConflictError создает ошибку конфликта с текущим состоянием данных
+func NewConflictError(message string, err error) error {
+	return &Error{Kind: ErrConflict, Message: message, Err: err}
+}
+
+// NewUnavailableError создает ошибку недоступности хранилища или внешнего сервиса.
+// Причина сохраняется в Err, но клиенту не показывается.
+func NewUnavailableError(err error) error {
+	return &Error{Kind: ErrUnavailable, Message: "service temporarily unavailable", Err: err}
+}
+
+// Коды ошибок валидации полей
+const (
+	CodeRequired   = "required"     // значение не задано
+	CodeInvalid    = "invalid"      // значение не из допустимого набора или неверного формата
+	CodeTooLong    = "too_long"     // строка длиннее допустимого
+	CodeOutOfRange = "out_of_range" // число или дата вне допустимого диапазона
+)
+
+// FieldError описывает нарушение правила валидации для одного поля
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationError содержит все нарушения, найденные при проверке объекта.
+// Проверяется через errors.Is(err, ErrValidation).
+type ValidationError struct {
+	Errors []FieldError
+}
+
+// Add добавляет нарушение для поля field
+func (e *ValidationError) Add(field, code, message string) {
+	e.Errors = append(e.Errors, FieldError{Field: field, Code: code, Message: message})
+}
+
+// Err возвращает nil, если нарушений нет, иначе саму ошибку
+func (e *ValidationError) Err() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, fieldErr := range e.Errors {
+		messages[i] = fieldErr.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
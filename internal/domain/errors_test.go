@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestError_Is(t *testing.T) {
+	cause := errors.New("connection refused")
+	tests := []struct {
+		name     string
+		err      error
+		wantKind error
+		wantMsg  string
+	}{
+		{name: "not found", err: NewNotFoundError("item not found"), wantKind: ErrNotFound, wantMsg: "item not found"},
+		{name: "validation", err: NewValidationError("amount", "amount must be positive"), wantKind: ErrValidation, wantMsg: "amount must be positive"},
+		{name: "conflict", err: NewConflictError("already exists", cause), wantKind: ErrConflict, wantMsg: "already exists"},
+		{name: "wrapped unavailable", err: fmt.Errorf("get item: %w", NewUnavailableError(cause)), wantKind: ErrUnavailable, wantMsg: "get item: service temporarily unavailable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !errors.Is(tt.err, tt.wantKind) {
+				t.Errorf("errors.Is(%v, %v) = false", tt.err, tt.wantKind)
+			}
+			for _, kind := range []error{ErrNotFound, ErrValidation, ErrConflict, ErrUnavailable} {
+				if kind != tt.wantKind && errors.Is(tt.err, kind) {
+					t.Errorf("errors.Is(%v, %v) = true", tt.err, kind)
+				}
+			}
+			if tt.err.Error() != tt.wantMsg {
+				t.Errorf("Error() = %q, want %q", tt.err.Error(), tt.wantMsg)
+			}
+		})
+	}
+
+	if !errors.Is(NewConflictError("already exists", cause), cause) {
+		t.Error("conflict error does not unwrap to its cause")
+	}
+}
@@ -0,0 +1,184 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Поля сортировки списка записей
+const (
+	SortByDate      = "date"
+	SortByAmount    = "amount"
+	SortByCategory  = "category"
+	SortByCreatedAt = "created_at"
+	SortByRank      = "rank" // релевантность, только вместе с поисковым запросом
+)
+
+// Размер страницы списка записей
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 1000
+)
+
+// ItemSort задает порядок списка записей. При равенстве значений
+// записи дополнительно упорядочиваются по ID в том же направлении.
+type ItemSort struct {
+	Field string
+	Desc  bool
+}
+
+// DefaultItemSort — новые записи первыми
+var DefaultItemSort = ItemSort{Field: SortByDate, Desc: true}
+
+// DefaultSearchSort — при поиске сначала наиболее релевантные записи
+var DefaultSearchSort = ItemSort{Field: SortByRank, Desc: true}
+
+// ItemFilter описывает фильтры, сортировку и страницу списка записей
+type ItemFilter struct {
+	From       *time.Time
+	To         *time.Time
+	Types      []string
+	Categories []string
+	MinAmount  *Money
+	MaxAmount  *Money
+	Query      string // полнотекстовый поиск по категории и описанию
+	Sort       ItemSort
+	Limit      int
+	Cursor     string // непрозрачный курсор из NextCursor предыдущей страницы
+	Deleted    bool   // только удаленные записи (корзина) вместо действующих
+}
+
+// ItemPage представляет одну страницу списка записей
+type ItemPage struct {
+	Items      []*Item `json:"items"`
+	NextCursor string  `json:"next_cursor,omitempty"` // пустой на последней странице
+	Total      int64   `json:"total"`                 // число записей по фильтрам без учета страницы
+}
+
+// Cursor указывает на последнюю запись страницы при keyset-пагинации
+type Cursor struct {
+	Sort  string `json:"s"`  // сортировка, для которой выдан курсор
+	Value string `json:"v"`  // значение поля сортировки
+	ID    int64  `json:"id"` // ID записи
+}
+
+// ParseItemSort разбирает сортировку вида "amount" или "-date" (по убыванию).
+// Для пустой строки возвращает нулевое значение — сортировку выберет Normalize.
+func ParseItemSort(value string) (ItemSort, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ItemSort{}, nil
+	}
+
+	sort := ItemSort{Field: value}
+	if strings.HasPrefix(value, "-") {
+		sort = ItemSort{Field: value[1:], Desc: true}
+	}
+
+	switch sort.Field {
+	case SortByDate, SortByAmount, SortByCategory, SortByCreatedAt, SortByRank:
+		return sort, nil
+	}
+	return ItemSort{}, NewValidationError("sort", "sort must be one of date, amount, category, created_at, rank with optional '-' prefix")
+}
+
+// String возвращает сортировку в формате параметра sort
+func (s ItemSort) String() string {
+	if s.Desc {
+		return "-" + s.Field
+	}
+	return s.Field
+}
+
+// Normalize подставляет значения по умолчанию для сортировки и размера страницы
+func (f *ItemFilter) Normalize() {
+	f.Query = strings.TrimSpace(f.Query)
+	if f.Sort.Field == "" {
+		f.Sort = DefaultItemSort
+		if f.Query != "" {
+			f.Sort = DefaultSearchSort
+		}
+	}
+	if f.Limit == 0 {
+		f.Limit = DefaultPageLimit
+	}
+}
+
+// Validate проверяет корректность фильтра
+func (f *ItemFilter) Validate() error {
+	if f.Limit < 1 || f.Limit > MaxPageLimit {
+		return NewValidationError("limit", fmt.Sprintf("limit must be between 1 and %d", MaxPageLimit))
+	}
+	if _, err := ParseItemSort(f.Sort.String()); err != nil {
+		return err
+	}
+	if f.Sort.Field == SortByRank && f.Query == "" {
+		return NewValidationError("sort", "sort by rank requires 'q'")
+	}
+	for _, t := range f.Types {
+		if t != TypeIncome && t != TypeExpense {
+			return NewValidationError("type", "type must be 'income' or 'expense'")
+		}
+	}
+	if f.From != nil && f.To != nil && f.From.After(*f.To) {
+		return NewValidationError("from", "'from' must not be after 'to'")
+	}
+	if f.MinAmount != nil && f.MaxAmount != nil && *f.MinAmount > *f.MaxAmount {
+		return NewValidationError("amount_min", "amount_min must not be greater than amount_max")
+	}
+	if f.Cursor != "" {
+		if _, err := f.DecodeCursor(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecodeCursor разбирает курсор фильтра и проверяет, что он выдан для той же сортировки
+func (f *ItemFilter) DecodeCursor() (*Cursor, error) {
+	if f.Cursor == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(f.Cursor)
+	if err != nil {
+		return nil, NewValidationError("cursor", "invalid cursor")
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, NewValidationError("cursor", "invalid cursor")
+	}
+	if cursor.Sort != f.Sort.String() {
+		return nil, NewValidationError("cursor", "cursor does not match sort")
+	}
+	return &cursor, nil
+}
+
+// NewCursor создает курсор, указывающий на запись item при сортировке sort
+func NewCursor(item *Item, sort ItemSort) string {
+	cursor := Cursor{Sort: sort.String(), Value: item.SortValue(sort.Field), ID: item.ID}
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// SortValue возвращает значение поля сортировки записи в текстовом виде
+func (i *Item) SortValue(field string) string {
+	switch field {
+	case SortByAmount:
+		return i.Amount.String()
+	case SortByCategory:
+		return i.Category
+	case SortByCreatedAt:
+		return i.CreatedAt.UTC().Format(time.RFC3339Nano)
+	case SortByRank:
+		if i.Match == nil {
+			return "0"
+		}
+		return strconv.FormatFloat(i.Match.Rank, 'g', -1, 64)
+	default:
+		return i.Date.UTC().Format(time.RFC3339Nano)
+	}
+}
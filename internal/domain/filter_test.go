@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseItemSort(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    ItemSort
+		wantErr bool
+	}{
+		{value: "", want: ItemSort{}},
+		{value: "-rank", want: DefaultSearchSort},
+		{value: "amount", want: ItemSort{Field: SortByAmount}},
+		{value: "-created_at", want: ItemSort{Field: SortByCreatedAt, Desc: true}},
+		{value: "category", want: ItemSort{Field: SortByCategory}},
+		{value: "id", wantErr: true},
+		{value: "--date", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseItemSort(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseItemSort() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseItemSort() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestItemFilter_Cursor(t *testing.T) {
+	item := &Item{ID: 42, Amount: NewMoney(10, 5), Date: time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)}
+	sort := ItemSort{Field: SortByAmount, Desc: true}
+
+	filter := ItemFilter{Sort: sort, Cursor: NewCursor(item, sort)}
+	cursor, err := filter.DecodeCursor()
+	if err != nil {
+		t.Fatalf("DecodeCursor() error = %v", err)
+	}
+	if cursor.ID != 42 || cursor.Value != "10.05" {
+		t.Errorf("DecodeCursor() = %+v, want id 42 and value 10.05", cursor)
+	}
+
+	filter.Sort = DefaultItemSort
+	if _, err := filter.DecodeCursor(); err == nil {
+		t.Error("DecodeCursor() expected error for cursor issued for another sort")
+	}
+
+	filter.Cursor = "not-a-cursor"
+	if _, err := filter.DecodeCursor(); err == nil {
+		t.Error("DecodeCursor() expected error for malformed cursor")
+	}
+}
+
+func TestItemFilter_Validate(t *testing.T) {
+	from := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	low, high := NewMoney(1, 0), NewMoney(2, 0)
+
+	tests := []struct {
+		name    string
+		filter  ItemFilter
+		wantErr bool
+	}{
+		{name: "defaults", filter: ItemFilter{}},
+		{name: "amount range", filter: ItemFilter{MinAmount: &low, MaxAmount: &high}},
+		{name: "reversed amount range", filter: ItemFilter{MinAmount: &high, MaxAmount: &low}, wantErr: true},
+		{name: "reversed date range", filter: ItemFilter{From: &from, To: &to}, wantErr: true},
+		{name: "negative limit", filter: ItemFilter{Limit: -1}, wantErr: true},
+		{name: "invalid type", filter: ItemFilter{Types: []string{"income", "other"}}, wantErr: true},
+		{name: "search", filter: ItemFilter{Query: "кофе"}},
+		{name: "rank without query", filter: ItemFilter{Sort: DefaultSearchSort}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filter.Normalize()
+			err := tt.filter.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Действия с записью в истории изменений
+const (
+	HistoryCreate  = "create"
+	HistoryUpdate  = "update"
+	HistoryDelete  = "delete"
+	HistoryRestore = "restore"
+)
+
+// AnonymousActor — автор изменения, если клиент не представился
+const AnonymousActor = "anonymous"
+
+// MaxActorLength — максимальная длина имени автора изменения
+const MaxActorLength = 255
+
+// HistoryEntry — одно изменение записи. Before — запись до изменения
+// (нет у create и restore), After — после (нет у delete).
+type HistoryEntry struct {
+	ID        int64     `json:"id"`
+	ItemID    int64     `json:"item_id"`
+	Action    string    `json:"action"`
+	Actor     string    `json:"actor"`
+	Before    *Item     `json:"before,omitempty"`
+	After     *Item     `json:"after,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewHistoryEntry создает запись истории об изменении, выполненном автором из ctx.
+// Запись хранит копии before и after, поэтому их дальнейшие изменения ее не затрагивают.
+func NewHistoryEntry(ctx context.Context, action string, before, after *Item) *HistoryEntry {
+	entry := &HistoryEntry{Action: action, Actor: ActorFromContext(ctx), Before: snapshot(before), After: snapshot(after), CreatedAt: time.Now()}
+	if after != nil {
+		entry.ItemID = after.ID
+	} else if before != nil {
+		entry.ItemID = before.ID
+	}
+	return entry
+}
+
+// snapshot копирует запись без данных поиска
+func snapshot(item *Item) *Item {
+	if item == nil {
+		return nil
+	}
+	copied := *item
+	copied.Match = nil
+	return &copied
+}
+
+// HistoryFilter описывает выборку истории изменений. Записи истории
+// отдаются от новых к старым, страница продолжается после Cursor.
+type HistoryFilter struct {
+	ItemID int64 // 0 — изменения всех записей
+	Actor  string
+	From   *time.Time
+	To     *time.Time
+	Limit  int
+	Cursor string // непрозрачный курсор из NextCursor предыдущей страницы
+}
+
+// HistoryPage представляет одну страницу истории изменений
+type HistoryPage struct {
+	Entries    []*HistoryEntry `json:"entries"`
+	NextCursor string          `json:"next_cursor,omitempty"` // пустой на последней странице
+}
+
+// Normalize подставляет размер страницы по умолчанию
+func (f *HistoryFilter) Normalize() {
+	f.Actor = strings.TrimSpace(f.Actor)
+	if f.Limit == 0 {
+		f.Limit = DefaultPageLimit
+	}
+}
+
+// Validate проверяет корректность фильтра истории
+func (f *HistoryFilter) Validate() error {
+	if f.Limit < 1 || f.Limit > MaxPageLimit {
+		return NewValidationError("limit", fmt.Sprintf("limit must be between 1 and %d", MaxPageLimit))
+	}
+	if f.From != nil && f.To != nil && f.From.After(*f.To) {
+		return NewValidationError("from", "'from' must not be after 'to'")
+	}
+	if _, err := f.DecodeCursor(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DecodeCursor возвращает id записи истории, после которой начинается страница, или 0
+func (f *HistoryFilter) DecodeCursor() (int64, error) {
+	if f.Cursor == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseInt(f.Cursor, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, NewValidationError("cursor", "invalid cursor")
+	}
+	return id, nil
+}
+
+// actorKey — ключ автора изменений в контексте запроса
+type actorKey struct{}
+
+// WithActor возвращает контекст, изменения в котором записываются от имени actor
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext возвращает автора изменений из контекста или AnonymousActor
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return AnonymousActor
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNewHistoryEntry(t *testing.T) {
+	ctx := WithActor(context.Background(), "alice")
+	before := &Item{ID: 7, Category: "Food", Match: &SearchMatch{Rank: 0.5}}
+
+	entry := NewHistoryEntry(ctx, HistoryDelete, before, nil)
+	before.Category = "Cafe"
+
+	if entry.ItemID != 7 || entry.Actor != "alice" || entry.After != nil {
+		t.Errorf("NewHistoryEntry() = %+v, want item 7 by alice without after", entry)
+	}
+	if entry.Before.Category != "Food" || entry.Before.Match != nil {
+		t.Errorf("NewHistoryEntry() before = %+v, want copy without match", entry.Before)
+	}
+	if got := NewHistoryEntry(context.Background(), HistoryCreate, nil, &Item{ID: 8}); got.Actor != AnonymousActor || got.ItemID != 8 {
+		t.Errorf("NewHistoryEntry() without actor = %+v, want anonymous item 8", got)
+	}
+}
+
+func TestHistoryFilter_Validate(t *testing.T) {
+	from := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		filter  HistoryFilter
+		wantErr bool
+	}{
+		{name: "defaults", filter: HistoryFilter{}},
+		{name: "cursor", filter: HistoryFilter{Cursor: "42"}},
+		{name: "invalid cursor", filter: HistoryFilter{Cursor: "-1"}, wantErr: true},
+		{name: "limit too large", filter: HistoryFilter{Limit: MaxPageLimit + 1}, wantErr: true},
+		{name: "from after to", filter: HistoryFilter{From: &from, To: &to}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filter.Normalize()
+			if err := tt.filter.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
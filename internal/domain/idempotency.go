@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// MaxIdempotencyKeyLength — максимальная длина ключа идемпотентности
+const MaxIdempotencyKeyLength = 255
+
+// DefaultIdempotencyTTL — сколько хранится ответ на запрос с ключом идемпотентности
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// DefaultIdempotencyLease — сколько ключ занят первым запросом. Если за это время
+// ответ не сохранен (например, процесс упал), повтор может перехватить ключ.
+const DefaultIdempotencyLease = time.Minute
+
+// IdempotencyRecord — сохраненный результат запроса с ключом идемпотентности
+type IdempotencyRecord struct {
+	Key         string
+	RequestHash string // хеш содержимого запроса, чтобы отличить повтор от другого запроса с тем же ключом
+	Response    []byte // тело ответа; nil, пока первый запрос еще выполняется
+	CreatedAt   time.Time
+	LockedUntil time.Time // до какого момента незавершенный ключ занят запросом, который его зарезервировал
+	ExpiresAt   time.Time
+}
+
+var (
+	// ErrIdempotencyKeyReused — ключ уже использован для запроса с другим содержимым
+	ErrIdempotencyKeyReused error = &Error{Kind: ErrValidation, Field: "Idempotency-Key", Message: "idempotency key was already used for a different request"}
+	// ErrIdempotencyKeyInProgress — запрос с этим ключом еще выполняется
+	ErrIdempotencyKeyInProgress error = &Error{Kind: ErrConflict, Message: "request with this idempotency key is still in progress"}
+	// ErrIdempotencyKeyTakenOver — аренда ключа истекла и его перехватил повтор запроса
+	ErrIdempotencyKeyTakenOver error = &Error{Kind: ErrConflict, Message: "idempotency key reservation expired and was taken over by a retry"}
+)
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"errors"
+	"sort"
+)
+
+// ImportRow — запись, прочитанная из строки импортируемого файла
+type ImportRow struct {
+	Line int   // номер строки файла, начиная с 1
+	Item *Item // nil, если строку не удалось разобрать
+	Err  error // ошибка разбора строки
+}
+
+// ImportRowError описывает ошибку в строке импортируемого файла
+type ImportRowError struct {
+	Line   int          `json:"line"`
+	Error  string       `json:"error"`
+	Fields []FieldError `json:"fields,omitempty"` // нарушения по полям, если строка не прошла валидацию
+}
+
+// ImportReport представляет результат импорта записей
+type ImportReport struct {
+	DryRun   bool             `json:"dry_run"`
+	Total    int              `json:"total"`    // строк с данными в файле
+	Valid    int              `json:"valid"`    // строк без ошибок
+	Imported int              `json:"imported"` // сохранено записей, 0 при dry_run
+	Errors   []ImportRowError `json:"errors"`
+}
+
+// AddError добавляет ошибку строки в отчет
+func (r *ImportReport) AddError(line int, err error) {
+	rowErr := ImportRowError{Line: line, Error: err.Error()}
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		rowErr.Fields = verr.Errors
+	}
+	r.Errors = append(r.Errors, rowErr)
+}
+
+// SortErrors упорядочивает ошибки по номеру строки
+func (r *ImportReport) SortErrors() {
+	sort.SliceStable(r.Errors, func(i, j int) bool {
+		return r.Errors[i].Line < r.Errors[j].Line
+	})
+}
@@ -0,0 +1,211 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MoneyScale — количество знаков после запятой, как у колонки DECIMAL(15,2)
+const MoneyScale = 2
+
+const centsInUnit = 100
+
+// MaxAmount — наибольшая сумма, которая помещается в колонку DECIMAL(15,2)
+const MaxAmount = Money(999_999_999_999_999)
+
+// maxMoneyDigits ограничивает длину целой части, чтобы сумма помещалась в int64
+const maxMoneyDigits = 16
+
+// Money представляет денежную сумму с точностью до копеек.
+// Значение хранится в минимальных единицах (копейках), поэтому
+// сложение и вычитание выполняются без ошибок округления.
+type Money int64
+
+// NewMoney создает сумму из целой части и копеек, например NewMoney(1000, 50) = 1000.50
+func NewMoney(units, cents int64) Money {
+	if units < 0 {
+		return Money(units*centsInUnit - cents)
+	}
+	return Money(units*centsInUnit + cents)
+}
+
+// ParseMoney разбирает десятичную строку вида "1000.50".
+// Строки с более чем двумя знаками после запятой отклоняются.
+func ParseMoney(s string) (Money, error) {
+	return parseMoney(s, false)
+}
+
+// MoneyFromFloat округляет число до копеек (половина — от нуля).
+// Используется для вычисленных значений: средних, медиан, перцентилей.
+func MoneyFromFloat(f float64) Money {
+	return Money(math.Round(f * centsInUnit))
+}
+
+// Cents возвращает сумму в копейках
+func (m Money) Cents() int64 {
+	return int64(m)
+}
+
+// Float64 возвращает приближенное значение суммы
+func (m Money) Float64() float64 {
+	return float64(m) / centsInUnit
+}
+
+// Add возвращает сумму m и other
+func (m Money) Add(other Money) Money {
+	return m + other
+}
+
+// Sub возвращает разность m и other
+func (m Money) Sub(other Money) Money {
+	return m - other
+}
+
+// String форматирует сумму с двумя знаками после запятой
+func (m Money) String() string {
+	sign := ""
+	cents := int64(m)
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/centsInUnit, cents%centsInUnit)
+}
+
+// MarshalJSON сериализует сумму как число с фиксированными двумя знаками: 1000.50
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON принимает сумму как JSON-число или строку без потери точности
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		unquoted, err := strconv.Unquote(s)
+		if err != nil {
+			return fmt.Errorf("invalid amount %s", s)
+		}
+		s = unquoted
+	}
+	parsed, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Scan читает сумму из базы данных. Значения с большей точностью
+// (например, результат AVG) округляются до копеек.
+func (m *Money) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*m = 0
+		return nil
+	case []byte:
+		parsed, err := parseMoney(string(v), true)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	case string:
+		parsed, err := parseMoney(v, true)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	case float64:
+		*m = MoneyFromFloat(v)
+		return nil
+	case int64:
+		*m = Money(v * centsInUnit)
+		return nil
+	}
+	return fmt.Errorf("cannot scan %T into Money", src)
+}
+
+// Value передает сумму в базу данных десятичной строкой
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// parseMoney разбирает десятичную строку. При round = true лишние
+// знаки после запятой округляются, иначе считаются ошибкой.
+func parseMoney(s string, round bool) (Money, error) {
+	s = strings.TrimSpace(s)
+	invalid := fmt.Errorf("invalid amount '%s'", s)
+	if s == "" {
+		return 0, invalid
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if intPart == "" && fracPart == "" {
+		return 0, invalid
+	}
+	if !isDigits(intPart) || !isDigits(fracPart) {
+		return 0, invalid
+	}
+	intPart = strings.TrimLeft(intPart, "0")
+	if len(intPart) > maxMoneyDigits {
+		return 0, errors.New("amount is too large")
+	}
+
+	roundUp := false
+	if len(fracPart) > MoneyScale {
+		if !round {
+			return 0, fmt.Errorf("amount must have at most %d decimal places", MoneyScale)
+		}
+		roundUp = fracPart[MoneyScale] >= '5'
+		fracPart = fracPart[:MoneyScale]
+	}
+	fracPart += strings.Repeat("0", MoneyScale-len(fracPart))
+
+	var units int64
+	if intPart != "" {
+		var err error
+		units, err = strconv.ParseInt(intPart, 10, 64)
+		if err != nil {
+			return 0, invalid
+		}
+	}
+	cents, err := strconv.ParseInt(fracPart, 10, 64)
+	if err != nil {
+		return 0, invalid
+	}
+
+	value := units*centsInUnit + cents
+	if roundUp {
+		value++
+	}
+	if negative {
+		value = -value
+	}
+	return Money(value), nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,147 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    Money
+		wantErr bool
+	}{
+		{name: "integer", value: "1000", want: NewMoney(1000, 0)},
+		{name: "two decimals", value: "1000.50", want: NewMoney(1000, 50)},
+		{name: "one decimal", value: "0.5", want: NewMoney(0, 50)},
+		{name: "negative", value: "-12.05", want: NewMoney(-12, 5)},
+		{name: "leading dot", value: ".99", want: NewMoney(0, 99)},
+		{name: "too many decimals", value: "1.005", wantErr: true},
+		{name: "not a number", value: "abc", wantErr: true},
+		{name: "empty", value: "", wantErr: true},
+		{name: "only dot", value: ".", wantErr: true},
+		{name: "exponent", value: "1e3", wantErr: true},
+		{name: "too large", value: "12345678901234567", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMoney(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseMoney() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseMoney() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoney_String(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{NewMoney(1000, 50), "1000.50"},
+		{NewMoney(0, 5), "0.05"},
+		{NewMoney(-3, 5), "-3.05"},
+		{0, "0.00"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.money.String(); got != tt.want {
+			t.Errorf("Money.String() = %v, want %v", got, tt.want)
+		}
+	}
+}
+
+func TestMoney_ExactArithmetic(t *testing.T) {
+	var sum Money
+	for i := 0; i < 10; i++ {
+		sum = sum.Add(NewMoney(0, 10))
+	}
+	if sum != NewMoney(1, 0) {
+		t.Errorf("sum of ten 0.10 = %v, want 1.00", sum)
+	}
+	if got := NewMoney(100, 0).Sub(NewMoney(0, 1)); got.String() != "99.99" {
+		t.Errorf("Money.Sub() = %v, want 99.99", got)
+	}
+}
+
+func TestMoney_JSON(t *testing.T) {
+	data, err := json.Marshal(struct {
+		Amount Money `json:"amount"`
+	}{NewMoney(1000, 50)})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if string(data) != `{"amount":1000.50}` {
+		t.Errorf("json.Marshal() = %s", data)
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    Money
+		wantErr bool
+	}{
+		{name: "number", input: `{"amount":1000.5}`, want: NewMoney(1000, 50)},
+		{name: "string", input: `{"amount":"19.99"}`, want: NewMoney(19, 99)},
+		{name: "too precise", input: `{"amount":0.001}`, wantErr: true},
+		{name: "invalid string", input: `{"amount":"ten"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got struct {
+				Amount Money `json:"amount"`
+			}
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("json.Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got.Amount != tt.want {
+				t.Errorf("json.Unmarshal() = %v, want %v", got.Amount, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoney_Scan(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     interface{}
+		want    Money
+		wantErr bool
+	}{
+		{name: "numeric", src: []byte("5500.00"), want: NewMoney(5500, 0)},
+		{name: "rounded average", src: []byte("550.0050000000000000"), want: NewMoney(550, 1)},
+		{name: "float", src: 904.9999999, want: NewMoney(905, 0)},
+		{name: "integer", src: int64(7), want: NewMoney(7, 0)},
+		{name: "null", src: nil, want: 0},
+		{name: "garbage", src: []byte("x"), wantErr: true},
+		{name: "unsupported type", src: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Money
+			err := got.Scan(tt.src)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Money.Scan() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Money.Scan() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	value, _ := NewMoney(12, 30).Value()
+	if value != "12.30" {
+		t.Errorf("Money.Value() = %v, want 12.30", value)
+	}
+}
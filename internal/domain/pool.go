@@ -0,0 +1,14 @@
+package domain
+
+// PoolStats — состояние пула соединений с базой
+type PoolStats struct {
+	MaxOpenConnections int   `json:"max_open_connections"` // 0 — без ограничения
+	OpenConnections    int   `json:"open_connections"`
+	InUse              int   `json:"in_use"`
+	Idle               int   `json:"idle"`
+	WaitCount          int64 `json:"wait_count"`           // сколько раз запрос ждал свободного соединения
+	WaitDurationMs     int64 `json:"wait_duration_ms"`     // суммарное время ожидания
+	MaxIdleClosed      int64 `json:"max_idle_closed"`      // закрыто из-за лимита простаивающих соединений
+	MaxIdleTimeClosed  int64 `json:"max_idle_time_closed"` // закрыто из-за долгого простоя
+	MaxLifetimeClosed  int64 `json:"max_lifetime_closed"`  // закрыто по истечении времени жизни
+}
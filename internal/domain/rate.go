@@ -0,0 +1,162 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// RateScale — количество знаков после запятой у курса, как у колонки NUMERIC(20, 10)
+const RateScale = 10
+
+const rateUnit = 10_000_000_000
+
+// Rate представляет курс обмена с точностью до 10 знаков после запятой.
+// Значение хранится целым числом десятимиллиардных долей, поэтому курс
+// не искажается при сохранении, а пересчет сумм одинаков во всех хранилищах.
+type Rate int64
+
+// ParseRate разбирает десятичную строку вида "92.5".
+// Строки с более чем 10 знаками после запятой отклоняются.
+func ParseRate(s string) (Rate, error) {
+	s = strings.TrimSpace(s)
+	invalid := fmt.Errorf("invalid rate '%s'", s)
+	if s == "" {
+		return 0, invalid
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if intPart == "" && fracPart == "" {
+		return 0, invalid
+	}
+	if !isDigits(intPart) || !isDigits(fracPart) {
+		return 0, invalid
+	}
+	if len(fracPart) > RateScale {
+		return 0, fmt.Errorf("rate must have at most %d decimal places", RateScale)
+	}
+	fracPart += strings.Repeat("0", RateScale-len(fracPart))
+
+	tooLarge := errors.New("rate is too large")
+	var units int64
+	if intPart = strings.TrimLeft(intPart, "0"); intPart != "" {
+		var err error
+		if units, err = strconv.ParseInt(intPart, 10, 64); err != nil {
+			return 0, tooLarge
+		}
+	}
+	fraction, err := strconv.ParseInt(fracPart, 10, 64)
+	if err != nil {
+		return 0, invalid
+	}
+	if units > (math.MaxInt64-fraction)/rateUnit {
+		return 0, tooLarge
+	}
+
+	value := units*rateUnit + fraction
+	if negative {
+		value = -value
+	}
+	return Rate(value), nil
+}
+
+// MustParseRate разбирает курс как ParseRate и паникует при ошибке.
+// Используется для курсов, заданных в коде.
+func MustParseRate(s string) Rate {
+	rate, err := ParseRate(s)
+	if err != nil {
+		panic(err)
+	}
+	return rate
+}
+
+// Rat возвращает точное значение курса
+func (r Rate) Rat() *big.Rat {
+	return big.NewRat(int64(r), rateUnit)
+}
+
+// String форматирует курс без незначащих нулей: 92.5, 0.0105, 1
+func (r Rate) String() string {
+	sign := ""
+	value := int64(r)
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+	s := sign + strconv.FormatInt(value/rateUnit, 10)
+	if fraction := value % rateUnit; fraction != 0 {
+		s += "." + strings.TrimRight(fmt.Sprintf("%0*d", RateScale, fraction), "0")
+	}
+	return s
+}
+
+// MarshalJSON сериализует курс как JSON-число: 92.5
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalJSON принимает курс как JSON-число или строку без потери точности
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		unquoted, err := strconv.Unquote(s)
+		if err != nil {
+			return fmt.Errorf("invalid rate %s", s)
+		}
+		s = unquoted
+	}
+	parsed, err := ParseRate(s)
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+// Scan читает курс из базы данных: десятичную строку колонки NUMERIC
+// или целое число десятимиллиардных долей колонки INTEGER SQLite
+func (r *Rate) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*r = 0
+		return nil
+	case []byte:
+		return r.scanString(string(v))
+	case string:
+		return r.scanString(v)
+	case int64:
+		*r = Rate(v)
+		return nil
+	}
+	return fmt.Errorf("cannot scan %T into Rate", src)
+}
+
+func (r *Rate) scanString(s string) error {
+	parsed, err := ParseRate(s)
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+// Value передает курс в базу данных десятичной строкой
+func (r Rate) Value() (driver.Value, error) {
+	return r.String(), nil
+}
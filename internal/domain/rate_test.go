@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    Rate
+		wantErr bool
+	}{
+		{name: "integer", value: "90", want: 900_000_000_000},
+		{name: "decimals", value: "92.5", want: 925_000_000_000},
+		{name: "ten decimals", value: "0.0000000001", want: 1},
+		{name: "negative", value: "-1", want: -10_000_000_000},
+		{name: "largest", value: "922337203.6854775807", want: 9_223_372_036_854_775_807},
+		{name: "too many decimals", value: "0.00000000001", wantErr: true},
+		{name: "too large", value: "922337203.6854775808", wantErr: true},
+		{name: "too many digits", value: "99999999999999999999", wantErr: true},
+		{name: "not a number", value: "abc", wantErr: true},
+		{name: "exponent", value: "1e3", wantErr: true},
+		{name: "empty", value: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRate(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseRate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseRate() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRate_String(t *testing.T) {
+	tests := map[string]Rate{
+		"92.5":         MustParseRate("92.50"),
+		"1":            MustParseRate("1.0000000000"),
+		"0.0105":       MustParseRate("0.0105"),
+		"0.6123456789": MustParseRate("0.6123456789"),
+		"-1.5":         MustParseRate("-1.5"),
+	}
+	for want, rate := range tests {
+		if got := rate.String(); got != want {
+			t.Errorf("Rate.String() = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestRate_JSON(t *testing.T) {
+	var rate ExchangeRate
+	if err := json.Unmarshal([]byte(`{"rate": 0.6123456789}`), &rate); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if rate.Rate != MustParseRate("0.6123456789") {
+		t.Errorf("Unmarshal() rate = %s", rate.Rate)
+	}
+	if err := json.Unmarshal([]byte(`{"rate": "92.5"}`), &rate); err != nil || rate.Rate != MustParseRate("92.5") {
+		t.Errorf("Unmarshal() string rate = %s, %v", rate.Rate, err)
+	}
+	if err := json.Unmarshal([]byte(`{"rate": 0.12345678901}`), &rate); err == nil {
+		t.Error("Unmarshal() expected error for more than 10 decimal places")
+	}
+
+	data, err := json.Marshal(Rate(925_000_000_000))
+	if err != nil || string(data) != "92.5" {
+		t.Errorf("Marshal() = %s, %v, want 92.5", data, err)
+	}
+}
+
+func TestRate_Scan(t *testing.T) {
+	tests := []struct {
+		name string
+		src  interface{}
+		want Rate
+	}{
+		{name: "numeric", src: []byte("92.5000000000"), want: MustParseRate("92.5")},
+		{name: "string", src: "0.0105", want: MustParseRate("0.0105")},
+		{name: "scaled integer", src: int64(925_000_000_000), want: MustParseRate("92.5")},
+		{name: "null", src: nil, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Rate
+			if err := got.Scan(tt.src); err != nil {
+				t.Fatalf("Scan() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Scan() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
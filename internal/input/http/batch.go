@@ -0,0 +1,42 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"net/http"
+)
+
+// maxBatchBodySize ограничивает размер тела пакетного запроса
+const maxBatchBodySize = 32 << 20
+
+// batchRequest — тело запроса POST /api/items/batch
+type batchRequest struct {
+	Partial    bool                    `json:"partial"`
+	Operations []domain.BatchOperation `json:"operations"`
+}
+
+// ApplyBatch выполняет пакет операций создания, обновления и удаления записей.
+// В режиме partial отчет возвращается со статусом 200 даже при ошибках отдельных операций,
+// иначе при любой ошибке ничего не сохраняется и статус ответа определяется первой ошибкой.
+func (h *Handler) ApplyBatch(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBatchBodySize)
+
+	var req batchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	report, err := h.useCases.ApplyBatch(r.Context(), req.Operations, req.Partial)
+	if err != nil {
+		if report == nil {
+			respondDomainError(w, err)
+			return
+		}
+		status, _ := errorStatus(err)
+		respondJSON(w, status, report)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, report)
+}
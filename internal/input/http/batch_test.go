@@ -0,0 +1,101 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler_ApplyBatch(t *testing.T) {
+	failedReport := &domain.BatchReport{
+		Failed: 1,
+		Results: []domain.BatchResult{
+			{Index: 0, Op: domain.BatchCreate, Status: domain.BatchStatusSkipped},
+			{Index: 1, Op: domain.BatchDelete, Status: domain.BatchStatusFailed, Error: "item not found"},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		body        string
+		report      *domain.BatchReport
+		err         error
+		wantStatus  int
+		wantPartial bool
+		wantOps     int
+	}{
+		{
+			name:       "atomic batch",
+			body:       `{"operations": [{"op": "create", "item": {"type": "income", "amount": 10, "category": "Salary", "date": "2024-01-15T00:00:00Z"}}, {"op": "delete", "id": 5}]}`,
+			report:     &domain.BatchReport{Succeeded: 2},
+			wantStatus: http.StatusOK,
+			wantOps:    2,
+		},
+		{
+			name:        "partial batch",
+			body:        `{"partial": true, "operations": [{"op": "delete", "id": 5, "version": 3}]}`,
+			report:      &domain.BatchReport{Partial: true, Failed: 1},
+			wantStatus:  http.StatusOK,
+			wantPartial: true,
+			wantOps:     1,
+		},
+		{
+			name:       "atomic batch failed",
+			body:       `{"operations": [{"op": "create", "item": {}}, {"op": "delete", "id": 5}]}`,
+			report:     failedReport,
+			err:        domain.NewNotFoundError("item not found"),
+			wantStatus: http.StatusNotFound,
+			wantOps:    2,
+		},
+		{
+			name:       "empty batch",
+			body:       `{"operations": []}`,
+			err:        domain.NewValidationError("operations", "batch must contain at least one operation"),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid body",
+			body:       `[{"op": "create"}]`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotOps []domain.BatchOperation
+			var gotPartial bool
+			handler := NewHandler(&mockUseCases{
+				applyBatchFunc: func(ctx context.Context, ops []domain.BatchOperation, partial bool) (*domain.BatchReport, error) {
+					gotOps, gotPartial = ops, partial
+					return tt.report, tt.err
+				},
+			})
+
+			req := httptest.NewRequest("POST", "/api/items/batch", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.ApplyBatch(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("ApplyBatch() status = %v, want %v, body %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if len(gotOps) != tt.wantOps || gotPartial != tt.wantPartial {
+				t.Errorf("ApplyBatch() got %d ops, partial %v, want %d, %v", len(gotOps), gotPartial, tt.wantOps, tt.wantPartial)
+			}
+			if tt.report == nil {
+				return
+			}
+			var report domain.BatchReport
+			if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+				t.Fatalf("failed to decode report: %v", err)
+			}
+			if len(report.Results) != len(tt.report.Results) || report.Failed != tt.report.Failed {
+				t.Errorf("ApplyBatch() report = %+v, want %+v", report, *tt.report)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"errors"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"log"
+	"net/http"
+)
+
+// Коды ошибок в теле ответа
+const (
+	codeBadRequest   = "bad_request"
+	codeValidation   = "validation_failed"
+	codeNotFound     = "not_found"
+	codeConflict     = "conflict"
+	codePrecondition = "precondition_failed"
+	codeKeyReused    = "idempotency_key_reused"
+	codeUnavailable  = "unavailable"
+	codeInternal     = "internal"
+)
+
+// errorResponse — тело ответа с ошибкой
+type errorResponse struct {
+	Error  string              `json:"error"`            // сообщение для клиента
+	Code   string              `json:"code"`             // машиночитаемый код ошибки
+	Fields map[string]string   `json:"fields,omitempty"` // ошибки по полям запроса
+	Errors []domain.FieldError `json:"errors,omitempty"` // все нарушения валидации с кодами
+}
+
+// statusCodes сопоставляет HTTP-статусы ошибок с кодами в теле ответа
+var statusCodes = map[int]string{
+	http.StatusBadRequest:          codeBadRequest,
+	http.StatusNotFound:            codeNotFound,
+	http.StatusUnprocessableEntity: codeValidation,
+	http.StatusConflict:            codeConflict,
+	http.StatusPreconditionFailed:  codePrecondition,
+	http.StatusServiceUnavailable:  codeUnavailable,
+	http.StatusInternalServerError: codeInternal,
+}
+
+// respondDomainError отвечает на ошибку use case статусом по ее категории.
+// Сообщения неизвестных ошибок не раскрываются клиенту и пишутся в лог.
+func respondDomainError(w http.ResponseWriter, err error) {
+	var verr *domain.ValidationError
+	if errors.As(err, &verr) {
+		respondValidationError(w, verr)
+		return
+	}
+
+	status, code := errorStatus(err)
+	response := errorResponse{Error: err.Error(), Code: code}
+
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) && domainErr.Field != "" {
+		response.Fields = map[string]string{domainErr.Field: domainErr.Message}
+	}
+
+	switch status {
+	case http.StatusServiceUnavailable:
+		if domainErr != nil && domainErr.Err != nil {
+			log.Printf("service unavailable: %v", domainErr.Err)
+		}
+	case http.StatusInternalServerError:
+		log.Printf("internal error: %v", err)
+		response = errorResponse{Error: "Internal server error", Code: codeInternal}
+	}
+
+	respondJSON(w, status, response)
+}
+
+// errorStatus возвращает HTTP-статус и код ответа для ошибки use case
+func errorStatus(err error) (int, string) {
+	var verr *domain.ValidationError
+	switch {
+	case errors.As(err, &verr):
+		return http.StatusUnprocessableEntity, codeValidation
+	case errors.Is(err, domain.ErrIdempotencyKeyReused):
+		return http.StatusUnprocessableEntity, codeKeyReused
+	case errors.Is(err, domain.ErrNotFound):
+		return http.StatusNotFound, codeNotFound
+	case errors.Is(err, domain.ErrValidation):
+		return http.StatusBadRequest, codeValidation
+	case errors.Is(err, domain.ErrVersionMismatch):
+		return http.StatusPreconditionFailed, codePrecondition
+	case errors.Is(err, domain.ErrConflict):
+		return http.StatusConflict, codeConflict
+	case errors.Is(err, domain.ErrUnavailable):
+		return http.StatusServiceUnavailable, codeUnavailable
+	default:
+		return http.StatusInternalServerError, codeInternal
+	}
+}
+
+// respondValidationError отвечает статусом 422 со списком нарушений и картой
+// поле → сообщение. Для поля с несколькими нарушениями в карте остается первое.
+func respondValidationError(w http.ResponseWriter, verr *domain.ValidationError) {
+	response := errorResponse{
+		Error:  verr.Error(),
+		Code:   codeValidation,
+		Fields: make(map[string]string, len(verr.Errors)),
+		Errors: verr.Errors,
+	}
+	for _, fieldErr := range verr.Errors {
+		if _, ok := response.Fields[fieldErr.Field]; !ok {
+			response.Fields[fieldErr.Field] = fieldErr.Message
+		}
+	}
+	respondJSON(w, http.StatusUnprocessableEntity, response)
+}
+
+// respondError отвечает ошибкой с сообщением message
+func respondError(w http.ResponseWriter, status int, message string) {
+	respondJSON(w, status, errorResponse{Error: message, Code: statusCodes[status]})
+}
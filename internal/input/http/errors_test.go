@@ -0,0 +1,133 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRespondDomainError_ValidationError(t *testing.T) {
+	verr := &domain.ValidationError{}
+	verr.Add("amount", domain.CodeOutOfRange, "amount cannot be negative")
+	verr.Add("category", domain.CodeRequired, "category is required")
+
+	w := httptest.NewRecorder()
+	respondDomainError(w, fmt.Errorf("create item: %w", verr))
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("respondDomainError() status = %v, want %v", w.Code, http.StatusUnprocessableEntity)
+	}
+	var got errorResponse
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if got.Code != codeValidation || got.Fields["amount"] != "amount cannot be negative" || got.Fields["category"] != "category is required" {
+		t.Errorf("respondDomainError() body = %+v", got)
+	}
+	if len(got.Errors) != 2 || got.Errors[1].Code != domain.CodeRequired {
+		t.Errorf("respondDomainError() errors = %+v", got.Errors)
+	}
+}
+
+func TestRespondDomainError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		want       errorResponse
+	}{
+		{
+			name:       "not found",
+			err:        domain.NewNotFoundError("item not found"),
+			wantStatus: http.StatusNotFound,
+			want:       errorResponse{Error: "item not found", Code: codeNotFound},
+		},
+		{
+			name:       "validation with field",
+			err:        domain.NewValidationError("amount", "amount must be positive"),
+			wantStatus: http.StatusBadRequest,
+			want:       errorResponse{Error: "amount must be positive", Code: codeValidation, Fields: map[string]string{"amount": "amount must be positive"}},
+		},
+		{
+			name:       "wrapped conflict",
+			err:        fmt.Errorf("create rate: %w", domain.NewConflictError("rate already exists", errors.New("pq: duplicate key"))),
+			wantStatus: http.StatusConflict,
+			want:       errorResponse{Error: "create rate: rate already exists", Code: codeConflict},
+		},
+		{
+			name:       "unavailable hides cause",
+			err:        domain.NewUnavailableError(errors.New("dial tcp 10.0.0.1:5432: connection refused")),
+			wantStatus: http.StatusServiceUnavailable,
+			want:       errorResponse{Error: "service temporarily unavailable", Code: codeUnavailable},
+		},
+		{
+			name:       "unknown error hides message",
+			err:        errors.New("pq: relation \"items\" does not exist"),
+			wantStatus: http.StatusInternalServerError,
+			want:       errorResponse{Error: "Internal server error", Code: codeInternal},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			respondDomainError(w, tt.err)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("respondDomainError() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+			var got errorResponse
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if got.Error != tt.want.Error || got.Code != tt.want.Code || len(got.Fields) != len(tt.want.Fields) {
+				t.Errorf("respondDomainError() body = %+v, want %+v", got, tt.want)
+			}
+			for field, msg := range tt.want.Fields {
+				if got.Fields[field] != msg {
+					t.Errorf("respondDomainError() fields[%s] = %q, want %q", field, got.Fields[field], msg)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_QueryValidationErrors(t *testing.T) {
+	handler := NewHandler(&mockUseCases{})
+	tests := []struct {
+		name      string
+		target    string
+		serve     http.HandlerFunc
+		wantField string
+	}{
+		{name: "items limit", target: "/api/items?limit=5000", serve: handler.GetItems, wantField: "limit"},
+		{name: "items amount", target: "/api/items?amount_min=abc", serve: handler.GetItems, wantField: "amount_min"},
+		{name: "trash type", target: "/api/trash?type=debt", serve: handler.GetTrash, wantField: "type"},
+		{name: "analytics percentiles", target: "/api/analytics?from=2024-01-01T00:00:00Z&to=2024-02-01T00:00:00Z&percentiles=NaN", serve: handler.GetAnalytics, wantField: "percentiles"},
+		{name: "analytics dates", target: "/api/analytics?from=2024-01-01", serve: handler.GetAnalytics, wantField: "from"},
+		{name: "groups group_by", target: "/api/analytics/groups?from=2024-01-01T00:00:00Z&to=2024-02-01T00:00:00Z&group_by=color", serve: handler.GetGroupedAnalytics, wantField: "group_by"},
+		{name: "audit to", target: "/api/audit?to=yesterday", serve: handler.GetAudit, wantField: "to"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.serve(w, httptest.NewRequest("GET", tt.target, nil))
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %v, want %v, body %s", w.Code, http.StatusBadRequest, w.Body.String())
+			}
+			var got errorResponse
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if got.Code != codeValidation || got.Fields[tt.wantField] == "" {
+				t.Errorf("body = %+v, want validation error on %s", got, tt.wantField)
+			}
+		})
+	}
+}
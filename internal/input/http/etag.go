@@ -0,0 +1,53 @@
+package http
+
+import (
+	"errors"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// errMultipleETags возвращается, если в If-Match передано несколько ETag
+var errMultipleETags = errors.New("If-Match must contain a single ETag")
+
+// itemETag возвращает сильный ETag записи — ее версию в кавычках
+func itemETag(item *domain.Item) string {
+	return `"` + strconv.FormatInt(item.Version, 10) + `"`
+}
+
+// setItemETag добавляет в ответ ETag записи
+func setItemETag(w http.ResponseWriter, item *domain.Item) {
+	w.Header().Set("ETag", itemETag(item))
+}
+
+// parseIfMatch возвращает версию записи из заголовка If-Match.
+// 0 — заголовка нет или передан "*", версия не проверяется.
+// Слабый или чужой ETag не может совпасть с версией записи,
+// поэтому для него сразу возвращается domain.ErrVersionMismatch.
+func parseIfMatch(r *http.Request) (int64, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+	if strings.Contains(value, ",") {
+		return 0, errMultipleETags
+	}
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return 0, domain.ErrVersionMismatch
+	}
+	version, err := strconv.ParseInt(value[1:len(value)-1], 10, 64)
+	if err != nil || version <= 0 {
+		return 0, domain.ErrVersionMismatch
+	}
+	return version, nil
+}
+
+// respondIfMatchError отвечает на ошибку разбора If-Match
+func respondIfMatchError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errMultipleETags) {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	respondDomainError(w, err)
+}
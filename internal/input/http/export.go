@@ -0,0 +1,189 @@
+package http
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"github.com/dontpanicw/SalesTracker/internal/input/itemfile"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Форматы выгрузки
+const (
+	formatJSON = "json"
+	formatCSV  = "csv"
+)
+
+// exportFlushRows — через сколько строк выгрузки данные отправляются клиенту
+const exportFlushRows = 500
+
+// analyticsCSVHeader — колонки статистики в CSV-выгрузке аналитики
+var analyticsCSVHeader = []string{"sum", "avg", "count", "median", "percentile_90", "min", "max", "stddev", "variance"}
+
+// ExportItems выгружает в CSV записи по тем же фильтрам и в том же порядке, что и GetItems.
+// Строки пишутся в ответ по мере чтения из репозитория; limit и cursor не учитываются.
+func (h *Handler) ExportItems(w http.ResponseWriter, r *http.Request) {
+	if format := r.URL.Query().Get("format"); format != "" && format != formatCSV {
+		respondError(w, http.StatusBadRequest, "Unsupported export format")
+		return
+	}
+
+	filter, err := parseItemConditions(r)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	cw, err := newCSVWriter(w, r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Заголовки ответа отправляются с первой строкой, чтобы ошибку до начала
+	// выгрузки можно было вернуть обычным JSON-ответом
+	started := false
+	start := func() error {
+		started = true
+		setCSVHeaders(w, "items.csv")
+		return cw.Write(itemfile.Header)
+	}
+
+	rows := 0
+	err = h.useCases.ExportItems(r.Context(), filter, func(item *domain.Item) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if err := cw.Write(escapeItemRecord(itemfile.Record(item))); err != nil {
+			return err
+		}
+		if rows++; rows%exportFlushRows == 0 {
+			return flushCSV(w, cw)
+		}
+		return nil
+	})
+	if err != nil {
+		if !started {
+			respondDomainError(w, err)
+			return
+		}
+		// Статус уже отправлен — остается только оборвать выгрузку
+		log.Printf("items export aborted after %d rows: %v", rows, err)
+		return
+	}
+
+	if !started {
+		start()
+	}
+	cw.Flush()
+}
+
+// writeGroupsCSV выгружает сгруппированную аналитику в CSV: сначала колонки группировки
+// в порядке group_by, затем доходы, расходы и их разница, статистика
+// и запрошенные перцентили (p0.5, p0.99, ...)
+func writeGroupsCSV(w http.ResponseWriter, cw *csv.Writer, query domain.AnalyticsQuery, groups []*domain.AnalyticsGroup) {
+	header := append(append([]string{}, query.GroupBy...), "income", "expense", "net")
+	header = append(header, analyticsCSVHeader...)
+	for _, p := range query.Percentiles {
+		header = append(header, "p"+domain.PercentileKey(p))
+	}
+
+	setCSVHeaders(w, "analytics.csv")
+	cw.Write(header)
+	for _, group := range groups {
+		record := make([]string, 0, len(header))
+		for _, field := range query.GroupBy {
+			switch {
+			case field == domain.GroupByCategory && group.Category != nil:
+				record = append(record, escapeCell(*group.Category))
+			case field == domain.GroupByType && group.Type != nil:
+				record = append(record, *group.Type)
+			case domain.IsPeriodGroup(field) && group.Period != nil:
+				record = append(record, group.Period.Format("2006-01-02"))
+			default:
+				record = append(record, "")
+			}
+		}
+		a := group.Analytics
+		record = append(record,
+			group.Income.String(), group.Expense.String(), group.Net.String(),
+			a.Sum.String(), a.Avg.String(), strconv.FormatInt(a.Count, 10), a.Median.String(),
+			a.Percentile.String(), a.Min.String(), a.Max.String(), a.StdDev.String(),
+			strconv.FormatFloat(a.Variance, 'f', -1, 64),
+		)
+		for _, p := range query.Percentiles {
+			record = append(record, a.Percentiles[domain.PercentileKey(p)].String())
+		}
+		cw.Write(record)
+	}
+	cw.Flush()
+}
+
+// escapeCell защищает текст ячейки от выполнения как формулы в Excel и других
+// табличных редакторах: значение, начинающееся с =, +, - или @, предваряется апострофом.
+// Применяется только к выгрузке через HTTP: файлы команды export читаются
+// обратно командой import без изменений.
+func escapeCell(s string) string {
+	if s != "" && strings.ContainsRune("=+-@", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// escapeItemRecord экранирует текстовые колонки строки выгрузки записей
+func escapeItemRecord(record []string) []string {
+	for i, column := range itemfile.Header {
+		if column == "category" || column == "description" {
+			record[i] = escapeCell(record[i])
+		}
+	}
+	return record
+}
+
+// parseFormat разбирает параметр format: json (по умолчанию) или csv
+func parseFormat(r *http.Request) (string, error) {
+	switch format := r.URL.Query().Get("format"); format {
+	case "", formatJSON:
+		return formatJSON, nil
+	case formatCSV:
+		return formatCSV, nil
+	default:
+		return "", fmt.Errorf("Unsupported format '%s'", format)
+	}
+}
+
+// newCSVWriter создает CSV-писатель с разделителем из параметра delimiter:
+// один символ, "tab" для табуляции, по умолчанию запятая
+func newCSVWriter(w http.ResponseWriter, r *http.Request) (*csv.Writer, error) {
+	delimiter, err := itemfile.ParseDelimiter(r.URL.Query().Get("delimiter"))
+	if err != nil {
+		return nil, err
+	}
+	cw := csv.NewWriter(w)
+	cw.Comma = delimiter
+	return cw, nil
+}
+
+// setCSVHeaders выставляет заголовки ответа для скачивания CSV-файла
+func setCSVHeaders(w http.ResponseWriter, filename string) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	w.WriteHeader(http.StatusOK)
+}
+
+// flushCSV отправляет клиенту накопленные строки
+func flushCSV(w http.ResponseWriter, cw *csv.Writer) error {
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return err
+	}
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler_ExportItems(t *testing.T) {
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	items := []*domain.Item{
+		{ID: 1, Type: "income", Amount: domain.NewMoney(1000, 50), Currency: "RUB", Category: "Salary", Date: date, CreatedAt: date, UpdatedAt: date},
+		{ID: 2, Type: "expense", Amount: domain.NewMoney(5, 0), Currency: "USD", Category: "Food", Description: "кофе; круассан", Date: date, CreatedAt: date, UpdatedAt: date},
+	}
+	stream := func(ctx context.Context, filter domain.ItemFilter, fn func(*domain.Item) error) error {
+		for _, item := range items {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		mock       *mockUseCases
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "csv with header",
+			query:      "?format=csv&type=income,expense",
+			mock:       &mockUseCases{exportItemsFunc: stream},
+			wantStatus: http.StatusOK,
+			wantBody: "id,type,amount,currency,category,description,date,created_at,updated_at\n" +
+				"1,income,1000.50,RUB,Salary,,2024-01-15T00:00:00Z,2024-01-15T00:00:00Z,2024-01-15T00:00:00Z\n" +
+				"2,expense,5.00,USD,Food,кофе; круассан,2024-01-15T00:00:00Z,2024-01-15T00:00:00Z,2024-01-15T00:00:00Z\n",
+		},
+		{
+			name:       "semicolon delimiter quotes fields",
+			query:      "?delimiter=%3B",
+			mock:       &mockUseCases{exportItemsFunc: stream},
+			wantStatus: http.StatusOK,
+			wantBody: "id;type;amount;currency;category;description;date;created_at;updated_at\n" +
+				"1;income;1000.50;RUB;Salary;;2024-01-15T00:00:00Z;2024-01-15T00:00:00Z;2024-01-15T00:00:00Z\n" +
+				"2;expense;5.00;USD;Food;\"кофе; круассан\";2024-01-15T00:00:00Z;2024-01-15T00:00:00Z;2024-01-15T00:00:00Z\n",
+		},
+		{
+			name:       "empty result still has header",
+			query:      "?delimiter=tab",
+			mock:       &mockUseCases{},
+			wantStatus: http.StatusOK,
+			wantBody:   "id\ttype\tamount\tcurrency\tcategory\tdescription\tdate\tcreated_at\tupdated_at\n",
+		},
+		{
+			name:  "formula-like text is escaped",
+			query: "",
+			mock: &mockUseCases{
+				exportItemsFunc: func(ctx context.Context, filter domain.ItemFilter, fn func(*domain.Item) error) error {
+					return fn(&domain.Item{ID: 3, Type: "expense", Amount: domain.NewMoney(1, 0), Currency: "RUB", Category: "=HYPERLINK(\"x\")", Description: "-1+2", Date: date, CreatedAt: date, UpdatedAt: date})
+				},
+			},
+			wantStatus: http.StatusOK,
+			wantBody: "id,type,amount,currency,category,description,date,created_at,updated_at\n" +
+				"3,expense,1.00,RUB,\"'=HYPERLINK(\"\"x\"\")\",'-1+2,2024-01-15T00:00:00Z,2024-01-15T00:00:00Z,2024-01-15T00:00:00Z\n",
+		},
+		{
+			name:  "limit above page maximum is ignored",
+			query: "?limit=5000&cursor=abc",
+			mock: &mockUseCases{
+				exportItemsFunc: func(ctx context.Context, filter domain.ItemFilter, fn func(*domain.Item) error) error {
+					if filter.Limit != 0 || filter.Cursor != "" {
+						return fmt.Errorf("unexpected pagination in filter %+v", filter)
+					}
+					return stream(ctx, filter, fn)
+				},
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "unsupported format",
+			query:      "?format=xlsx",
+			mock:       &mockUseCases{},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid delimiter",
+			query:      "?delimiter=%22",
+			mock:       &mockUseCases{},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "error before first row",
+			query: "",
+			mock: &mockUseCases{
+				exportItemsFunc: func(ctx context.Context, filter domain.ItemFilter, fn func(*domain.Item) error) error {
+					return errors.New("database error")
+				},
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHandler(tt.mock)
+
+			req := httptest.NewRequest("GET", "/api/items/export"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.ExportItems(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("ExportItems() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("ExportItems() body = %q, want %q", w.Body.String(), tt.wantBody)
+			}
+			if tt.wantStatus == http.StatusOK && w.Header().Get("Content-Type") != "text/csv; charset=utf-8" {
+				t.Errorf("ExportItems() Content-Type = %v", w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestHandler_GetGroupedAnalytics_CSV(t *testing.T) {
+	category := "Food"
+	period := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock := &mockUseCases{
+		getGroupedFunc: func(ctx context.Context, query domain.AnalyticsQuery) ([]*domain.AnalyticsGroup, error) {
+			return []*domain.AnalyticsGroup{{
+				Category: &category,
+				Period:   &period,
+				Income:   domain.NewMoney(200, 0),
+				Expense:  domain.NewMoney(100, 0),
+				Net:      domain.NewMoney(100, 0),
+				Analytics: domain.Analytics{
+					Sum:         domain.NewMoney(300, 0),
+					Avg:         domain.NewMoney(150, 0),
+					Count:       2,
+					Median:      domain.NewMoney(150, 0),
+					Percentile:  domain.NewMoney(190, 0),
+					Min:         domain.NewMoney(100, 0),
+					Max:         domain.NewMoney(200, 0),
+					StdDev:      domain.NewMoney(50, 0),
+					Variance:    2500,
+					Percentiles: map[string]domain.Money{"0.99": domain.NewMoney(199, 0)},
+				},
+			}}, nil
+		},
+	}
+
+	handler := NewHandler(mock)
+	req := httptest.NewRequest("GET", "/api/analytics/groups?from=2024-01-01T00:00:00Z&to=2024-01-31T00:00:00Z&group_by=category,month&percentiles=0.99&format=csv", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetGroupedAnalytics(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("GetGroupedAnalytics() status = %v, want %v", w.Code, http.StatusOK)
+	}
+	want := "category,month,income,expense,net,sum,avg,count,median,percentile_90,min,max,stddev,variance,p0.99\n" +
+		"Food,2024-01-01,200.00,100.00,100.00,300.00,150.00,2,150.00,190.00,100.00,200.00,50.00,2500,199.00\n"
+	if w.Body.String() != want {
+		t.Errorf("GetGroupedAnalytics() body = %q, want %q", w.Body.String(), want)
+	}
+}
+
+func TestEscapeCell(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "", want: ""},
+		{value: "Food", want: "Food"},
+		{value: "=1+1", want: "'=1+1"},
+		{value: "+7 999", want: "'+7 999"},
+		{value: "-5", want: "'-5"},
+		{value: "@SUM(A1)", want: "'@SUM(A1)"},
+		{value: "a=b", want: "a=b"},
+	}
+
+	for _, tt := range tests {
+		if got := escapeCell(tt.value); got != tt.want {
+			t.Errorf("escapeCell(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
@@ -1,11 +1,14 @@
 package http
 
 import (
+	"encoding/csv"
 	"encoding/json"
+	"fmt"
 	"github.com/dontpanicw/SalesTracker/internal/domain"
 	"github.com/dontpanicw/SalesTracker/internal/port"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -26,42 +29,48 @@ func (h *Handler) CreateItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.useCases.CreateItem(r.Context(), &item); err != nil {
-		respondError(w, http.StatusBadRequest, err.Error())
-		return
+	key := r.Header.Get("Idempotency-Key")
+	if key == "" {
+		if err := h.useCases.CreateItem(r.Context(), &item); err != nil {
+			respondDomainError(w, err)
+			return
+		}
+	} else {
+		if len(key) > domain.MaxIdempotencyKeyLength {
+			respondError(w, http.StatusBadRequest, fmt.Sprintf("Idempotency-Key must not exceed %d characters", domain.MaxIdempotencyKeyLength))
+			return
+		}
+		replayed, err := h.useCases.CreateItemIdempotent(r.Context(), key, &item)
+		if err != nil {
+			respondDomainError(w, err)
+			return
+		}
+		if replayed {
+			w.Header().Set("Idempotent-Replayed", "true")
+		}
 	}
 
+	setItemETag(w, &item)
 	respondJSON(w, http.StatusCreated, item)
 }
 
 func (h *Handler) GetItems(w http.ResponseWriter, r *http.Request) {
-	var from, to *time.Time
-
-	if fromStr := r.URL.Query().Get("from"); fromStr != "" {
-		t, err := time.Parse(time.RFC3339, fromStr)
-		if err != nil {
-			respondError(w, http.StatusBadRequest, "Invalid 'from' date format")
-			return
-		}
-		from = &t
-	}
-
-	if toStr := r.URL.Query().Get("to"); toStr != "" {
-		t, err := time.Parse(time.RFC3339, toStr)
-		if err != nil {
-			respondError(w, http.StatusBadRequest, "Invalid 'to' date format")
-			return
-		}
-		to = &t
+	filter, err := parseItemFilter(r)
+	if err != nil {
+		respondDomainError(w, err)
+		return
 	}
 
-	items, err := h.useCases.GetItems(r.Context(), from, to)
+	page, err := h.useCases.GetItems(r.Context(), filter)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+		respondDomainError(w, err)
 		return
 	}
+	if page.Items == nil {
+		page.Items = []*domain.Item{}
+	}
 
-	respondJSON(w, http.StatusOK, items)
+	respondJSON(w, http.StatusOK, page)
 }
 
 func (h *Handler) GetItem(w http.ResponseWriter, r *http.Request) {
@@ -74,10 +83,11 @@ func (h *Handler) GetItem(w http.ResponseWriter, r *http.Request) {
 
 	item, err := h.useCases.GetItem(r.Context(), id)
 	if err != nil {
-		respondError(w, http.StatusNotFound, err.Error())
+		respondDomainError(w, err)
 		return
 	}
 
+	setItemETag(w, item)
 	respondJSON(w, http.StatusOK, item)
 }
 
@@ -89,18 +99,26 @@ func (h *Handler) UpdateItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	version, err := parseIfMatch(r)
+	if err != nil {
+		respondIfMatchError(w, err)
+		return
+	}
+
 	var item domain.Item
 	if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
 		respondError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 	item.ID = id
+	item.Version = version
 
 	if err := h.useCases.UpdateItem(r.Context(), &item); err != nil {
-		respondError(w, http.StatusBadRequest, err.Error())
+		respondDomainError(w, err)
 		return
 	}
 
+	setItemETag(w, &item)
 	respondJSON(w, http.StatusOK, item)
 }
 
@@ -112,8 +130,14 @@ func (h *Handler) DeleteItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.useCases.DeleteItem(r.Context(), id); err != nil {
-		respondError(w, http.StatusNotFound, err.Error())
+	version, err := parseIfMatch(r)
+	if err != nil {
+		respondIfMatchError(w, err)
+		return
+	}
+
+	if err := h.useCases.DeleteItem(r.Context(), id, version); err != nil {
+		respondDomainError(w, err)
 		return
 	}
 
@@ -121,33 +145,241 @@ func (h *Handler) DeleteItem(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) GetAnalytics(w http.ResponseWriter, r *http.Request) {
-	fromStr := r.URL.Query().Get("from")
-	toStr := r.URL.Query().Get("to")
+	// Отчет не группируется: молча игнорировать group_by значило бы вернуть не то, что просили
+	if r.URL.Query().Has("group_by") {
+		respondError(w, http.StatusBadRequest, "group_by is not supported here, use /api/analytics/groups")
+		return
+	}
 
-	if fromStr == "" || toStr == "" {
-		respondError(w, http.StatusBadRequest, "Both 'from' and 'to' parameters are required")
+	query, err := parseAnalyticsQuery(r)
+	if err != nil {
+		respondDomainError(w, err)
 		return
 	}
 
-	from, err := time.Parse(time.RFC3339, fromStr)
+	analytics, err := h.useCases.GetAnalytics(r.Context(), query)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid 'from' date format")
+		respondDomainError(w, err)
 		return
 	}
 
-	to, err := time.Parse(time.RFC3339, toStr)
+	respondJSON(w, http.StatusOK, analytics)
+}
+
+func (h *Handler) GetGroupedAnalytics(w http.ResponseWriter, r *http.Request) {
+	query, err := parseAnalyticsQuery(r)
 	if err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid 'to' date format")
+		respondDomainError(w, err)
 		return
 	}
 
-	analytics, err := h.useCases.GetAnalytics(r.Context(), from, to)
+	query.GroupBy, err = domain.ParseGroupBy(r.URL.Query().Get("group_by"))
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
+		respondDomainError(w, err)
 		return
 	}
 
-	respondJSON(w, http.StatusOK, analytics)
+	format, err := parseFormat(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var cw *csv.Writer
+	if format == formatCSV {
+		if cw, err = newCSVWriter(w, r); err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	groups, err := h.useCases.GetGroupedAnalytics(r.Context(), query)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+	if format == formatCSV {
+		writeGroupsCSV(w, cw, query, groups)
+		return
+	}
+	if groups == nil {
+		groups = []*domain.AnalyticsGroup{}
+	}
+
+	respondJSON(w, http.StatusOK, groups)
+}
+
+func (h *Handler) GetTimeSeries(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	interval := r.URL.Query().Get("interval")
+	if interval == "" {
+		interval = domain.IntervalDay
+	}
+	if err := domain.ValidateInterval(interval); err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	loc := time.UTC
+	if tz := r.URL.Query().Get("tz"); tz != "" {
+		loc, err = time.LoadLocation(tz)
+		if err != nil || tz == "Local" {
+			respondError(w, http.StatusBadRequest, "Invalid 'tz' time zone")
+			return
+		}
+	}
+
+	points, err := h.useCases.GetTimeSeries(r.Context(), domain.TimeSeriesQuery{
+		From:     from,
+		To:       to,
+		Interval: interval,
+		Location: loc,
+		Currency: r.URL.Query().Get("currency"),
+	})
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+	if points == nil {
+		points = []*domain.TimeSeriesPoint{}
+	}
+
+	respondJSON(w, http.StatusOK, points)
+}
+
+// parseItemFilter разбирает фильтры, сортировку и параметры страницы списка записей
+func parseItemFilter(r *http.Request) (domain.ItemFilter, error) {
+	filter, err := parseItemConditions(r)
+	if err != nil {
+		return filter, err
+	}
+	values := r.URL.Query()
+
+	if limitStr := values.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			return filter, domain.NewValidationError("limit", "Invalid 'limit' value")
+		}
+		filter.Limit = limit
+	}
+
+	filter.Cursor = values.Get("cursor")
+
+	filter.Normalize()
+	return filter, filter.Validate()
+}
+
+// parseItemConditions разбирает фильтры и сортировку записей без параметров страницы.
+// Параметры type и category можно передавать несколько раз, type — также через запятую.
+// Параметр q включает полнотекстовый поиск по категории и описанию.
+func parseItemConditions(r *http.Request) (domain.ItemFilter, error) {
+	values := r.URL.Query()
+	var filter domain.ItemFilter
+
+	if fromStr := values.Get("from"); fromStr != "" {
+		t, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			return filter, domain.NewValidationError("from", "Invalid 'from' date format")
+		}
+		filter.From = &t
+	}
+
+	if toStr := values.Get("to"); toStr != "" {
+		t, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			return filter, domain.NewValidationError("to", "Invalid 'to' date format")
+		}
+		filter.To = &t
+	}
+
+	for _, value := range values["type"] {
+		for _, t := range strings.Split(value, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				filter.Types = append(filter.Types, t)
+			}
+		}
+	}
+
+	for _, category := range values["category"] {
+		if category != "" {
+			filter.Categories = append(filter.Categories, category)
+		}
+	}
+
+	var err error
+	if filter.MinAmount, err = parseAmountParam(r, "amount_min"); err != nil {
+		return filter, err
+	}
+	if filter.MaxAmount, err = parseAmountParam(r, "amount_max"); err != nil {
+		return filter, err
+	}
+
+	sort, err := domain.ParseItemSort(values.Get("sort"))
+	if err != nil {
+		return filter, err
+	}
+	filter.Sort = sort
+	filter.Query = values.Get("q")
+	return filter, nil
+}
+
+// parseAmountParam разбирает необязательный параметр с суммой
+func parseAmountParam(r *http.Request, name string) (*domain.Money, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	amount, err := domain.ParseMoney(value)
+	if err != nil {
+		return nil, domain.NewValidationError(name, fmt.Sprintf("Invalid '%s' amount", name))
+	}
+	return &amount, nil
+}
+
+// parseAnalyticsQuery разбирает общие параметры аналитики: период, перцентили и валюту
+func parseAnalyticsQuery(r *http.Request) (domain.AnalyticsQuery, error) {
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		return domain.AnalyticsQuery{}, err
+	}
+
+	percentiles, err := domain.ParsePercentiles(r.URL.Query().Get("percentiles"))
+	if err != nil {
+		return domain.AnalyticsQuery{}, err
+	}
+
+	return domain.AnalyticsQuery{
+		From:        from,
+		To:          to,
+		Percentiles: percentiles,
+		Currency:    r.URL.Query().Get("currency"),
+	}, nil
+}
+
+// parseDateRange разбирает обязательные параметры периода 'from' и 'to'
+func parseDateRange(r *http.Request) (time.Time, time.Time, error) {
+	fromStr := r.URL.Query().Get("from")
+	toStr := r.URL.Query().Get("to")
+
+	if fromStr == "" || toStr == "" {
+		return time.Time{}, time.Time{}, domain.NewValidationError("from", "Both 'from' and 'to' parameters are required")
+	}
+
+	from, err := time.Parse(time.RFC3339, fromStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, domain.NewValidationError("from", "Invalid 'from' date format")
+	}
+
+	to, err := time.Parse(time.RFC3339, toStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, domain.NewValidationError("to", "Invalid 'to' date format")
+	}
+
+	return from, to, nil
 }
 
 func respondJSON(w http.ResponseWriter, status int, data interface{}) {
@@ -155,7 +387,3 @@ func respondJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(data)
 }
-
-func respondError(w http.ResponseWriter, status int, message string) {
-	respondJSON(w, status, map[string]string{"error": message})
-}
@@ -8,6 +8,7 @@ import (
 	"github.com/dontpanicw/SalesTracker/internal/domain"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -15,12 +16,25 @@ import (
 )
 
 type mockUseCases struct {
-	createItemFunc   func(ctx context.Context, item *domain.Item) error
-	getItemFunc      func(ctx context.Context, id int64) (*domain.Item, error)
-	getItemsFunc     func(ctx context.Context, from, to *time.Time) ([]*domain.Item, error)
-	updateItemFunc   func(ctx context.Context, item *domain.Item) error
-	deleteItemFunc   func(ctx context.Context, id int64) error
-	getAnalyticsFunc func(ctx context.Context, from, to time.Time) (*domain.Analytics, error)
+	createItemFunc    func(ctx context.Context, item *domain.Item) error
+	createOnceFunc    func(ctx context.Context, key string, item *domain.Item) (bool, error)
+	importItemsFunc   func(ctx context.Context, rows []domain.ImportRow, dryRun bool) (*domain.ImportReport, error)
+	getItemFunc       func(ctx context.Context, id int64) (*domain.Item, error)
+	getItemsFunc      func(ctx context.Context, filter domain.ItemFilter) (*domain.ItemPage, error)
+	exportItemsFunc   func(ctx context.Context, filter domain.ItemFilter, fn func(*domain.Item) error) error
+	updateItemFunc    func(ctx context.Context, item *domain.Item) error
+	deleteItemFunc    func(ctx context.Context, id int64, version int64) error
+	restoreItemFunc   func(ctx context.Context, id int64, version int64) (*domain.Item, error)
+	purgeTrashFunc    func(ctx context.Context) (int64, error)
+	applyBatchFunc    func(ctx context.Context, ops []domain.BatchOperation, partial bool) (*domain.BatchReport, error)
+	getHistoryFunc    func(ctx context.Context, filter domain.HistoryFilter) (*domain.HistoryPage, error)
+	getAnalyticsFunc  func(ctx context.Context, query domain.AnalyticsQuery) (*domain.AnalyticsReport, error)
+	getGroupedFunc    func(ctx context.Context, query domain.AnalyticsQuery) ([]*domain.AnalyticsGroup, error)
+	getTimeSeriesFunc func(ctx context.Context, query domain.TimeSeriesQuery) ([]*domain.TimeSeriesPoint, error)
+	createRateFunc    func(ctx context.Context, rate *domain.ExchangeRate) error
+	getRatesFunc      func(ctx context.Context, from, to string) ([]*domain.ExchangeRate, error)
+	deleteRateFunc    func(ctx context.Context, id int64) error
+	getPoolStatsFunc  func(ctx context.Context) (*domain.PoolStats, error)
 }
 
 func (m *mockUseCases) CreateItem(ctx context.Context, item *domain.Item) error {
@@ -30,6 +44,20 @@ func (m *mockUseCases) CreateItem(ctx context.Context, item *domain.Item) error
 	return nil
 }
 
+func (m *mockUseCases) CreateItemIdempotent(ctx context.Context, key string, item *domain.Item) (bool, error) {
+	if m.createOnceFunc != nil {
+		return m.createOnceFunc(ctx, key, item)
+	}
+	return false, nil
+}
+
+func (m *mockUseCases) ImportItems(ctx context.Context, rows []domain.ImportRow, dryRun bool) (*domain.ImportReport, error) {
+	if m.importItemsFunc != nil {
+		return m.importItemsFunc(ctx, rows, dryRun)
+	}
+	return &domain.ImportReport{}, nil
+}
+
 func (m *mockUseCases) GetItem(ctx context.Context, id int64) (*domain.Item, error) {
 	if m.getItemFunc != nil {
 		return m.getItemFunc(ctx, id)
@@ -37,11 +65,18 @@ func (m *mockUseCases) GetItem(ctx context.Context, id int64) (*domain.Item, err
 	return nil, nil
 }
 
-func (m *mockUseCases) GetItems(ctx context.Context, from, to *time.Time) ([]*domain.Item, error) {
+func (m *mockUseCases) GetItems(ctx context.Context, filter domain.ItemFilter) (*domain.ItemPage, error) {
 	if m.getItemsFunc != nil {
-		return m.getItemsFunc(ctx, from, to)
+		return m.getItemsFunc(ctx, filter)
 	}
-	return nil, nil
+	return &domain.ItemPage{}, nil
+}
+
+func (m *mockUseCases) ExportItems(ctx context.Context, filter domain.ItemFilter, fn func(*domain.Item) error) error {
+	if m.exportItemsFunc != nil {
+		return m.exportItemsFunc(ctx, filter, fn)
+	}
+	return nil
 }
 
 func (m *mockUseCases) UpdateItem(ctx context.Context, item *domain.Item) error {
@@ -51,20 +86,90 @@ func (m *mockUseCases) UpdateItem(ctx context.Context, item *domain.Item) error
 	return nil
 }
 
-func (m *mockUseCases) DeleteItem(ctx context.Context, id int64) error {
+func (m *mockUseCases) DeleteItem(ctx context.Context, id int64, version int64) error {
 	if m.deleteItemFunc != nil {
-		return m.deleteItemFunc(ctx, id)
+		return m.deleteItemFunc(ctx, id, version)
 	}
 	return nil
 }
 
-func (m *mockUseCases) GetAnalytics(ctx context.Context, from, to time.Time) (*domain.Analytics, error) {
+func (m *mockUseCases) RestoreItem(ctx context.Context, id int64, version int64) (*domain.Item, error) {
+	if m.restoreItemFunc != nil {
+		return m.restoreItemFunc(ctx, id, version)
+	}
+	return nil, nil
+}
+
+func (m *mockUseCases) PurgeTrash(ctx context.Context) (int64, error) {
+	if m.purgeTrashFunc != nil {
+		return m.purgeTrashFunc(ctx)
+	}
+	return 0, nil
+}
+
+func (m *mockUseCases) ApplyBatch(ctx context.Context, ops []domain.BatchOperation, partial bool) (*domain.BatchReport, error) {
+	if m.applyBatchFunc != nil {
+		return m.applyBatchFunc(ctx, ops, partial)
+	}
+	return &domain.BatchReport{}, nil
+}
+
+func (m *mockUseCases) GetHistory(ctx context.Context, filter domain.HistoryFilter) (*domain.HistoryPage, error) {
+	if m.getHistoryFunc != nil {
+		return m.getHistoryFunc(ctx, filter)
+	}
+	return &domain.HistoryPage{}, nil
+}
+
+func (m *mockUseCases) GetAnalytics(ctx context.Context, query domain.AnalyticsQuery) (*domain.AnalyticsReport, error) {
 	if m.getAnalyticsFunc != nil {
-		return m.getAnalyticsFunc(ctx, from, to)
+		return m.getAnalyticsFunc(ctx, query)
+	}
+	return nil, nil
+}
+
+func (m *mockUseCases) GetGroupedAnalytics(ctx context.Context, query domain.AnalyticsQuery) ([]*domain.AnalyticsGroup, error) {
+	if m.getGroupedFunc != nil {
+		return m.getGroupedFunc(ctx, query)
+	}
+	return nil, nil
+}
+
+func (m *mockUseCases) GetTimeSeries(ctx context.Context, query domain.TimeSeriesQuery) ([]*domain.TimeSeriesPoint, error) {
+	if m.getTimeSeriesFunc != nil {
+		return m.getTimeSeriesFunc(ctx, query)
 	}
 	return nil, nil
 }
 
+func (m *mockUseCases) CreateRate(ctx context.Context, rate *domain.ExchangeRate) error {
+	if m.createRateFunc != nil {
+		return m.createRateFunc(ctx, rate)
+	}
+	return nil
+}
+
+func (m *mockUseCases) GetRates(ctx context.Context, from, to string) ([]*domain.ExchangeRate, error) {
+	if m.getRatesFunc != nil {
+		return m.getRatesFunc(ctx, from, to)
+	}
+	return nil, nil
+}
+
+func (m *mockUseCases) DeleteRate(ctx context.Context, id int64) error {
+	if m.deleteRateFunc != nil {
+		return m.deleteRateFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *mockUseCases) GetPoolStats(ctx context.Context) (*domain.PoolStats, error) {
+	if m.getPoolStatsFunc != nil {
+		return m.getPoolStatsFunc(ctx)
+	}
+	return &domain.PoolStats{}, nil
+}
+
 func TestHandler_CreateItem(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -76,7 +181,7 @@ func TestHandler_CreateItem(t *testing.T) {
 			name: "successful creation",
 			body: domain.Item{
 				Type:     "income",
-				Amount:   1000.00,
+				Amount:   domain.NewMoney(1000, 0),
 				Category: "Salary",
 				Date:     time.Now(),
 			},
@@ -94,21 +199,48 @@ func TestHandler_CreateItem(t *testing.T) {
 			mock:       &mockUseCases{},
 			wantStatus: http.StatusBadRequest,
 		},
+		{
+			name: "amount with more than two decimals",
+			body: map[string]interface{}{
+				"type":     "income",
+				"amount":   "10.005",
+				"category": "Salary",
+				"date":     time.Now(),
+			},
+			mock:       &mockUseCases{},
+			wantStatus: http.StatusBadRequest,
+		},
 		{
 			name: "validation error",
 			body: domain.Item{
 				Type:     "invalid",
-				Amount:   1000.00,
+				Amount:   domain.NewMoney(1000, 0),
 				Category: "Salary",
 				Date:     time.Now(),
 			},
 			mock: &mockUseCases{
 				createItemFunc: func(ctx context.Context, item *domain.Item) error {
-					return errors.New("validation error")
+					return domain.NewValidationError("type", "type must be either 'income' or 'expense'")
 				},
 			},
 			wantStatus: http.StatusBadRequest,
 		},
+		{
+			name: "item validation errors",
+			body: domain.Item{
+				Type:     "income",
+				Amount:   domain.NewMoney(-10, 0),
+				Category: "",
+				Date:     time.Now(),
+			},
+			mock: &mockUseCases{
+				createItemFunc: func(ctx context.Context, item *domain.Item) error {
+					item.Normalize()
+					return item.Validate()
+				},
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
 	}
 
 	for _, tt := range tests {
@@ -129,6 +261,56 @@ func TestHandler_CreateItem(t *testing.T) {
 	}
 }
 
+func TestHandler_CreateItem_IdempotencyKey(t *testing.T) {
+	tests := []struct {
+		name         string
+		key          string
+		err          error
+		replayed     bool
+		wantStatus   int
+		wantReplayed string
+	}{
+		{name: "first request", key: "key-1", wantStatus: http.StatusCreated},
+		{name: "repeated request", key: "key-1", replayed: true, wantStatus: http.StatusCreated, wantReplayed: "true"},
+		{name: "key reused with different body", key: "key-1", err: domain.ErrIdempotencyKeyReused, wantStatus: http.StatusUnprocessableEntity},
+		{name: "first request in progress", key: "key-1", err: domain.ErrIdempotencyKeyInProgress, wantStatus: http.StatusConflict},
+		{name: "key too long", key: strings.Repeat("k", domain.MaxIdempotencyKeyLength+1), wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotKey string
+			handler := NewHandler(&mockUseCases{
+				createItemFunc: func(ctx context.Context, item *domain.Item) error {
+					t.Error("CreateItem() called for request with Idempotency-Key")
+					return nil
+				},
+				createOnceFunc: func(ctx context.Context, key string, item *domain.Item) (bool, error) {
+					gotKey = key
+					return tt.replayed, tt.err
+				},
+			})
+
+			body := `{"type": "income", "amount": 100, "category": "Salary", "date": "2024-01-15T00:00:00Z"}`
+			req := httptest.NewRequest("POST", "/api/items", strings.NewReader(body))
+			req.Header.Set("Idempotency-Key", tt.key)
+			w := httptest.NewRecorder()
+
+			handler.CreateItem(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("CreateItem() status = %v, want %v, body %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if got := w.Header().Get("Idempotent-Replayed"); got != tt.wantReplayed {
+				t.Errorf("CreateItem() Idempotent-Replayed = %q, want %q", got, tt.wantReplayed)
+			}
+			if tt.wantStatus != http.StatusBadRequest && gotKey != tt.key {
+				t.Errorf("CreateItemIdempotent() key = %q, want %q", gotKey, tt.key)
+			}
+		})
+	}
+}
+
 func TestHandler_GetItems(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -140,9 +322,12 @@ func TestHandler_GetItems(t *testing.T) {
 			name:  "successful get all",
 			query: "",
 			mock: &mockUseCases{
-				getItemsFunc: func(ctx context.Context, from, to *time.Time) ([]*domain.Item, error) {
-					return []*domain.Item{
-						{ID: 1, Type: "income", Amount: 1000.00, Category: "Salary", Date: time.Now()},
+				getItemsFunc: func(ctx context.Context, filter domain.ItemFilter) (*domain.ItemPage, error) {
+					return &domain.ItemPage{
+						Items: []*domain.Item{
+							{ID: 1, Type: "income", Amount: domain.NewMoney(1000, 0), Category: "Salary", Date: time.Now()},
+						},
+						Total: 1,
 					}, nil
 				},
 			},
@@ -152,18 +337,77 @@ func TestHandler_GetItems(t *testing.T) {
 			name:  "with date filters",
 			query: "?from=2024-01-01T00:00:00Z&to=2024-12-31T23:59:59Z",
 			mock: &mockUseCases{
-				getItemsFunc: func(ctx context.Context, from, to *time.Time) ([]*domain.Item, error) {
-					return []*domain.Item{}, nil
+				getItemsFunc: func(ctx context.Context, filter domain.ItemFilter) (*domain.ItemPage, error) {
+					return &domain.ItemPage{}, nil
+				},
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:  "with filters, sort and limit",
+			query: "?type=income,expense&category=Food&category=Salary&amount_min=10&amount_max=99.50&sort=-amount&limit=20",
+			mock: &mockUseCases{
+				getItemsFunc: func(ctx context.Context, filter domain.ItemFilter) (*domain.ItemPage, error) {
+					if len(filter.Types) != 2 || len(filter.Categories) != 2 {
+						return nil, errors.New("multi-value filters not parsed")
+					}
+					if *filter.MaxAmount != domain.NewMoney(99, 50) || filter.Sort.String() != "-amount" || filter.Limit != 20 {
+						return nil, errors.New("filter not parsed")
+					}
+					return &domain.ItemPage{}, nil
+				},
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:  "full-text search",
+			query: "?q=%D0%BA%D0%BE%D1%84%D0%B5",
+			mock: &mockUseCases{
+				getItemsFunc: func(ctx context.Context, filter domain.ItemFilter) (*domain.ItemPage, error) {
+					if filter.Query != "кофе" || filter.Sort != domain.DefaultSearchSort {
+						return nil, errors.New("search not parsed")
+					}
+					return &domain.ItemPage{}, nil
 				},
 			},
 			wantStatus: http.StatusOK,
 		},
+		{
+			name:       "sort by rank without query",
+			query:      "?sort=-rank",
+			mock:       &mockUseCases{},
+			wantStatus: http.StatusBadRequest,
+		},
 		{
 			name:       "invalid date format",
 			query:      "?from=invalid",
 			mock:       &mockUseCases{},
 			wantStatus: http.StatusBadRequest,
 		},
+		{
+			name:       "invalid sort",
+			query:      "?sort=id",
+			mock:       &mockUseCases{},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "limit too large",
+			query:      "?limit=5000",
+			mock:       &mockUseCases{},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid amount",
+			query:      "?amount_min=abc",
+			mock:       &mockUseCases{},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid cursor",
+			query:      "?cursor=broken",
+			mock:       &mockUseCases{},
+			wantStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
@@ -194,7 +438,7 @@ func TestHandler_GetItem(t *testing.T) {
 			id:   "1",
 			mock: &mockUseCases{
 				getItemFunc: func(ctx context.Context, id int64) (*domain.Item, error) {
-					return &domain.Item{ID: 1, Type: "income", Amount: 1000.00}, nil
+					return &domain.Item{ID: 1, Type: "income", Amount: domain.NewMoney(1000, 0)}, nil
 				},
 			},
 			wantStatus: http.StatusOK,
@@ -210,11 +454,31 @@ func TestHandler_GetItem(t *testing.T) {
 			id:   "999",
 			mock: &mockUseCases{
 				getItemFunc: func(ctx context.Context, id int64) (*domain.Item, error) {
-					return nil, errors.New("not found")
+					return nil, domain.NewNotFoundError("item not found")
 				},
 			},
 			wantStatus: http.StatusNotFound,
 		},
+		{
+			name: "database error",
+			id:   "1",
+			mock: &mockUseCases{
+				getItemFunc: func(ctx context.Context, id int64) (*domain.Item, error) {
+					return nil, errors.New("pq: relation \"items\" does not exist")
+				},
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name: "database unavailable",
+			id:   "1",
+			mock: &mockUseCases{
+				getItemFunc: func(ctx context.Context, id int64) (*domain.Item, error) {
+					return nil, domain.NewUnavailableError(errors.New("dial tcp: connection refused"))
+				},
+			},
+			wantStatus: http.StatusServiceUnavailable,
+		},
 	}
 
 	for _, tt := range tests {
@@ -235,9 +499,20 @@ func TestHandler_GetItem(t *testing.T) {
 }
 
 func TestHandler_DeleteItem(t *testing.T) {
+	// deleteVersion5 удаляет запись 1, только если ее версия 5 или версия не проверяется
+	deleteVersion5 := &mockUseCases{
+		deleteItemFunc: func(ctx context.Context, id int64, version int64) error {
+			if version != 0 && version != 5 {
+				return domain.ErrVersionMismatch
+			}
+			return nil
+		},
+	}
+
 	tests := []struct {
 		name       string
 		id         string
+		ifMatch    string
 		mock       *mockUseCases
 		wantStatus int
 	}{
@@ -245,7 +520,7 @@ func TestHandler_DeleteItem(t *testing.T) {
 			name: "successful deletion",
 			id:   "1",
 			mock: &mockUseCases{
-				deleteItemFunc: func(ctx context.Context, id int64) error {
+				deleteItemFunc: func(ctx context.Context, id int64, version int64) error {
 					return nil
 				},
 			},
@@ -261,12 +536,47 @@ func TestHandler_DeleteItem(t *testing.T) {
 			name: "item not found",
 			id:   "999",
 			mock: &mockUseCases{
-				deleteItemFunc: func(ctx context.Context, id int64) error {
-					return errors.New("not found")
+				deleteItemFunc: func(ctx context.Context, id int64, version int64) error {
+					return domain.NewNotFoundError("item not found")
 				},
 			},
 			wantStatus: http.StatusNotFound,
 		},
+		{
+			name:       "matching If-Match",
+			id:         "1",
+			ifMatch:    `"5"`,
+			mock:       deleteVersion5,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "stale If-Match",
+			id:         "1",
+			ifMatch:    `"4"`,
+			mock:       deleteVersion5,
+			wantStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:       "weak ETag never matches",
+			id:         "1",
+			ifMatch:    `W/"5"`,
+			mock:       deleteVersion5,
+			wantStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:       "If-Match any",
+			id:         "1",
+			ifMatch:    "*",
+			mock:       deleteVersion5,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "several ETags",
+			id:         "1",
+			ifMatch:    `"4", "5"`,
+			mock:       deleteVersion5,
+			wantStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
@@ -274,6 +584,9 @@ func TestHandler_DeleteItem(t *testing.T) {
 			handler := NewHandler(tt.mock)
 
 			req := httptest.NewRequest("DELETE", "/api/items/"+tt.id, nil)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
 			req = mux.SetURLVars(req, map[string]string{"id": tt.id})
 			w := httptest.NewRecorder()
 
@@ -297,13 +610,11 @@ func TestHandler_GetAnalytics(t *testing.T) {
 			name:  "successful analytics",
 			query: "?from=2024-01-01T00:00:00Z&to=2024-12-31T23:59:59Z",
 			mock: &mockUseCases{
-				getAnalyticsFunc: func(ctx context.Context, from, to time.Time) (*domain.Analytics, error) {
-					return &domain.Analytics{
-						Sum:        10000.00,
-						Avg:        1000.00,
-						Count:      10,
-						Median:     950.00,
-						Percentile: 1500.00,
+				getAnalyticsFunc: func(ctx context.Context, query domain.AnalyticsQuery) (*domain.AnalyticsReport, error) {
+					return &domain.AnalyticsReport{
+						Income:  domain.Analytics{Sum: domain.NewMoney(10000, 0), Avg: domain.NewMoney(1000, 0), Count: 10, Median: domain.NewMoney(950, 0), Percentile: domain.NewMoney(1500, 0)},
+						Expense: domain.Analytics{Sum: domain.NewMoney(4000, 0), Avg: domain.NewMoney(800, 0), Count: 5, Median: domain.NewMoney(700, 0), Percentile: domain.NewMoney(1200, 0)},
+						Balance: domain.NewMoney(6000, 0),
 					}, nil
 				},
 			},
@@ -321,6 +632,44 @@ func TestHandler_GetAnalytics(t *testing.T) {
 			mock:       &mockUseCases{},
 			wantStatus: http.StatusBadRequest,
 		},
+		{
+			name:       "group_by not supported",
+			query:      "?from=2024-01-01T00:00:00Z&to=2024-12-31T23:59:59Z&group_by=category",
+			mock:       &mockUseCases{},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "custom percentiles",
+			query: "?from=2024-01-01T00:00:00Z&to=2024-12-31T23:59:59Z&percentiles=0.25,0.5,0.99",
+			mock: &mockUseCases{
+				getAnalyticsFunc: func(ctx context.Context, query domain.AnalyticsQuery) (*domain.AnalyticsReport, error) {
+					if len(query.Percentiles) != 3 || query.Percentiles[2] != 0.99 {
+						t.Errorf("GetAnalytics() percentiles = %v", query.Percentiles)
+					}
+					return &domain.AnalyticsReport{}, nil
+				},
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:  "reporting currency",
+			query: "?from=2024-01-01T00:00:00Z&to=2024-12-31T23:59:59Z&currency=USD",
+			mock: &mockUseCases{
+				getAnalyticsFunc: func(ctx context.Context, query domain.AnalyticsQuery) (*domain.AnalyticsReport, error) {
+					if query.Currency != "USD" {
+						t.Errorf("GetAnalytics() currency = %v, want USD", query.Currency)
+					}
+					return &domain.AnalyticsReport{Currency: "USD"}, nil
+				},
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid percentile",
+			query:      "?from=2024-01-01T00:00:00Z&to=2024-12-31T23:59:59Z&percentiles=0.5,99",
+			mock:       &mockUseCases{},
+			wantStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
@@ -338,3 +687,135 @@ func TestHandler_GetAnalytics(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_GetGroupedAnalytics(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		mock       *mockUseCases
+		wantStatus int
+	}{
+		{
+			name:  "successful grouping",
+			query: "?from=2024-01-01T00:00:00Z&to=2024-12-31T23:59:59Z&group_by=category,month",
+			mock: &mockUseCases{
+				getGroupedFunc: func(ctx context.Context, query domain.AnalyticsQuery) ([]*domain.AnalyticsGroup, error) {
+					if len(query.GroupBy) != 2 || query.GroupBy[0] != "category" || query.GroupBy[1] != "month" {
+						t.Errorf("GetGroupedAnalytics() groupBy = %v", query.GroupBy)
+					}
+					category := "Food"
+					return []*domain.AnalyticsGroup{
+						{Category: &category, Analytics: domain.Analytics{Sum: domain.NewMoney(500, 0), Count: 2}},
+					}, nil
+				},
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "missing group_by",
+			query:      "?from=2024-01-01T00:00:00Z&to=2024-12-31T23:59:59Z",
+			mock:       &mockUseCases{},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown group_by field",
+			query:      "?from=2024-01-01T00:00:00Z&to=2024-12-31T23:59:59Z&group_by=amount",
+			mock:       &mockUseCases{},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "missing parameters",
+			query:      "?group_by=type",
+			mock:       &mockUseCases{},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHandler(tt.mock)
+
+			req := httptest.NewRequest("GET", "/api/analytics/groups"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.GetGroupedAnalytics(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("GetGroupedAnalytics() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHandler_GetTimeSeries(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		mock       *mockUseCases
+		wantStatus int
+	}{
+		{
+			name:  "successful time series",
+			query: "?from=2024-01-01T00:00:00Z&to=2024-01-31T23:59:59Z&interval=week&tz=Europe/Moscow",
+			mock: &mockUseCases{
+				getTimeSeriesFunc: func(ctx context.Context, query domain.TimeSeriesQuery) ([]*domain.TimeSeriesPoint, error) {
+					if query.Interval != "week" || query.Location.String() != "Europe/Moscow" {
+						t.Errorf("GetTimeSeries() interval = %v, loc = %v", query.Interval, query.Location)
+					}
+					return []*domain.TimeSeriesPoint{}, nil
+				},
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:  "default interval and time zone",
+			query: "?from=2024-01-01T00:00:00Z&to=2024-01-31T23:59:59Z",
+			mock: &mockUseCases{
+				getTimeSeriesFunc: func(ctx context.Context, query domain.TimeSeriesQuery) ([]*domain.TimeSeriesPoint, error) {
+					if query.Interval != "day" || query.Location != time.UTC {
+						t.Errorf("GetTimeSeries() interval = %v, loc = %v", query.Interval, query.Location)
+					}
+					return nil, nil
+				},
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid interval",
+			query:      "?from=2024-01-01T00:00:00Z&to=2024-01-31T23:59:59Z&interval=hour",
+			mock:       &mockUseCases{},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid time zone",
+			query:      "?from=2024-01-01T00:00:00Z&to=2024-01-31T23:59:59Z&tz=Mars/Olympus",
+			mock:       &mockUseCases{},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "too many points",
+			query: "?from=0001-01-01T00:00:00Z&to=9999-12-31T23:59:59Z&interval=day",
+			mock: &mockUseCases{
+				getTimeSeriesFunc: func(ctx context.Context, query domain.TimeSeriesQuery) ([]*domain.TimeSeriesPoint, error) {
+					return nil, domain.NewValidationError("interval", "time series would have too many points")
+				},
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHandler(tt.mock)
+
+			req := httptest.NewRequest("GET", "/api/analytics/timeseries"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.GetTimeSeries(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("GetTimeSeries() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package http
+
+import (
+	"fmt"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// actorMiddleware передает в контекст запроса автора изменений из заголовка X-Actor
+func actorMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor := strings.TrimSpace(r.Header.Get("X-Actor"))
+		if len(actor) > domain.MaxActorLength {
+			respondError(w, http.StatusBadRequest, fmt.Sprintf("X-Actor must not be longer than %d characters", domain.MaxActorLength))
+			return
+		}
+		if actor != "" {
+			r = r.WithContext(domain.WithActor(r.Context(), actor))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// GetItemHistory возвращает историю изменений одной записи, включая удаленные
+func (h *Handler) GetItemHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	filter, err := parseHistoryFilter(r)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+	filter.ItemID = id
+
+	h.respondHistory(w, r, filter)
+}
+
+// GetAudit возвращает историю изменений всех записей с фильтром по автору и периоду
+func (h *Handler) GetAudit(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseHistoryFilter(r)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	if itemStr := r.URL.Query().Get("item_id"); itemStr != "" {
+		id, err := strconv.ParseInt(itemStr, 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid 'item_id' value")
+			return
+		}
+		filter.ItemID = id
+	}
+
+	h.respondHistory(w, r, filter)
+}
+
+func (h *Handler) respondHistory(w http.ResponseWriter, r *http.Request, filter domain.HistoryFilter) {
+	page, err := h.useCases.GetHistory(r.Context(), filter)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+	if page.Entries == nil {
+		page.Entries = []*domain.HistoryEntry{}
+	}
+
+	respondJSON(w, http.StatusOK, page)
+}
+
+// parseHistoryFilter разбирает параметры истории: actor, from, to, limit и cursor
+func parseHistoryFilter(r *http.Request) (domain.HistoryFilter, error) {
+	values := r.URL.Query()
+	filter := domain.HistoryFilter{Actor: values.Get("actor"), Cursor: values.Get("cursor")}
+
+	if fromStr := values.Get("from"); fromStr != "" {
+		t, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			return filter, domain.NewValidationError("from", "Invalid 'from' date format")
+		}
+		filter.From = &t
+	}
+
+	if toStr := values.Get("to"); toStr != "" {
+		t, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			return filter, domain.NewValidationError("to", "Invalid 'to' date format")
+		}
+		filter.To = &t
+	}
+
+	if limitStr := values.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			return filter, domain.NewValidationError("limit", "Invalid 'limit' value")
+		}
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
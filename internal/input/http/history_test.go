@@ -0,0 +1,129 @@
+package http
+
+import (
+	"context"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestActorMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     string
+		wantActor  string
+		wantStatus int
+	}{
+		{name: "actor from header", header: " alice ", wantActor: "alice", wantStatus: http.StatusOK},
+		{name: "anonymous without header", wantActor: domain.AnonymousActor, wantStatus: http.StatusOK},
+		{name: "too long actor", header: strings.Repeat("a", domain.MaxActorLength+1), wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var actor string
+			handler := actorMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				actor = domain.ActorFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest("POST", "/api/items", nil)
+			if tt.header != "" {
+				req.Header.Set("X-Actor", tt.header)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("actorMiddleware() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+			if actor != tt.wantActor {
+				t.Errorf("actorMiddleware() actor = %q, want %q", actor, tt.wantActor)
+			}
+		})
+	}
+}
+
+func TestHandler_GetItemHistory(t *testing.T) {
+	var got domain.HistoryFilter
+	handler := NewHandler(&mockUseCases{
+		getHistoryFunc: func(ctx context.Context, filter domain.HistoryFilter) (*domain.HistoryPage, error) {
+			got = filter
+			return &domain.HistoryPage{}, nil
+		},
+	})
+
+	tests := []struct {
+		name       string
+		id         string
+		query      string
+		wantStatus int
+		wantFilter domain.HistoryFilter
+	}{
+		{
+			name:       "history with paging",
+			id:         "7",
+			query:      "?limit=10&cursor=42",
+			wantStatus: http.StatusOK,
+			wantFilter: domain.HistoryFilter{ItemID: 7, Limit: 10, Cursor: "42"},
+		},
+		{name: "invalid id", id: "abc", wantStatus: http.StatusBadRequest},
+		{name: "invalid limit", id: "7", query: "?limit=ten", wantStatus: http.StatusBadRequest},
+		{name: "invalid date", id: "7", query: "?from=2024-01-01", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = domain.HistoryFilter{}
+			req := httptest.NewRequest("GET", "/api/items/"+tt.id+"/history"+tt.query, nil)
+			req = mux.SetURLVars(req, map[string]string{"id": tt.id})
+			w := httptest.NewRecorder()
+
+			handler.GetItemHistory(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("GetItemHistory() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+			if w.Code == http.StatusOK && (got.ItemID != tt.wantFilter.ItemID || got.Limit != tt.wantFilter.Limit || got.Cursor != tt.wantFilter.Cursor) {
+				t.Errorf("GetItemHistory() filter = %+v, want %+v", got, tt.wantFilter)
+			}
+		})
+	}
+}
+
+func TestHandler_GetAudit(t *testing.T) {
+	var got domain.HistoryFilter
+	handler := NewHandler(&mockUseCases{
+		getHistoryFunc: func(ctx context.Context, filter domain.HistoryFilter) (*domain.HistoryPage, error) {
+			got = filter
+			if filter.Limit > domain.MaxPageLimit {
+				return nil, domain.NewValidationError("limit", "limit is too large")
+			}
+			return &domain.HistoryPage{Entries: []*domain.HistoryEntry{{ID: 1, ItemID: 3, Action: domain.HistoryCreate}}}, nil
+		},
+	})
+
+	req := httptest.NewRequest("GET", "/api/audit?actor=alice&from=2024-01-01T00:00:00Z&to=2024-02-01T00:00:00Z&item_id=3", nil)
+	w := httptest.NewRecorder()
+	handler.GetAudit(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("GetAudit() status = %v, want %v", w.Code, http.StatusOK)
+	}
+	if got.Actor != "alice" || got.ItemID != 3 || got.From == nil || got.To == nil {
+		t.Errorf("GetAudit() filter = %+v, want actor, item and period", got)
+	}
+	if !strings.Contains(w.Body.String(), `"action":"create"`) {
+		t.Errorf("GetAudit() body = %s, want history entries", w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/api/audit?limit=5000", nil)
+	w = httptest.NewRecorder()
+	handler.GetAudit(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("GetAudit() status = %v, want %v for too large limit", w.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"github.com/dontpanicw/SalesTracker/internal/input/itemfile"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// maxImportSize — максимальный размер загружаемого файла импорта
+const maxImportSize = 10 << 20
+
+// ImportItems загружает записи из CSV-файла (multipart, поле file).
+// Колонки сопоставляются полям записи параметрами type, amount, currency, category,
+// description, date: имя колонки из заголовка или ее номер, начиная с 1.
+// По умолчанию колонки ищутся по именам полей. В ответе — отчет с ошибками по строкам.
+func (h *Handler) ImportItems(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	if err := r.ParseMultipartForm(maxImportSize); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid multipart form")
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "File is required")
+		return
+	}
+	defer file.Close()
+
+	dryRun, err := parseBoolValue(r.FormValue("dry_run"), false)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid 'dry_run' value")
+		return
+	}
+	hasHeader, err := parseBoolValue(r.FormValue("header"), true)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid 'header' value")
+		return
+	}
+	delimiter, err := itemfile.ParseDelimiter(r.FormValue("delimiter"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	mapping := make(map[string]string, len(itemfile.Fields))
+	for _, field := range itemfile.Fields {
+		mapping[field] = strings.TrimSpace(r.FormValue(field))
+	}
+
+	rows, err := itemfile.ReadCSV(file, itemfile.CSVOptions{
+		Delimiter:  delimiter,
+		HasHeader:  hasHeader,
+		Mapping:    mapping,
+		DateFormat: r.FormValue("date_format"),
+	})
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	report, err := h.useCases.ImportItems(r.Context(), rows, dryRun)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, report)
+}
+
+// parseBoolValue разбирает необязательный логический параметр
+func parseBoolValue(value string, def bool) (bool, error) {
+	if value == "" {
+		return def, nil
+	}
+	return strconv.ParseBool(value)
+}
@@ -0,0 +1,144 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newImportRequest собирает multipart-запрос с файлом и полями формы
+func newImportRequest(t *testing.T, content string, fields map[string]string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if content != "" {
+		part, err := writer.CreateFormFile("file", "items.csv")
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write([]byte(content))
+	}
+	for name, value := range fields {
+		writer.WriteField(name, value)
+	}
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/api/items/import", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestHandler_ImportItems(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		fields     map[string]string
+		wantStatus int
+		wantRows   []domain.ImportRow
+		wantDryRun bool
+	}{
+		{
+			name:       "default mapping",
+			content:    "type,amount,category,date\nincome,1000.50,Salary,2024-01-15\nexpense,abc,Food,2024-01-16\n",
+			wantStatus: http.StatusOK,
+			wantRows: []domain.ImportRow{
+				{Line: 2, Item: &domain.Item{Type: "income", Amount: domain.NewMoney(1000, 50), Category: "Salary", Date: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)}},
+				{Line: 3},
+			},
+		},
+		{
+			name:    "custom mapping, date format and dry run",
+			content: "\ufeffДата;Сумма;Вид;Статья;Комментарий\n15.01.2024;50;EXPENSE;Кафе;\"кофе;\nи булочка\"\n16.01.2024;20;expense;Кафе;чай\n",
+			fields: map[string]string{
+				"delimiter":   ";",
+				"type":        "Вид",
+				"amount":      "2",
+				"category":    "Статья",
+				"description": "Комментарий",
+				"date":        "Дата",
+				"date_format": "02.01.2006",
+				"dry_run":     "true",
+			},
+			wantStatus: http.StatusOK,
+			wantDryRun: true,
+			wantRows: []domain.ImportRow{
+				{Line: 2, Item: &domain.Item{Type: "expense", Amount: domain.NewMoney(50, 0), Category: "Кафе", Description: "кофе;\nи булочка", Date: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)}},
+				{Line: 4, Item: &domain.Item{Type: "expense", Amount: domain.NewMoney(20, 0), Category: "Кафе", Description: "чай", Date: time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)}},
+			},
+		},
+		{
+			name:       "missing required column",
+			content:    "type,amount,date\nincome,10,2024-01-15\n",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "missing file",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid dry_run",
+			content:    "type,amount,category,date\n",
+			fields:     map[string]string{"dry_run": "maybe"},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotRows []domain.ImportRow
+			var gotDryRun bool
+			handler := NewHandler(&mockUseCases{
+				importItemsFunc: func(ctx context.Context, rows []domain.ImportRow, dryRun bool) (*domain.ImportReport, error) {
+					gotRows, gotDryRun = rows, dryRun
+					return &domain.ImportReport{Total: len(rows), Errors: []domain.ImportRowError{}}, nil
+				},
+			})
+
+			w := httptest.NewRecorder()
+			handler.ImportItems(w, newImportRequest(t, tt.content, tt.fields))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("ImportItems() status = %v, want %v, body %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var report domain.ImportReport
+			if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+				t.Fatalf("failed to decode report: %v", err)
+			}
+			if gotDryRun != tt.wantDryRun {
+				t.Errorf("ImportItems() dryRun = %v, want %v", gotDryRun, tt.wantDryRun)
+			}
+			if len(gotRows) != len(tt.wantRows) {
+				t.Fatalf("ImportItems() parsed %d rows, want %d", len(gotRows), len(tt.wantRows))
+			}
+			for i, want := range tt.wantRows {
+				got := gotRows[i]
+				if got.Line != want.Line {
+					t.Errorf("row %d line = %d, want %d", i, got.Line, want.Line)
+				}
+				if want.Item == nil {
+					if got.Err == nil {
+						t.Errorf("row %d expected parse error", i)
+					}
+					continue
+				}
+				if got.Err != nil {
+					t.Errorf("row %d unexpected error: %v", i, got.Err)
+					continue
+				}
+				if *got.Item != *want.Item {
+					t.Errorf("row %d item = %+v, want %+v", i, *got.Item, *want.Item)
+				}
+			}
+		})
+	}
+}
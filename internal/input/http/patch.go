@@ -0,0 +1,157 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// PatchItem частично обновляет запись по JSON Merge Patch (RFC 7396):
+// переданные поля заменяют текущие значения, null очищает поле,
+// остальные поля не меняются. id, created_at, updated_at, version и deleted_at изменить нельзя.
+// Запись сохраняется, только если не изменилась с момента чтения.
+func (h *Handler) PatchItem(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		respondIfMatchError(w, err)
+		return
+	}
+
+	// UseNumber сохраняет суммы как есть, без округления через float64
+	decoder := json.NewDecoder(r.Body)
+	decoder.UseNumber()
+	var patch map[string]interface{}
+	if err := decoder.Decode(&patch); err != nil || patch == nil {
+		respondError(w, http.StatusBadRequest, "Request body must be a JSON object")
+		return
+	}
+
+	current, err := h.useCases.GetItem(r.Context(), id)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+	if version != 0 && version != current.Version {
+		respondDomainError(w, domain.ErrVersionMismatch)
+		return
+	}
+
+	item, err := applyItemPatch(current, patch)
+	if err != nil {
+		var verr *domain.ValidationError
+		if errors.As(err, &verr) {
+			respondValidationError(w, verr)
+			return
+		}
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.useCases.UpdateItem(r.Context(), item); err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	setItemETag(w, item)
+	respondJSON(w, http.StatusOK, item)
+}
+
+// applyItemPatch применяет merge patch к JSON-представлению записи
+// и собирает из результата новую запись
+func applyItemPatch(current *domain.Item, patch map[string]interface{}) (*domain.Item, error) {
+	data, err := json.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc map[string]interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	data, err = json.Marshal(mergePatch(doc, patch))
+	if err != nil {
+		return nil, err
+	}
+	item := &domain.Item{}
+	if err := json.Unmarshal(data, item); err != nil {
+		if verr := patchFieldErrors(patch).Err(); verr != nil {
+			return nil, verr
+		}
+		return nil, err
+	}
+	item.ID = current.ID
+	item.CreatedAt = current.CreatedAt
+	item.UpdatedAt = current.UpdatedAt
+	item.Version = current.Version
+	item.DeletedAt = current.DeletedAt
+	item.Match = nil
+	return item, nil
+}
+
+// patchFieldErrors разбирает каждое поле патча отдельно, чтобы указать,
+// какие именно значения имеют неверный тип или формат
+func patchFieldErrors(patch map[string]interface{}) *domain.ValidationError {
+	keys := make([]string, 0, len(patch))
+	for key := range patch {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	verr := &domain.ValidationError{}
+	for _, key := range keys {
+		if patch[key] == nil {
+			continue
+		}
+		data, err := json.Marshal(map[string]interface{}{key: patch[key]})
+		if err != nil {
+			return verr
+		}
+		err = json.Unmarshal(data, &domain.Item{})
+		if err == nil {
+			continue
+		}
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			verr.Add(key, domain.CodeInvalid, fmt.Sprintf("%s must not be a %s", key, typeErr.Value))
+			continue
+		}
+		verr.Add(key, domain.CodeInvalid, err.Error())
+	}
+	return verr
+}
+
+// mergePatch реализует алгоритм MergePatch из RFC 7396 для JSON-объектов
+func mergePatch(target, patch map[string]interface{}) map[string]interface{} {
+	if target == nil {
+		target = make(map[string]interface{}, len(patch))
+	}
+	for key, value := range patch {
+		if value == nil {
+			delete(target, key)
+			continue
+		}
+		if patchObj, ok := value.(map[string]interface{}); ok {
+			targetObj, _ := target[key].(map[string]interface{})
+			target[key] = mergePatch(targetObj, patchObj)
+			continue
+		}
+		target[key] = value
+	}
+	return target
+}
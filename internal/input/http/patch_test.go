@@ -0,0 +1,192 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func TestHandler_PatchItem(t *testing.T) {
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	stored := func() *domain.Item {
+		return &domain.Item{
+			ID:          1,
+			Type:        "expense",
+			Amount:      domain.NewMoney(250, 0),
+			Currency:    "RUB",
+			Category:    "Food",
+			Description: "lunch",
+			Date:        date,
+			CreatedAt:   createdAt,
+			UpdatedAt:   createdAt,
+			Version:     3,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		id         string
+		body       string
+		ifMatch    string
+		wantStatus int
+		wantFields []string
+		want       func(item *domain.Item)
+	}{
+		{
+			name:       "updates only supplied fields",
+			id:         "1",
+			body:       `{"amount": "12345678901.23", "category": "Cafe"}`,
+			wantStatus: http.StatusOK,
+			want: func(item *domain.Item) {
+				item.Amount = domain.NewMoney(12345678901, 23)
+				item.Category = "Cafe"
+			},
+		},
+		{
+			name:       "null clears description",
+			id:         "1",
+			body:       `{"description": null}`,
+			wantStatus: http.StatusOK,
+			want: func(item *domain.Item) {
+				item.Description = ""
+			},
+		},
+		{
+			name:       "read-only fields are ignored",
+			id:         "1",
+			body:       `{"id": 42, "created_at": "2030-01-01T00:00:00Z", "version": 10, "type": "income"}`,
+			wantStatus: http.StatusOK,
+			want: func(item *domain.Item) {
+				item.Type = "income"
+			},
+		},
+		{
+			name:       "matching If-Match",
+			id:         "1",
+			body:       `{"category": "Cafe"}`,
+			ifMatch:    `"3"`,
+			wantStatus: http.StatusOK,
+			want: func(item *domain.Item) {
+				item.Category = "Cafe"
+			},
+		},
+		{
+			name:       "stale If-Match",
+			id:         "1",
+			body:       `{"category": "Cafe"}`,
+			ifMatch:    `"2"`,
+			wantStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:       "null required field fails validation",
+			id:         "1",
+			body:       `{"category": null}`,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "invalid amount",
+			id:         "1",
+			body:       `{"amount": "10.005"}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantFields: []string{"amount"},
+		},
+		{
+			name:       "wrong field types",
+			id:         "1",
+			body:       `{"amount": "abc", "category": 5, "description": "ok", "date": true}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantFields: []string{"amount", "category", "date"},
+		},
+		{
+			name:       "body is not an object",
+			id:         "1",
+			body:       `[{"op": "replace"}]`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "item not found",
+			id:         "999",
+			body:       `{"category": "Cafe"}`,
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved *domain.Item
+			handler := NewHandler(&mockUseCases{
+				getItemFunc: func(ctx context.Context, id int64) (*domain.Item, error) {
+					if id != 1 {
+						return nil, domain.NewNotFoundError("item not found")
+					}
+					return stored(), nil
+				},
+				updateItemFunc: func(ctx context.Context, item *domain.Item) error {
+					item.Normalize()
+					if err := item.Validate(); err != nil {
+						return err
+					}
+					copied := *item
+					saved = &copied
+					item.Version++
+					return nil
+				},
+			})
+
+			req := httptest.NewRequest("PATCH", "/api/items/"+tt.id, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/merge-patch+json")
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			req = mux.SetURLVars(req, map[string]string{"id": tt.id})
+			w := httptest.NewRecorder()
+
+			handler.PatchItem(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("PatchItem() status = %v, want %v, body %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantFields != nil {
+				var resp errorResponse
+				if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				var fields []string
+				for _, fieldErr := range resp.Errors {
+					fields = append(fields, fieldErr.Field)
+				}
+				if !reflect.DeepEqual(fields, tt.wantFields) {
+					t.Errorf("PatchItem() error fields = %v, want %v", fields, tt.wantFields)
+				}
+			}
+			if tt.want == nil {
+				return
+			}
+
+			want := stored()
+			tt.want(want)
+			if saved == nil || *saved != *want {
+				t.Errorf("PatchItem() saved %+v, want %+v", saved, want)
+			}
+			var got domain.Item
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if !got.CreatedAt.Equal(createdAt) {
+				t.Errorf("PatchItem() created_at = %v, want %v", got.CreatedAt, createdAt)
+			}
+			if etag := w.Header().Get("ETag"); etag != `"4"` {
+				t.Errorf("PatchItem() ETag = %s, want \"4\"", etag)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package http
+
+import "net/http"
+
+// GetPoolStats возвращает состояние пула соединений с базой
+func (h *Handler) GetPoolStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.useCases.GetPoolStats(r.Context())
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, stats)
+}
@@ -0,0 +1,64 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_GetPoolStats(t *testing.T) {
+	tests := []struct {
+		name       string
+		mock       *mockUseCases
+		wantStatus int
+		wantInUse  int
+	}{
+		{
+			name: "pool stats",
+			mock: &mockUseCases{
+				getPoolStatsFunc: func(ctx context.Context) (*domain.PoolStats, error) {
+					return &domain.PoolStats{MaxOpenConnections: 25, OpenConnections: 4, InUse: 3, Idle: 1}, nil
+				},
+			},
+			wantStatus: http.StatusOK,
+			wantInUse:  3,
+		},
+		{
+			name: "storage without pool",
+			mock: &mockUseCases{
+				getPoolStatsFunc: func(ctx context.Context) (*domain.PoolStats, error) {
+					return nil, domain.NewNotFoundError("storage has no connection pool")
+				},
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHandler(tt.mock)
+
+			req := httptest.NewRequest("GET", "/api/stats/pool", nil)
+			w := httptest.NewRecorder()
+
+			handler.GetPoolStats(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("GetPoolStats() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var stats domain.PoolStats
+			if err := json.NewDecoder(w.Body).Decode(&stats); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if stats.InUse != tt.wantInUse {
+				t.Errorf("GetPoolStats() in_use = %d, want %d", stats.InUse, tt.wantInUse)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) CreateRate(w http.ResponseWriter, r *http.Request) {
+	var rate domain.ExchangeRate
+	if err := json.NewDecoder(r.Body).Decode(&rate); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.useCases.CreateRate(r.Context(), &rate); err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, rate)
+}
+
+func (h *Handler) GetRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := h.useCases.GetRates(r.Context(), r.URL.Query().Get("from"), r.URL.Query().Get("to"))
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+	if rates == nil {
+		rates = []*domain.ExchangeRate{}
+	}
+
+	respondJSON(w, http.StatusOK, rates)
+}
+
+func (h *Handler) DeleteRate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	if err := h.useCases.DeleteRate(r.Context(), id); err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,138 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func TestHandler_CreateRate(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       interface{}
+		mock       *mockUseCases
+		wantStatus int
+	}{
+		{
+			name: "successful creation",
+			body: domain.ExchangeRate{From: "USD", To: "RUB", Rate: domain.MustParseRate("92.5"), ValidFrom: time.Now()},
+			mock: &mockUseCases{
+				createRateFunc: func(ctx context.Context, rate *domain.ExchangeRate) error {
+					rate.ID = 1
+					return nil
+				},
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "invalid json",
+			body:       "invalid",
+			mock:       &mockUseCases{},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "validation error",
+			body: domain.ExchangeRate{From: "USD", To: "USD", Rate: domain.MustParseRate("1"), ValidFrom: time.Now()},
+			mock: &mockUseCases{
+				createRateFunc: func(ctx context.Context, rate *domain.ExchangeRate) error {
+					return domain.NewValidationError("to", "from and to currencies must differ")
+				},
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHandler(tt.mock)
+
+			body, _ := json.Marshal(tt.body)
+			req := httptest.NewRequest("POST", "/api/rates", bytes.NewBuffer(body))
+			w := httptest.NewRecorder()
+
+			handler.CreateRate(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("CreateRate() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHandler_GetRates(t *testing.T) {
+	handler := NewHandler(&mockUseCases{
+		getRatesFunc: func(ctx context.Context, from, to string) ([]*domain.ExchangeRate, error) {
+			if from != "USD" || to != "" {
+				t.Errorf("GetRates() from = %v, to = %v", from, to)
+			}
+			return nil, nil
+		},
+	})
+
+	req := httptest.NewRequest("GET", "/api/rates?from=USD", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetRates(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("GetRates() status = %v, want %v", w.Code, http.StatusOK)
+	}
+	if body := w.Body.String(); body != "[]\n" {
+		t.Errorf("GetRates() body = %q, want empty list", body)
+	}
+}
+
+func TestHandler_DeleteRate(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		mock       *mockUseCases
+		wantStatus int
+	}{
+		{
+			name:       "successful deletion",
+			id:         "1",
+			mock:       &mockUseCases{},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "invalid id",
+			id:         "invalid",
+			mock:       &mockUseCases{},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "rate not found",
+			id:   "999",
+			mock: &mockUseCases{
+				deleteRateFunc: func(ctx context.Context, id int64) error {
+					return domain.NewNotFoundError("exchange rate not found")
+				},
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHandler(tt.mock)
+
+			req := httptest.NewRequest("DELETE", "/api/rates/"+tt.id, nil)
+			req = mux.SetURLVars(req, map[string]string{"id": tt.id})
+			w := httptest.NewRecorder()
+
+			handler.DeleteRate(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("DeleteRate() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
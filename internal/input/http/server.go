@@ -1,14 +1,19 @@
 package http
 
 import (
+	"context"
 	"fmt"
 	"github.com/dontpanicw/SalesTracker/internal/port"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
 )
 
+// shutdownTimeout — сколько при остановке ждать завершения текущих запросов
+const shutdownTimeout = 10 * time.Second
+
 type Server struct {
 	router  *mux.Router
 	handler *Handler
@@ -28,28 +33,61 @@ func NewServer(useCases port.UseCases, port string) *Server {
 func (s *Server) setupRoutes() {
 	// API routes
 	api := s.router.PathPrefix("/api").Subrouter()
+	api.Use(actorMiddleware)
 
 	api.HandleFunc("/items", s.handler.CreateItem).Methods("POST")
 	api.HandleFunc("/items", s.handler.GetItems).Methods("GET")
+	// Маршруты выгрузки, загрузки, пакетных операций и корзины регистрируются раньше /items/{id}
+	api.HandleFunc("/items/export", s.handler.ExportItems).Methods("GET")
+	api.HandleFunc("/items/import", s.handler.ImportItems).Methods("POST")
+	api.HandleFunc("/items/batch", s.handler.ApplyBatch).Methods("POST")
+	api.HandleFunc("/items/trash", s.handler.GetTrash).Methods("GET")
 	api.HandleFunc("/items/{id}", s.handler.GetItem).Methods("GET")
 	api.HandleFunc("/items/{id}", s.handler.UpdateItem).Methods("PUT")
+	api.HandleFunc("/items/{id}", s.handler.PatchItem).Methods("PATCH")
 	api.HandleFunc("/items/{id}", s.handler.DeleteItem).Methods("DELETE")
+	api.HandleFunc("/items/{id}/restore", s.handler.RestoreItem).Methods("POST")
+	api.HandleFunc("/items/{id}/history", s.handler.GetItemHistory).Methods("GET")
+	api.HandleFunc("/audit", s.handler.GetAudit).Methods("GET")
 	api.HandleFunc("/analytics", s.handler.GetAnalytics).Methods("GET")
+	api.HandleFunc("/analytics/groups", s.handler.GetGroupedAnalytics).Methods("GET")
+	api.HandleFunc("/analytics/timeseries", s.handler.GetTimeSeries).Methods("GET")
+	api.HandleFunc("/rates", s.handler.CreateRate).Methods("POST")
+	api.HandleFunc("/rates", s.handler.GetRates).Methods("GET")
+	api.HandleFunc("/rates/{id}", s.handler.DeleteRate).Methods("DELETE")
+	api.HandleFunc("/stats/pool", s.handler.GetPoolStats).Methods("GET")
 
 	// Serve static files
 	s.router.PathPrefix("/").Handler(http.FileServer(http.Dir("./web")))
 }
 
-func (s *Server) Start() error {
+// Start запускает сервер и блокируется до отмены ctx. После отмены сервер
+// перестает принимать соединения и ждет завершения текущих запросов.
+func (s *Server) Start(ctx context.Context) error {
 	c := cors.New(cors.Options{
 		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"*"},
+		ExposedHeaders:   []string{"ETag", "Idempotent-Replayed"},
 		AllowCredentials: true,
 	})
 
 	handler := c.Handler(s.router)
 
+	server := &http.Server{Addr: ":" + s.port, Handler: handler}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+
 	fmt.Printf("Server starting on port %s\n", s.port)
-	return http.ListenAndServe(":"+s.port, handler)
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
 }
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// GetTrash возвращает страницу записей в корзине.
+// Фильтры, сортировка и пагинация те же, что у GET /api/items.
+func (h *Handler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseItemFilter(r)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+	filter.Deleted = true
+
+	page, err := h.useCases.GetItems(r.Context(), filter)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+	if page.Items == nil {
+		page.Items = []*domain.Item{}
+	}
+
+	respondJSON(w, http.StatusOK, page)
+}
+
+// RestoreItem возвращает запись из корзины. If-Match проверяется так же, как при удалении.
+func (h *Handler) RestoreItem(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		respondIfMatchError(w, err)
+		return
+	}
+
+	item, err := h.useCases.RestoreItem(r.Context(), id, version)
+	if err != nil {
+		respondDomainError(w, err)
+		return
+	}
+
+	setItemETag(w, item)
+	respondJSON(w, http.StatusOK, item)
+}
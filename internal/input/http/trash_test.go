@@ -0,0 +1,92 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func TestHandler_GetTrash(t *testing.T) {
+	deletedAt := time.Date(2024, 1, 20, 12, 0, 0, 0, time.UTC)
+	var got domain.ItemFilter
+	handler := NewHandler(&mockUseCases{
+		getItemsFunc: func(ctx context.Context, filter domain.ItemFilter) (*domain.ItemPage, error) {
+			got = filter
+			return &domain.ItemPage{Items: []*domain.Item{{ID: 1, DeletedAt: &deletedAt}}, Total: 1}, nil
+		},
+	})
+
+	req := httptest.NewRequest("GET", "/api/items/trash?category=Food", nil)
+	w := httptest.NewRecorder()
+	handler.GetTrash(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("GetTrash() status = %v, want %v", w.Code, http.StatusOK)
+	}
+	if !got.Deleted || len(got.Categories) != 1 || got.Categories[0] != "Food" {
+		t.Errorf("GetTrash() filter = %+v, want deleted items of category Food", got)
+	}
+	var page domain.ItemPage
+	if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(page.Items) != 1 || page.Items[0].DeletedAt == nil || !page.Items[0].DeletedAt.Equal(deletedAt) {
+		t.Errorf("GetTrash() items = %+v, want one item with deleted_at", page.Items)
+	}
+}
+
+func TestHandler_RestoreItem(t *testing.T) {
+	// restoreVersion5 возвращает из корзины запись 1 с версией 5
+	restoreVersion5 := &mockUseCases{
+		restoreItemFunc: func(ctx context.Context, id int64, version int64) (*domain.Item, error) {
+			if id != 1 {
+				return nil, domain.NewNotFoundError("item not found")
+			}
+			if version != 0 && version != 5 {
+				return nil, domain.ErrVersionMismatch
+			}
+			return &domain.Item{ID: id, Version: 6}, nil
+		},
+	}
+
+	tests := []struct {
+		name       string
+		id         string
+		ifMatch    string
+		wantStatus int
+	}{
+		{name: "successful restore", id: "1", wantStatus: http.StatusOK},
+		{name: "matching If-Match", id: "1", ifMatch: `"5"`, wantStatus: http.StatusOK},
+		{name: "stale If-Match", id: "1", ifMatch: `"4"`, wantStatus: http.StatusPreconditionFailed},
+		{name: "invalid id", id: "invalid", wantStatus: http.StatusBadRequest},
+		{name: "item not in trash", id: "999", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHandler(restoreVersion5)
+
+			req := httptest.NewRequest("POST", "/api/items/"+tt.id+"/restore", nil)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			req = mux.SetURLVars(req, map[string]string{"id": tt.id})
+			w := httptest.NewRecorder()
+
+			handler.RestoreItem(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("RestoreItem() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+			if w.Code == http.StatusOK && w.Header().Get("ETag") != `"6"` {
+				t.Errorf("RestoreItem() ETag = %s, want \"6\"", w.Header().Get("ETag"))
+			}
+		})
+	}
+}
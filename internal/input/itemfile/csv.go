@@ -0,0 +1,234 @@
+// Package itemfile читает и пишет файлы записей в форматах CSV и JSON.
+// Используется HTTP-импортом и выгрузкой и командами import и export.
+package itemfile
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Fields — поля записи, которые сопоставляются колонкам файла
+var Fields = []string{"type", "amount", "currency", "category", "description", "date"}
+
+// requiredFields — поля, без колонок для которых импорт невозможен
+var requiredFields = map[string]bool{"type": true, "amount": true, "category": true, "date": true}
+
+// defaultDateLayouts — форматы даты, которые пробуются, если DateFormat не задан
+var defaultDateLayouts = []string{time.RFC3339, "2006-01-02"}
+
+// Header — заголовок CSV-выгрузки записей
+var Header = []string{"id", "type", "amount", "currency", "category", "description", "date", "created_at", "updated_at"}
+
+// CSVOptions описывает формат импортируемого CSV-файла
+type CSVOptions struct {
+	Delimiter  rune
+	HasHeader  bool
+	Mapping    map[string]string // поле записи -> имя колонки или номер с 1, пусто — по имени поля
+	DateFormat string            // формат даты в нотации Go, пусто — RFC 3339 или 2006-01-02
+}
+
+// ReadCSV читает строки CSV и разбирает их в записи. Ошибки отдельных
+// строк попадают в ImportRow.Err; ошибка возвращается, только если файл нельзя прочитать.
+func ReadCSV(src io.Reader, opts CSVOptions) ([]domain.ImportRow, error) {
+	reader := csv.NewReader(src)
+	reader.Comma = opts.Delimiter
+	reader.FieldsPerRecord = -1
+
+	var header []string
+	if opts.HasHeader {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil, errors.New("File is empty")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Invalid CSV header: %v", err)
+		}
+		header = append([]string{}, record...)
+		// Excel добавляет в начало UTF-8 файла BOM
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	columns, err := resolveColumns(opts.Mapping, header)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []domain.ImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, err
+			}
+			rows = append(rows, domain.ImportRow{Line: parseErr.StartLine, Err: parseErr.Err})
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		item, err := parseRecord(record, columns, opts.DateFormat)
+		rows = append(rows, domain.ImportRow{Line: line, Item: item, Err: err})
+	}
+	return rows, nil
+}
+
+// resolveColumns находит номера колонок (с нуля) для полей записи.
+// Необязательные поля без колонки в результат не попадают.
+func resolveColumns(mapping map[string]string, header []string) (map[string]int, error) {
+	columns := make(map[string]int, len(Fields))
+	for _, field := range Fields {
+		name := mapping[field]
+		if n, err := strconv.Atoi(name); err == nil {
+			if n < 1 {
+				return nil, fmt.Errorf("Invalid column number for '%s'", field)
+			}
+			columns[field] = n - 1
+			continue
+		}
+
+		explicit := name != ""
+		if !explicit {
+			name = field
+		}
+		index := -1
+		for i, column := range header {
+			if strings.EqualFold(strings.TrimSpace(column), name) {
+				index = i
+				break
+			}
+		}
+		switch {
+		case index >= 0:
+			columns[field] = index
+		case explicit || requiredFields[field]:
+			return nil, fmt.Errorf("Column '%s' for '%s' not found", name, field)
+		}
+	}
+	return columns, nil
+}
+
+// parseRecord разбирает строку файла в запись. Проверка бизнес-правил
+// выполняется позже, в use case.
+func parseRecord(record []string, columns map[string]int, dateFormat string) (*domain.Item, error) {
+	values := make(map[string]string, len(columns))
+	for _, field := range Fields {
+		index, ok := columns[field]
+		if !ok {
+			continue
+		}
+		if index >= len(record) {
+			return nil, fmt.Errorf("missing column %d for %s", index+1, field)
+		}
+		values[field] = strings.TrimSpace(record[index])
+	}
+
+	amount, err := domain.ParseMoney(values["amount"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount '%s'", values["amount"])
+	}
+	date, err := parseDate(values["date"], dateFormat)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date '%s'", values["date"])
+	}
+
+	return &domain.Item{
+		Type:        strings.ToLower(values["type"]),
+		Amount:      amount,
+		Currency:    values["currency"],
+		Category:    values["category"],
+		Description: values["description"],
+		Date:        date,
+	}, nil
+}
+
+// parseDate разбирает дату в заданном формате или в одном из форматов по умолчанию
+func parseDate(value, layout string) (time.Time, error) {
+	if layout != "" {
+		return time.Parse(layout, value)
+	}
+	var err error
+	for _, layout := range defaultDateLayouts {
+		var date time.Time
+		if date, err = time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// Record возвращает строку CSV-выгрузки записи в порядке Header
+func Record(item *domain.Item) []string {
+	return []string{
+		strconv.FormatInt(item.ID, 10),
+		item.Type,
+		item.Amount.String(),
+		item.Currency,
+		item.Category,
+		item.Description,
+		item.Date.UTC().Format(time.RFC3339),
+		item.CreatedAt.UTC().Format(time.RFC3339),
+		item.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// ParseDelimiter разбирает разделитель CSV: один символ,
+// "tab" для табуляции, по умолчанию запятая
+func ParseDelimiter(value string) (rune, error) {
+	switch value {
+	case "":
+		return ',', nil
+	case "tab", `\t`:
+		return '\t', nil
+	}
+	delimiter, size := utf8.DecodeRuneInString(value)
+	if size != len(value) || delimiter == utf8.RuneError ||
+		delimiter == '"' || delimiter == '\r' || delimiter == '\n' {
+		return 0, errors.New("Invalid 'delimiter': must be a single character")
+	}
+	return delimiter, nil
+}
+
+// CSVWriter пишет записи в CSV с заголовком Header
+type CSVWriter struct {
+	cw      *csv.Writer
+	started bool
+}
+
+// NewCSVWriter создает CSVWriter с разделителем delimiter, пишущий в w
+func NewCSVWriter(w io.Writer, delimiter rune) *CSVWriter {
+	cw := csv.NewWriter(w)
+	cw.Comma = delimiter
+	return &CSVWriter{cw: cw}
+}
+
+// Write добавляет строку записи, перед первой строкой пишется заголовок
+func (w *CSVWriter) Write(item *domain.Item) error {
+	if !w.started {
+		w.started = true
+		if err := w.cw.Write(Header); err != nil {
+			return err
+		}
+	}
+	return w.cw.Write(Record(item))
+}
+
+// Close дописывает буфер. Без записей пишется только заголовок.
+func (w *CSVWriter) Close() error {
+	if !w.started {
+		w.started = true
+		if err := w.cw.Write(Header); err != nil {
+			return err
+		}
+	}
+	w.cw.Flush()
+	return w.cw.Error()
+}
@@ -0,0 +1,146 @@
+package itemfile
+
+import (
+	"bytes"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		opts      CSVOptions
+		wantRows  int
+		wantErrs  []int // строки с ошибками
+		wantError bool  // файл нельзя прочитать
+	}{
+		{
+			name:     "columns by header with BOM",
+			data:     "\ufeffType,Amount,Category,Date\nexpense,100.50,Food,2024-01-15\nincome,abc,Salary,2024-01-16\n",
+			opts:     CSVOptions{Delimiter: ',', HasHeader: true},
+			wantRows: 2,
+			wantErrs: []int{3},
+		},
+		{
+			name:     "columns by number without header",
+			data:     "2024-01-15;expense;10\n",
+			opts:     CSVOptions{Delimiter: ';', Mapping: map[string]string{"date": "1", "type": "2", "amount": "3", "category": "2"}},
+			wantRows: 1,
+		},
+		{
+			name:     "custom date format",
+			data:     "type,amount,category,date\nexpense,1,Food,15.01.2024\n",
+			opts:     CSVOptions{Delimiter: ',', HasHeader: true, DateFormat: "02.01.2006"},
+			wantRows: 1,
+		},
+		{
+			name:      "missing required column",
+			data:      "type,amount,date\nexpense,1,2024-01-15\n",
+			opts:      CSVOptions{Delimiter: ',', HasHeader: true},
+			wantError: true,
+		},
+		{
+			name:      "empty file",
+			opts:      CSVOptions{Delimiter: ',', HasHeader: true},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := ReadCSV(strings.NewReader(tt.data), tt.opts)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("ReadCSV() error = %v, wantError %v", err, tt.wantError)
+			}
+			if len(rows) != tt.wantRows {
+				t.Fatalf("ReadCSV() returned %d rows, want %d", len(rows), tt.wantRows)
+			}
+			var errLines []int
+			for _, row := range rows {
+				if row.Err != nil {
+					errLines = append(errLines, row.Line)
+				}
+			}
+			if len(errLines) != len(tt.wantErrs) || (len(errLines) > 0 && errLines[0] != tt.wantErrs[0]) {
+				t.Errorf("ReadCSV() error lines = %v, want %v", errLines, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewCSVWriter(&buf, ';')
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if got := buf.String(); got != strings.Join(Header, ";")+"\n" {
+		t.Errorf("empty export = %q, want only header", got)
+	}
+
+	buf.Reset()
+	w = NewCSVWriter(&buf, ',')
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	w.Write(&domain.Item{ID: 1, Type: "expense", Amount: domain.NewMoney(100, 50), Currency: "RUB", Category: "Food", Date: date})
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	rows, err := ReadCSV(&buf, CSVOptions{Delimiter: ',', HasHeader: true})
+	if err != nil || len(rows) != 1 || rows[0].Err != nil {
+		t.Fatalf("ReadCSV() of export = %+v, %v", rows, err)
+	}
+	if item := rows[0].Item; item.Amount != domain.NewMoney(100, 50) || !item.Date.Equal(date) || item.Category != "Food" {
+		t.Errorf("ReadCSV() of export = %+v", item)
+	}
+}
+
+func TestParseDelimiter(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    rune
+		wantErr bool
+	}{
+		{value: "", want: ','},
+		{value: ";", want: ';'},
+		{value: "tab", want: '\t'},
+		{value: `"`, wantErr: true},
+		{value: ",,", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDelimiter(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseDelimiter(%q) = %q, %v", tt.value, got, err)
+		}
+	}
+}
+
+func TestCSVWriter_RoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewCSVWriter(&buf, ',')
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	values := []string{"=1+1", "+bonus", "-refund", "@home"}
+	for i, value := range values {
+		w.Write(&domain.Item{ID: int64(i + 1), Type: "expense", Amount: domain.NewMoney(1, 0), Currency: "RUB", Category: value, Description: value, Date: date})
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	rows, err := ReadCSV(&buf, CSVOptions{Delimiter: ',', HasHeader: true})
+	if err != nil || len(rows) != len(values) {
+		t.Fatalf("ReadCSV() of export = %+v, %v", rows, err)
+	}
+	for i, row := range rows {
+		if row.Err != nil {
+			t.Fatalf("ReadCSV() row %d error = %v", i+1, row.Err)
+		}
+		if row.Item.Category != values[i] || row.Item.Description != values[i] {
+			t.Errorf("ReadCSV() row %d = %q/%q, want %q", i+1, row.Item.Category, row.Item.Description, values[i])
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package itemfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"io"
+)
+
+// ReadJSON читает JSON-массив записей в формате API. Номер строки ImportRow —
+// номер элемента массива, начиная с 1. Ошибки отдельных элементов попадают
+// в ImportRow.Err; ошибка возвращается, только если файл не является JSON-массивом.
+func ReadJSON(src io.Reader) ([]domain.ImportRow, error) {
+	var elements []json.RawMessage
+	if err := json.NewDecoder(src).Decode(&elements); err != nil {
+		return nil, fmt.Errorf("Invalid JSON: expected an array of items: %v", err)
+	}
+
+	rows := make([]domain.ImportRow, len(elements))
+	for i, element := range elements {
+		rows[i].Line = i + 1
+		item := &domain.Item{}
+		if err := json.Unmarshal(element, item); err != nil {
+			rows[i].Err = fmt.Errorf("invalid item: %v", err)
+			continue
+		}
+		rows[i].Item = item
+	}
+	return rows, nil
+}
+
+// JSONWriter пишет записи JSON-массивом по мере поступления
+type JSONWriter struct {
+	w     io.Writer
+	count int
+}
+
+// NewJSONWriter создает JSONWriter, пишущий в w
+func NewJSONWriter(w io.Writer) *JSONWriter {
+	return &JSONWriter{w: w}
+}
+
+// Write добавляет запись в массив
+func (jw *JSONWriter) Write(item *domain.Item) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	prefix := ",\n  "
+	if jw.count == 0 {
+		prefix = "[\n  "
+	}
+	jw.count++
+	_, err = fmt.Fprintf(jw.w, "%s%s", prefix, data)
+	return err
+}
+
+// Close завершает массив. Без записей пишется пустой массив.
+func (jw *JSONWriter) Close() error {
+	if jw.count == 0 {
+		_, err := io.WriteString(jw.w, "[]\n")
+		return err
+	}
+	_, err := io.WriteString(jw.w, "\n]\n")
+	return err
+}
@@ -0,0 +1,62 @@
+package itemfile
+
+import (
+	"bytes"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadJSON(t *testing.T) {
+	data := `[
+		{"type": "expense", "amount": 100.5, "category": "Food", "date": "2024-01-15T00:00:00Z"},
+		{"type": "income", "amount": "abc", "category": "Salary", "date": "2024-01-16T00:00:00Z"}
+	]`
+
+	rows, err := ReadJSON(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadJSON() error = %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("ReadJSON() returned %d rows, want 2", len(rows))
+	}
+	if rows[0].Err != nil || rows[0].Item.Amount != domain.NewMoney(100, 50) || rows[0].Line != 1 {
+		t.Errorf("ReadJSON() first row = %+v", rows[0])
+	}
+	if rows[1].Err == nil || rows[1].Line != 2 {
+		t.Errorf("ReadJSON() second row = %+v, want error", rows[1])
+	}
+
+	if _, err := ReadJSON(strings.NewReader(`{"type": "expense"}`)); err == nil {
+		t.Error("ReadJSON() of an object: expected error")
+	}
+}
+
+func TestJSONWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewJSONWriter(&buf)
+	if err := w.Close(); err != nil || buf.String() != "[]\n" {
+		t.Fatalf("empty export = %q, %v", buf.String(), err)
+	}
+
+	buf.Reset()
+	w = NewJSONWriter(&buf)
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	for i := int64(1); i <= 2; i++ {
+		if err := w.Write(&domain.Item{ID: i, Type: "income", Amount: domain.NewMoney(i, 0), Category: "Salary", Date: date}); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	rows, err := ReadJSON(&buf)
+	if err != nil || len(rows) != 2 {
+		t.Fatalf("ReadJSON() of export = %+v, %v", rows, err)
+	}
+	if rows[1].Err != nil || rows[1].Item.Amount != domain.NewMoney(2, 0) || !rows[1].Item.Date.Equal(date) {
+		t.Errorf("ReadJSON() of export = %+v", rows[1])
+	}
+}
@@ -9,9 +9,53 @@ import (
 // Repository определяет интерфейс для работы с хранилищем
 type Repository interface {
 	Create(ctx context.Context, item *domain.Item) error
+	// CreateMany сохраняет записи в одной транзакции: либо все, либо ни одной
+	CreateMany(ctx context.Context, items []*domain.Item) error
 	GetByID(ctx context.Context, id int64) (*domain.Item, error)
-	GetAll(ctx context.Context, from, to *time.Time) ([]*domain.Item, error)
+	// GetByIDs возвращает найденные записи с указанными id в произвольном порядке
+	GetByIDs(ctx context.Context, ids []int64) ([]*domain.Item, error)
+	// GetAll и StreamAll возвращают действующие записи или, если filter.Deleted, записи в корзине
+	GetAll(ctx context.Context, filter domain.ItemFilter) (*domain.ItemPage, error)
+	// StreamAll передает в fn все записи по фильтру, не загружая их в память целиком
+	StreamAll(ctx context.Context, filter domain.ItemFilter, fn func(*domain.Item) error) error
+	// Update и Delete при ненулевой версии изменяют запись, только если ее версия
+	// совпадает, иначе возвращают domain.ErrVersionMismatch. Обе операции увеличивают версию.
+	// Delete переносит запись в корзину, записи в корзине для остальных операций не найдены.
 	Update(ctx context.Context, item *domain.Item) error
-	Delete(ctx context.Context, id int64) error
-	GetAnalytics(ctx context.Context, from, to time.Time) (*domain.Analytics, error)
+	Delete(ctx context.Context, id int64, version int64) error
+	// Restore возвращает запись из корзины, проверяя версию так же, как Delete
+	Restore(ctx context.Context, id int64, version int64) (*domain.Item, error)
+	// PurgeDeleted окончательно удаляет записи, перенесенные в корзину раньше before
+	PurgeDeleted(ctx context.Context, before time.Time) (purged int64, err error)
+	// ApplyItemBatch применяет создание, обновление и удаление записей в одной транзакции.
+	// Ошибки отдельных обновлений и удалений возвращаются в failed по id записи;
+	// если partial = false и такие ошибки есть, ничего не сохраняется.
+	ApplyItemBatch(ctx context.Context, batch *domain.ItemBatch, partial bool) (failed map[int64]error, err error)
+	GetAnalytics(ctx context.Context, query domain.AnalyticsQuery) (*domain.AnalyticsReport, error)
+	GetGroupedAnalytics(ctx context.Context, query domain.AnalyticsQuery) ([]*domain.AnalyticsGroup, error)
+	GetTimeSeries(ctx context.Context, query domain.TimeSeriesQuery) ([]*domain.TimeSeriesPoint, error)
+
+	// AddHistory сохраняет записи истории изменений
+	AddHistory(ctx context.Context, entries []*domain.HistoryEntry) error
+	GetHistory(ctx context.Context, filter domain.HistoryFilter) (*domain.HistoryPage, error)
+
+	// ReserveIdempotencyKey сохраняет ключ идемпотентности, удаляя истекшие.
+	// Незавершенный ключ, аренда которого истекла к record.CreatedAt, перехватывается.
+	// Если ключ уже сохранен и не перехвачен, возвращает его запись, иначе nil.
+	ReserveIdempotencyKey(ctx context.Context, record *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error)
+	// CompleteIdempotencyKey сохраняет ответ для резервирования record. Если ключ
+	// перехватил повтор, возвращает domain.ErrIdempotencyKeyTakenOver.
+	CompleteIdempotencyKey(ctx context.Context, record *domain.IdempotencyRecord, response []byte) error
+	// ReleaseIdempotencyKey удаляет резервирование record, если ответ еще не сохранен
+	// и ключ не перехвачен
+	ReleaseIdempotencyKey(ctx context.Context, record *domain.IdempotencyRecord) error
+
+	CreateRate(ctx context.Context, rate *domain.ExchangeRate) error
+	GetRates(ctx context.Context, from, to string) ([]*domain.ExchangeRate, error)
+	DeleteRate(ctx context.Context, id int64) error
+}
+
+// PoolStatsProvider реализуют хранилища, которые работают через пул соединений
+type PoolStatsProvider interface {
+	PoolStats() domain.PoolStats
 }
@@ -0,0 +1,13 @@
+package port
+
+import "context"
+
+// TxManager выполняет несколько операций хранилища атомарно
+type TxManager interface {
+	// WithinTx вызывает fn с репозиторием, все операции которого выполняются в одной
+	// транзакции. Транзакция фиксируется, если fn вернула nil, иначе откатывается.
+	// При конфликте сериализации транзакция повторяется с начала, поэтому fn не должна
+	// иметь побочных эффектов вне переданного репозитория.
+	// Вызов WithinTx внутри fn выполняется в той же транзакции.
+	WithinTx(ctx context.Context, fn func(ctx context.Context, repo Repository) error) error
+}
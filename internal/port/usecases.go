@@ -3,15 +3,40 @@ package port
 import (
 	"context"
 	"github.com/dontpanicw/SalesTracker/internal/domain"
-	"time"
 )
 
 // UseCases определяет бизнес-логику приложения
 type UseCases interface {
 	CreateItem(ctx context.Context, item *domain.Item) error
+	// CreateItemIdempotent создает запись один раз для ключа key. При повторе
+	// с тем же ключом и содержимым заполняет item сохраненной записью и возвращает replayed = true.
+	CreateItemIdempotent(ctx context.Context, key string, item *domain.Item) (replayed bool, err error)
+	ImportItems(ctx context.Context, rows []domain.ImportRow, dryRun bool) (*domain.ImportReport, error)
 	GetItem(ctx context.Context, id int64) (*domain.Item, error)
-	GetItems(ctx context.Context, from, to *time.Time) ([]*domain.Item, error)
+	GetItems(ctx context.Context, filter domain.ItemFilter) (*domain.ItemPage, error)
+	ExportItems(ctx context.Context, filter domain.ItemFilter, fn func(*domain.Item) error) error
+	// UpdateItem, DeleteItem и RestoreItem проверяют версию записи, если она задана (не 0).
+	// DeleteItem переносит запись в корзину, RestoreItem возвращает ее обратно.
 	UpdateItem(ctx context.Context, item *domain.Item) error
-	DeleteItem(ctx context.Context, id int64) error
-	GetAnalytics(ctx context.Context, from, to time.Time) (*domain.Analytics, error)
+	DeleteItem(ctx context.Context, id int64, version int64) error
+	RestoreItem(ctx context.Context, id int64, version int64) (*domain.Item, error)
+	// PurgeTrash окончательно удаляет записи, которые пробыли в корзине дольше срока хранения
+	PurgeTrash(ctx context.Context) (purged int64, err error)
+	// ApplyBatch выполняет пакет операций. Если partial = false, пакет выполняется
+	// целиком или не выполняется совсем, и ошибка первой неуспешной операции возвращается вместе с отчетом.
+	ApplyBatch(ctx context.Context, ops []domain.BatchOperation, partial bool) (*domain.BatchReport, error)
+	// GetHistory возвращает историю изменений записей, сделанных через UseCases.
+	// Автор изменения берется из контекста (domain.WithActor).
+	GetHistory(ctx context.Context, filter domain.HistoryFilter) (*domain.HistoryPage, error)
+	GetAnalytics(ctx context.Context, query domain.AnalyticsQuery) (*domain.AnalyticsReport, error)
+	GetGroupedAnalytics(ctx context.Context, query domain.AnalyticsQuery) ([]*domain.AnalyticsGroup, error)
+	GetTimeSeries(ctx context.Context, query domain.TimeSeriesQuery) ([]*domain.TimeSeriesPoint, error)
+
+	// GetPoolStats возвращает состояние пула соединений с базой.
+	// Если хранилище работает без пула, возвращает domain.ErrNotFound.
+	GetPoolStats(ctx context.Context) (*domain.PoolStats, error)
+
+	CreateRate(ctx context.Context, rate *domain.ExchangeRate) error
+	GetRates(ctx context.Context, from, to string) ([]*domain.ExchangeRate, error)
+	DeleteRate(ctx context.Context, id int64) error
 }
@@ -0,0 +1,154 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"github.com/dontpanicw/SalesTracker/internal/port"
+	"time"
+)
+
+// ApplyBatch проверяет операции пакета и применяет корректные одной транзакцией.
+// Одна запись может изменяться только одной операцией пакета.
+func (u *useCases) ApplyBatch(ctx context.Context, ops []domain.BatchOperation, partial bool) (*domain.BatchReport, error) {
+	if len(ops) == 0 {
+		return nil, domain.NewValidationError("operations", "batch must contain at least one operation")
+	}
+	if len(ops) > domain.MaxBatchOperations {
+		return nil, domain.NewValidationError("operations", fmt.Sprintf("batch must not contain more than %d operations", domain.MaxBatchOperations))
+	}
+
+	report := &domain.BatchReport{Partial: partial, Results: make([]domain.BatchResult, len(ops))}
+	batch := &domain.ItemBatch{}
+	opByID := make(map[int64]int)
+	now := time.Now()
+
+	for i := range ops {
+		op := &ops[i]
+		report.Results[i] = domain.BatchResult{Index: i, Op: op.Op}
+		if err := op.Validate(); err != nil {
+			report.Fail(i, err)
+			continue
+		}
+		if op.Op != domain.BatchCreate {
+			if prev, ok := opByID[op.ID]; ok {
+				report.Fail(i, domain.NewValidationError("id", fmt.Sprintf("item %d is already changed by operation %d", op.ID, prev)))
+				continue
+			}
+			opByID[op.ID] = i
+		}
+
+		switch op.Op {
+		case domain.BatchCreate:
+			op.Item.ID, op.Item.Version = 0, 0
+			op.Item.CreatedAt, op.Item.UpdatedAt = now, now
+			batch.Create = append(batch.Create, op.Item)
+			report.Results[i].Item = op.Item
+		case domain.BatchUpdate:
+			op.Item.ID, op.Item.Version = op.ID, op.Version
+			op.Item.UpdatedAt = now
+			batch.Update = append(batch.Update, op.Item)
+			report.Results[i].Item = op.Item
+		case domain.BatchDelete:
+			batch.Delete = append(batch.Delete, domain.ItemRef{ID: op.ID, Version: op.Version})
+		}
+	}
+
+	if partial || report.FirstError() == nil {
+		if len(batch.Create)+len(batch.Update)+len(batch.Delete) > 0 {
+			var err error
+			if report, err = u.applyBatch(ctx, ops, batch, opByID, report); err != nil {
+				return nil, err
+			}
+		}
+	}
+	report.Finish()
+
+	if partial {
+		return report, nil
+	}
+	return report, report.FirstError()
+}
+
+// applyBatch одной транзакцией читает состояние изменяемых записей, применяет пакет
+// и сохраняет историю успешных операций. Возвращает копию report с результатами
+// применения: при повторе транзакции результаты считаются заново.
+func (u *useCases) applyBatch(ctx context.Context, ops []domain.BatchOperation, batch *domain.ItemBatch, opByID map[int64]int, report *domain.BatchReport) (*domain.BatchReport, error) {
+	var applied *domain.BatchReport
+	err := u.inTx(ctx, func(ctx context.Context, repo port.Repository) error {
+		// ApplyItemBatch меняет идентификаторы и версии записей, при повторе транзакции нужны исходные
+		for i := range ops {
+			op := &ops[i]
+			if report.Results[i].Status == domain.BatchStatusFailed {
+				continue
+			}
+			switch op.Op {
+			case domain.BatchCreate:
+				op.Item.ID, op.Item.Version = 0, 0
+			case domain.BatchUpdate:
+				op.Item.ID, op.Item.Version = op.ID, op.Version
+			}
+		}
+
+		before, err := itemsBefore(ctx, repo, opByID)
+		if err != nil {
+			return err
+		}
+		failed, err := repo.ApplyItemBatch(ctx, batch, report.Partial)
+		if err != nil {
+			return err
+		}
+
+		applied = &domain.BatchReport{Partial: report.Partial, Results: append([]domain.BatchResult(nil), report.Results...)}
+		for id, err := range failed {
+			applied.Fail(opByID[id], err)
+		}
+		applied.Finish()
+		return u.saveHistory(ctx, repo, batchHistory(ctx, ops, applied, before)...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
+
+// itemsBefore читает текущее состояние записей, которые изменяет пакет
+func itemsBefore(ctx context.Context, repo port.Repository, opByID map[int64]int) (map[int64]*domain.Item, error) {
+	if len(opByID) == 0 {
+		return nil, nil
+	}
+	ids := make([]int64, 0, len(opByID))
+	for id := range opByID {
+		ids = append(ids, id)
+	}
+	items, err := repo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	before := make(map[int64]*domain.Item, len(items))
+	for _, item := range items {
+		before[item.ID] = item
+	}
+	return before, nil
+}
+
+// batchHistory возвращает историю успешно выполненных операций пакета
+func batchHistory(ctx context.Context, ops []domain.BatchOperation, report *domain.BatchReport, before map[int64]*domain.Item) []*domain.HistoryEntry {
+	var entries []*domain.HistoryEntry
+	for i, result := range report.Results {
+		if result.Status != domain.BatchStatusOK {
+			continue
+		}
+		var entry *domain.HistoryEntry
+		switch result.Op {
+		case domain.BatchCreate:
+			entry = domain.NewHistoryEntry(ctx, domain.HistoryCreate, nil, result.Item)
+		case domain.BatchUpdate:
+			entry = domain.NewHistoryEntry(ctx, domain.HistoryUpdate, before[ops[i].ID], result.Item)
+		case domain.BatchDelete:
+			entry = domain.NewHistoryEntry(ctx, domain.HistoryDelete, before[ops[i].ID], nil)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
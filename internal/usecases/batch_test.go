@@ -0,0 +1,127 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestUseCases_ApplyBatch(t *testing.T) {
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	newItem := func(category string) *domain.Item {
+		return &domain.Item{Type: "expense", Amount: domain.NewMoney(100, 0), Category: category, Date: date}
+	}
+	newOps := func() []domain.BatchOperation {
+		return []domain.BatchOperation{
+			{Op: domain.BatchCreate, Item: newItem("Food")},
+			{Op: domain.BatchUpdate, ID: 7, Version: 2, Item: newItem("Cafe")},
+			{Op: domain.BatchDelete, ID: 8},
+			{Op: domain.BatchCreate, Item: newItem("")},
+			{Op: domain.BatchDelete, ID: 7},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		ops           []domain.BatchOperation
+		partial       bool
+		failed        map[int64]error
+		wantErr       error
+		wantApplied   bool
+		wantStatuses  []string
+		wantSucceeded int
+	}{
+		{
+			name:         "invalid operation cancels whole batch",
+			ops:          newOps(),
+			wantErr:      domain.ErrValidation,
+			wantStatuses: []string{"skipped", "skipped", "skipped", "failed", "failed"},
+		},
+		{
+			name:          "partial batch applies valid operations",
+			ops:           newOps(),
+			partial:       true,
+			wantApplied:   true,
+			wantStatuses:  []string{"ok", "ok", "ok", "failed", "failed"},
+			wantSucceeded: 3,
+		},
+		{
+			name:          "repository failures are reported per operation",
+			ops:           newOps(),
+			partial:       true,
+			failed:        map[int64]error{8: domain.NewNotFoundError("item not found")},
+			wantApplied:   true,
+			wantStatuses:  []string{"ok", "ok", "failed", "failed", "failed"},
+			wantSucceeded: 2,
+		},
+		{
+			name:         "repository failure rolls back atomic batch",
+			ops:          newOps()[:3],
+			failed:       map[int64]error{7: domain.ErrVersionMismatch},
+			wantErr:      domain.ErrVersionMismatch,
+			wantApplied:  true,
+			wantStatuses: []string{"skipped", "failed", "skipped"},
+		},
+		{
+			name:          "atomic batch",
+			ops:           newOps()[:3],
+			wantApplied:   true,
+			wantStatuses:  []string{"ok", "ok", "ok"},
+			wantSucceeded: 3,
+		},
+		{
+			name:    "empty batch",
+			wantErr: domain.ErrValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var applied *domain.ItemBatch
+			uc := New(&mockRepository{
+				applyBatch: func(ctx context.Context, batch *domain.ItemBatch, partial bool) (map[int64]error, error) {
+					applied = batch
+					for i, item := range batch.Create {
+						item.ID = int64(100 + i)
+					}
+					return tt.failed, nil
+				},
+			})
+
+			report, err := uc.ApplyBatch(context.Background(), tt.ops, tt.partial)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ApplyBatch() error = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("ApplyBatch() unexpected error: %v", err)
+			}
+			if (applied != nil) != tt.wantApplied {
+				t.Errorf("ApplyBatch() repository called = %v, want %v", applied != nil, tt.wantApplied)
+			}
+			if tt.wantStatuses == nil {
+				return
+			}
+
+			wantFailed := 0
+			for i, want := range tt.wantStatuses {
+				if got := report.Results[i].Status; got != want {
+					t.Errorf("result %d status = %s, want %s (%s)", i, got, want, report.Results[i].Error)
+				}
+				if want == domain.BatchStatusFailed {
+					wantFailed++
+				}
+			}
+			if report.Succeeded != tt.wantSucceeded || report.Failed != wantFailed {
+				t.Errorf("ApplyBatch() succeeded = %d, failed = %d, want %d, %d", report.Succeeded, report.Failed, tt.wantSucceeded, wantFailed)
+			}
+			if applied != nil && len(applied.Update) > 0 {
+				if update := applied.Update[0]; update.ID != 7 || update.Version != 2 || update.Currency != "RUB" {
+					t.Errorf("update item = %+v, want id 7, version 2, normalized", update)
+				}
+			}
+		})
+	}
+}
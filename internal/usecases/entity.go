@@ -2,49 +2,213 @@ package usecases
 
 import (
 	"context"
+	"fmt"
 	"github.com/dontpanicw/SalesTracker/internal/domain"
 	"github.com/dontpanicw/SalesTracker/internal/port"
 	"time"
 )
 
 type useCases struct {
-	repo port.Repository
+	repo             port.Repository
+	txManager        port.TxManager         // nil, если хранилище не поддерживает транзакции
+	poolStats        port.PoolStatsProvider // nil, если хранилище работает без пула соединений
+	idempotencyTTL   time.Duration
+	idempotencyLease time.Duration
+	trashRetention   time.Duration
+}
+
+// Option настраивает use cases
+type Option func(*useCases)
+
+// WithIdempotencyTTL задает, сколько хранится ответ на запрос с ключом идемпотентности
+func WithIdempotencyTTL(ttl time.Duration) Option {
+	return func(u *useCases) {
+		u.idempotencyTTL = ttl
+	}
+}
+
+// WithIdempotencyLease задает, сколько ключ идемпотентности занят первым запросом,
+// прежде чем повтор сможет его перехватить
+func WithIdempotencyLease(lease time.Duration) Option {
+	return func(u *useCases) {
+		u.idempotencyLease = lease
+	}
+}
+
+// WithTrashRetention задает, сколько удаленная запись хранится в корзине
+func WithTrashRetention(retention time.Duration) Option {
+	return func(u *useCases) {
+		u.trashRetention = retention
+	}
+}
+
+// WithTxManager задает транзакции хранилища: изменение записи и его история
+// сохраняются атомарно
+func WithTxManager(txManager port.TxManager) Option {
+	return func(u *useCases) {
+		u.txManager = txManager
+	}
+}
+
+// WithPoolStats задает источник статистики пула соединений с базой
+func WithPoolStats(provider port.PoolStatsProvider) Option {
+	return func(u *useCases) {
+		u.poolStats = provider
+	}
 }
 
 // New создает новый экземпляр use cases
-func New(repo port.Repository) port.UseCases {
-	return &useCases{repo: repo}
+func New(repo port.Repository, opts ...Option) port.UseCases {
+	u := &useCases{
+		repo:             repo,
+		idempotencyTTL:   domain.DefaultIdempotencyTTL,
+		idempotencyLease: domain.DefaultIdempotencyLease,
+		trashRetention:   domain.DefaultTrashRetention,
+	}
+	for _, opt := range opts {
+		opt(u)
+	}
+	return u
 }
 
 func (u *useCases) CreateItem(ctx context.Context, item *domain.Item) error {
+	item.Normalize()
 	if err := item.Validate(); err != nil {
 		return err
 	}
 	item.CreatedAt = time.Now()
 	item.UpdatedAt = time.Now()
-	return u.repo.Create(ctx, item)
+	return u.inTx(ctx, func(ctx context.Context, repo port.Repository) error {
+		if err := repo.Create(ctx, item); err != nil {
+			return err
+		}
+		return u.saveHistory(ctx, repo, domain.NewHistoryEntry(ctx, domain.HistoryCreate, nil, item))
+	})
 }
 
 func (u *useCases) GetItem(ctx context.Context, id int64) (*domain.Item, error) {
 	return u.repo.GetByID(ctx, id)
 }
 
-func (u *useCases) GetItems(ctx context.Context, from, to *time.Time) ([]*domain.Item, error) {
-	return u.repo.GetAll(ctx, from, to)
+func (u *useCases) GetItems(ctx context.Context, filter domain.ItemFilter) (*domain.ItemPage, error) {
+	filter.Normalize()
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	return u.repo.GetAll(ctx, filter)
+}
+
+// ExportItems передает в fn все записи по фильтру в порядке сортировки.
+// Курсор и размер страницы фильтра не учитываются.
+func (u *useCases) ExportItems(ctx context.Context, filter domain.ItemFilter, fn func(*domain.Item) error) error {
+	filter.Cursor = ""
+	filter.Limit = 0
+	filter.Normalize()
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+	return u.repo.StreamAll(ctx, filter, fn)
 }
 
 func (u *useCases) UpdateItem(ctx context.Context, item *domain.Item) error {
+	item.Normalize()
 	if err := item.Validate(); err != nil {
 		return err
 	}
 	item.UpdatedAt = time.Now()
-	return u.repo.Update(ctx, item)
+	version := item.Version
+	return u.inTx(ctx, func(ctx context.Context, repo port.Repository) error {
+		// Update меняет версию в item, при повторе транзакции нужна исходная
+		item.Version = version
+		before, err := repo.GetByID(ctx, item.ID)
+		if err != nil {
+			return err
+		}
+		if err := repo.Update(ctx, item); err != nil {
+			return err
+		}
+		return u.saveHistory(ctx, repo, domain.NewHistoryEntry(ctx, domain.HistoryUpdate, before, item))
+	})
+}
+
+func (u *useCases) DeleteItem(ctx context.Context, id int64, version int64) error {
+	return u.inTx(ctx, func(ctx context.Context, repo port.Repository) error {
+		before, err := repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := repo.Delete(ctx, id, version); err != nil {
+			return err
+		}
+		return u.saveHistory(ctx, repo, domain.NewHistoryEntry(ctx, domain.HistoryDelete, before, nil))
+	})
+}
+
+func (u *useCases) RestoreItem(ctx context.Context, id int64, version int64) (*domain.Item, error) {
+	var item *domain.Item
+	err := u.inTx(ctx, func(ctx context.Context, repo port.Repository) error {
+		var err error
+		if item, err = repo.Restore(ctx, id, version); err != nil {
+			return err
+		}
+		return u.saveHistory(ctx, repo, domain.NewHistoryEntry(ctx, domain.HistoryRestore, nil, item))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (u *useCases) PurgeTrash(ctx context.Context) (int64, error) {
+	return u.repo.PurgeDeleted(ctx, time.Now().Add(-u.trashRetention))
+}
+
+func (u *useCases) GetAnalytics(ctx context.Context, query domain.AnalyticsQuery) (*domain.AnalyticsReport, error) {
+	if err := normalizeReportCurrency(&query.Currency); err != nil {
+		return nil, err
+	}
+	return u.repo.GetAnalytics(ctx, query)
 }
 
-func (u *useCases) DeleteItem(ctx context.Context, id int64) error {
-	return u.repo.Delete(ctx, id)
+func (u *useCases) GetGroupedAnalytics(ctx context.Context, query domain.AnalyticsQuery) ([]*domain.AnalyticsGroup, error) {
+	if len(query.GroupBy) == 0 {
+		return nil, domain.NewValidationError("group_by", "group_by is required")
+	}
+	if err := normalizeReportCurrency(&query.Currency); err != nil {
+		return nil, err
+	}
+	return u.repo.GetGroupedAnalytics(ctx, query)
 }
 
-func (u *useCases) GetAnalytics(ctx context.Context, from, to time.Time) (*domain.Analytics, error) {
-	return u.repo.GetAnalytics(ctx, from, to)
+func (u *useCases) GetTimeSeries(ctx context.Context, query domain.TimeSeriesQuery) ([]*domain.TimeSeriesPoint, error) {
+	if err := domain.ValidateInterval(query.Interval); err != nil {
+		return nil, err
+	}
+	if query.From.After(query.To) {
+		return nil, domain.NewValidationError("from", "'from' must not be after 'to'")
+	}
+	if query.Location == nil {
+		query.Location = time.UTC
+	}
+	if n := query.PointCount(); n > domain.MaxTimeSeriesPoints {
+		return nil, domain.NewValidationError("interval", fmt.Sprintf(
+			"time series would have %d points, at most %d are allowed: narrow the period or use a longer interval",
+			n, domain.MaxTimeSeriesPoints))
+	}
+	if err := normalizeReportCurrency(&query.Currency); err != nil {
+		return nil, err
+	}
+	return u.repo.GetTimeSeries(ctx, query)
+}
+
+// normalizeReportCurrency подставляет валюту отчета по умолчанию и проверяет код
+func normalizeReportCurrency(currency *string) error {
+	*currency = domain.NormalizeCurrency(*currency)
+	if *currency == "" {
+		*currency = domain.DefaultCurrency
+	}
+	if !domain.IsValidCurrency(*currency) {
+		return domain.NewValidationError("currency", "currency must be an ISO 4217 code")
+	}
+	return nil
 }
@@ -4,17 +4,34 @@ import (
 	"context"
 	"errors"
 	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"reflect"
 	"testing"
 	"time"
 )
 
 type mockRepository struct {
 	createFunc   func(ctx context.Context, item *domain.Item) error
+	createMany   func(ctx context.Context, items []*domain.Item) error
 	getByIDFunc  func(ctx context.Context, id int64) (*domain.Item, error)
-	getAllFunc   func(ctx context.Context, from, to *time.Time) ([]*domain.Item, error)
+	getByIDs     func(ctx context.Context, ids []int64) ([]*domain.Item, error)
+	getAllFunc   func(ctx context.Context, filter domain.ItemFilter) (*domain.ItemPage, error)
+	streamAll    func(ctx context.Context, filter domain.ItemFilter, fn func(*domain.Item) error) error
 	updateFunc   func(ctx context.Context, item *domain.Item) error
-	deleteFunc   func(ctx context.Context, id int64) error
-	getAnalytics func(ctx context.Context, from, to time.Time) (*domain.Analytics, error)
+	deleteFunc   func(ctx context.Context, id int64, version int64) error
+	restoreFunc  func(ctx context.Context, id int64, version int64) (*domain.Item, error)
+	purgeDeleted func(ctx context.Context, before time.Time) (int64, error)
+	applyBatch   func(ctx context.Context, batch *domain.ItemBatch, partial bool) (map[int64]error, error)
+	getAnalytics func(ctx context.Context, query domain.AnalyticsQuery) (*domain.AnalyticsReport, error)
+	getGrouped   func(ctx context.Context, query domain.AnalyticsQuery) ([]*domain.AnalyticsGroup, error)
+	getSeries    func(ctx context.Context, query domain.TimeSeriesQuery) ([]*domain.TimeSeriesPoint, error)
+	createRate   func(ctx context.Context, rate *domain.ExchangeRate) error
+	getRates     func(ctx context.Context, from, to string) ([]*domain.ExchangeRate, error)
+	deleteRate   func(ctx context.Context, id int64) error
+	reserveKey   func(ctx context.Context, record *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error)
+	completeKey  func(ctx context.Context, record *domain.IdempotencyRecord, response []byte) error
+	releaseKey   func(ctx context.Context, record *domain.IdempotencyRecord) error
+	addHistory   func(ctx context.Context, entries []*domain.HistoryEntry) error
+	getHistory   func(ctx context.Context, filter domain.HistoryFilter) (*domain.HistoryPage, error)
 }
 
 func (m *mockRepository) Create(ctx context.Context, item *domain.Item) error {
@@ -24,6 +41,13 @@ func (m *mockRepository) Create(ctx context.Context, item *domain.Item) error {
 	return nil
 }
 
+func (m *mockRepository) CreateMany(ctx context.Context, items []*domain.Item) error {
+	if m.createMany != nil {
+		return m.createMany(ctx, items)
+	}
+	return nil
+}
+
 func (m *mockRepository) GetByID(ctx context.Context, id int64) (*domain.Item, error) {
 	if m.getByIDFunc != nil {
 		return m.getByIDFunc(ctx, id)
@@ -31,13 +55,27 @@ func (m *mockRepository) GetByID(ctx context.Context, id int64) (*domain.Item, e
 	return nil, nil
 }
 
-func (m *mockRepository) GetAll(ctx context.Context, from, to *time.Time) ([]*domain.Item, error) {
-	if m.getAllFunc != nil {
-		return m.getAllFunc(ctx, from, to)
+func (m *mockRepository) GetByIDs(ctx context.Context, ids []int64) ([]*domain.Item, error) {
+	if m.getByIDs != nil {
+		return m.getByIDs(ctx, ids)
 	}
 	return nil, nil
 }
 
+func (m *mockRepository) GetAll(ctx context.Context, filter domain.ItemFilter) (*domain.ItemPage, error) {
+	if m.getAllFunc != nil {
+		return m.getAllFunc(ctx, filter)
+	}
+	return &domain.ItemPage{}, nil
+}
+
+func (m *mockRepository) StreamAll(ctx context.Context, filter domain.ItemFilter, fn func(*domain.Item) error) error {
+	if m.streamAll != nil {
+		return m.streamAll(ctx, filter, fn)
+	}
+	return nil
+}
+
 func (m *mockRepository) Update(ctx context.Context, item *domain.Item) error {
 	if m.updateFunc != nil {
 		return m.updateFunc(ctx, item)
@@ -45,20 +83,111 @@ func (m *mockRepository) Update(ctx context.Context, item *domain.Item) error {
 	return nil
 }
 
-func (m *mockRepository) Delete(ctx context.Context, id int64) error {
+func (m *mockRepository) Delete(ctx context.Context, id int64, version int64) error {
 	if m.deleteFunc != nil {
-		return m.deleteFunc(ctx, id)
+		return m.deleteFunc(ctx, id, version)
+	}
+	return nil
+}
+
+func (m *mockRepository) Restore(ctx context.Context, id int64, version int64) (*domain.Item, error) {
+	if m.restoreFunc != nil {
+		return m.restoreFunc(ctx, id, version)
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	if m.purgeDeleted != nil {
+		return m.purgeDeleted(ctx, before)
+	}
+	return 0, nil
+}
+
+func (m *mockRepository) ReserveIdempotencyKey(ctx context.Context, record *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error) {
+	if m.reserveKey != nil {
+		return m.reserveKey(ctx, record)
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) CompleteIdempotencyKey(ctx context.Context, record *domain.IdempotencyRecord, response []byte) error {
+	if m.completeKey != nil {
+		return m.completeKey(ctx, record, response)
+	}
+	return nil
+}
+
+func (m *mockRepository) ReleaseIdempotencyKey(ctx context.Context, record *domain.IdempotencyRecord) error {
+	if m.releaseKey != nil {
+		return m.releaseKey(ctx, record)
+	}
+	return nil
+}
+
+func (m *mockRepository) AddHistory(ctx context.Context, entries []*domain.HistoryEntry) error {
+	if m.addHistory != nil {
+		return m.addHistory(ctx, entries)
 	}
 	return nil
 }
 
-func (m *mockRepository) GetAnalytics(ctx context.Context, from, to time.Time) (*domain.Analytics, error) {
+func (m *mockRepository) GetHistory(ctx context.Context, filter domain.HistoryFilter) (*domain.HistoryPage, error) {
+	if m.getHistory != nil {
+		return m.getHistory(ctx, filter)
+	}
+	return &domain.HistoryPage{}, nil
+}
+
+func (m *mockRepository) ApplyItemBatch(ctx context.Context, batch *domain.ItemBatch, partial bool) (map[int64]error, error) {
+	if m.applyBatch != nil {
+		return m.applyBatch(ctx, batch, partial)
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) GetAnalytics(ctx context.Context, query domain.AnalyticsQuery) (*domain.AnalyticsReport, error) {
 	if m.getAnalytics != nil {
-		return m.getAnalytics(ctx, from, to)
+		return m.getAnalytics(ctx, query)
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) GetGroupedAnalytics(ctx context.Context, query domain.AnalyticsQuery) ([]*domain.AnalyticsGroup, error) {
+	if m.getGrouped != nil {
+		return m.getGrouped(ctx, query)
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) GetTimeSeries(ctx context.Context, query domain.TimeSeriesQuery) ([]*domain.TimeSeriesPoint, error) {
+	if m.getSeries != nil {
+		return m.getSeries(ctx, query)
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) CreateRate(ctx context.Context, rate *domain.ExchangeRate) error {
+	if m.createRate != nil {
+		return m.createRate(ctx, rate)
+	}
+	return nil
+}
+
+func (m *mockRepository) GetRates(ctx context.Context, from, to string) ([]*domain.ExchangeRate, error) {
+	if m.getRates != nil {
+		return m.getRates(ctx, from, to)
 	}
 	return nil, nil
 }
 
+func (m *mockRepository) DeleteRate(ctx context.Context, id int64) error {
+	if m.deleteRate != nil {
+		return m.deleteRate(ctx, id)
+	}
+	return nil
+}
+
 func TestUseCases_CreateItem(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -70,7 +199,7 @@ func TestUseCases_CreateItem(t *testing.T) {
 			name: "successful creation",
 			item: &domain.Item{
 				Type:     "income",
-				Amount:   1000.00,
+				Amount:   domain.NewMoney(1000, 0),
 				Category: "Salary",
 				Date:     time.Now(),
 			},
@@ -82,11 +211,23 @@ func TestUseCases_CreateItem(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "unknown currency",
+			item: &domain.Item{
+				Type:     "income",
+				Amount:   domain.NewMoney(1000, 0),
+				Currency: "ABC",
+				Category: "Salary",
+				Date:     time.Now(),
+			},
+			mock:    &mockRepository{},
+			wantErr: true,
+		},
 		{
 			name: "validation error",
 			item: &domain.Item{
 				Type:     "income",
-				Amount:   -100.00,
+				Amount:   domain.NewMoney(-100, 0),
 				Category: "Salary",
 				Date:     time.Now(),
 			},
@@ -97,7 +238,7 @@ func TestUseCases_CreateItem(t *testing.T) {
 			name: "repository error",
 			item: &domain.Item{
 				Type:     "income",
-				Amount:   1000.00,
+				Amount:   domain.NewMoney(1000, 0),
 				Category: "Salary",
 				Date:     time.Now(),
 			},
@@ -121,6 +262,73 @@ func TestUseCases_CreateItem(t *testing.T) {
 	}
 }
 
+func TestUseCases_GetItems(t *testing.T) {
+	minAmount, maxAmount := domain.NewMoney(100, 0), domain.NewMoney(10, 0)
+
+	tests := []struct {
+		name      string
+		filter    domain.ItemFilter
+		wantLimit int
+		wantSort  string
+		wantErr   bool
+	}{
+		{name: "defaults", filter: domain.ItemFilter{}, wantLimit: domain.DefaultPageLimit, wantSort: "-date"},
+		{name: "custom sort", filter: domain.ItemFilter{Sort: domain.ItemSort{Field: domain.SortByAmount}, Limit: 10}, wantLimit: 10, wantSort: "amount"},
+		{name: "limit too large", filter: domain.ItemFilter{Limit: domain.MaxPageLimit + 1}, wantErr: true},
+		{name: "invalid type", filter: domain.ItemFilter{Types: []string{"transfer"}}, wantErr: true},
+		{name: "reversed amount range", filter: domain.ItemFilter{MinAmount: &minAmount, MaxAmount: &maxAmount}, wantErr: true},
+		{name: "invalid cursor", filter: domain.ItemFilter{Cursor: "???"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got domain.ItemFilter
+			uc := New(&mockRepository{
+				getAllFunc: func(ctx context.Context, filter domain.ItemFilter) (*domain.ItemPage, error) {
+					got = filter
+					return &domain.ItemPage{}, nil
+				},
+			})
+			_, err := uc.GetItems(context.Background(), tt.filter)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetItems() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Limit != tt.wantLimit || got.Sort.String() != tt.wantSort {
+				t.Errorf("GetItems() passed limit %d sort %q, want %d %q", got.Limit, got.Sort, tt.wantLimit, tt.wantSort)
+			}
+		})
+	}
+}
+
+func TestUseCases_ExportItems(t *testing.T) {
+	var got domain.ItemFilter
+	uc := New(&mockRepository{
+		streamAll: func(ctx context.Context, filter domain.ItemFilter, fn func(*domain.Item) error) error {
+			got = filter
+			return fn(&domain.Item{ID: 1})
+		},
+	})
+
+	var ids []int64
+	err := uc.ExportItems(context.Background(), domain.ItemFilter{Cursor: "ignored", Limit: 5000}, func(item *domain.Item) error {
+		ids = append(ids, item.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ExportItems() error = %v", err)
+	}
+	if got.Cursor != "" || got.Sort != domain.DefaultItemSort {
+		t.Errorf("ExportItems() passed filter %+v, want default sort without cursor", got)
+	}
+	if len(ids) != 1 {
+		t.Errorf("ExportItems() streamed %d items, want 1", len(ids))
+	}
+}
+
 func TestUseCases_UpdateItem(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -133,7 +341,7 @@ func TestUseCases_UpdateItem(t *testing.T) {
 			item: &domain.Item{
 				ID:       1,
 				Type:     "expense",
-				Amount:   500.00,
+				Amount:   domain.NewMoney(500, 0),
 				Category: "Food",
 				Date:     time.Now(),
 			},
@@ -149,7 +357,7 @@ func TestUseCases_UpdateItem(t *testing.T) {
 			item: &domain.Item{
 				ID:       1,
 				Type:     "invalid",
-				Amount:   500.00,
+				Amount:   domain.NewMoney(500, 0),
 				Category: "Food",
 				Date:     time.Now(),
 			},
@@ -180,7 +388,7 @@ func TestUseCases_DeleteItem(t *testing.T) {
 			name: "successful deletion",
 			id:   1,
 			mock: &mockRepository{
-				deleteFunc: func(ctx context.Context, id int64) error {
+				deleteFunc: func(ctx context.Context, id int64, version int64) error {
 					return nil
 				},
 			},
@@ -190,7 +398,7 @@ func TestUseCases_DeleteItem(t *testing.T) {
 			name: "item not found",
 			id:   999,
 			mock: &mockRepository{
-				deleteFunc: func(ctx context.Context, id int64) error {
+				deleteFunc: func(ctx context.Context, id int64, version int64) error {
 					return errors.New("item not found")
 				},
 			},
@@ -201,7 +409,7 @@ func TestUseCases_DeleteItem(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			uc := New(tt.mock)
-			err := uc.DeleteItem(context.Background(), tt.id)
+			err := uc.DeleteItem(context.Background(), tt.id, 0)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("DeleteItem() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -209,62 +417,168 @@ func TestUseCases_DeleteItem(t *testing.T) {
 	}
 }
 
+func TestUseCases_RestoreItem(t *testing.T) {
+	uc := New(&mockRepository{
+		restoreFunc: func(ctx context.Context, id int64, version int64) (*domain.Item, error) {
+			if id != 1 {
+				return nil, domain.NewNotFoundError("item not found")
+			}
+			if version != 0 && version != 2 {
+				return nil, domain.ErrVersionMismatch
+			}
+			return &domain.Item{ID: id, Version: 3}, nil
+		},
+	})
+
+	item, err := uc.RestoreItem(context.Background(), 1, 2)
+	if err != nil || item.Version != 3 {
+		t.Errorf("RestoreItem() = %+v, %v, want version 3", item, err)
+	}
+	if _, err := uc.RestoreItem(context.Background(), 1, 1); !errors.Is(err, domain.ErrVersionMismatch) {
+		t.Errorf("RestoreItem() stale version error = %v, want ErrVersionMismatch", err)
+	}
+	if _, err := uc.RestoreItem(context.Background(), 999, 0); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("RestoreItem() missing item error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestUseCases_PurgeTrash(t *testing.T) {
+	var before time.Time
+	mock := &mockRepository{
+		purgeDeleted: func(ctx context.Context, b time.Time) (int64, error) {
+			before = b
+			return 3, nil
+		},
+	}
+
+	tests := []struct {
+		name      string
+		opts      []Option
+		retention time.Duration
+	}{
+		{name: "default retention", retention: domain.DefaultTrashRetention},
+		{name: "custom retention", opts: []Option{WithTrashRetention(time.Hour)}, retention: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			purged, err := New(mock, tt.opts...).PurgeTrash(context.Background())
+			if err != nil || purged != 3 {
+				t.Fatalf("PurgeTrash() = %d, %v, want 3", purged, err)
+			}
+			want := time.Now().Add(-tt.retention)
+			if d := want.Sub(before); d < 0 || d > time.Minute {
+				t.Errorf("PurgeTrash() before = %v, want about %v", before, want)
+			}
+		})
+	}
+}
+
 func TestUseCases_GetAnalytics(t *testing.T) {
 	from := time.Now().AddDate(0, -1, 0)
 	to := time.Now()
 
 	tests := []struct {
-		name    string
-		mock    *mockRepository
-		want    *domain.Analytics
-		wantErr bool
+		name     string
+		currency string
+		mock     *mockRepository
+		want     *domain.AnalyticsReport
+		wantErr  bool
 	}{
 		{
 			name: "successful analytics",
 			mock: &mockRepository{
-				getAnalytics: func(ctx context.Context, from, to time.Time) (*domain.Analytics, error) {
-					return &domain.Analytics{
-						Sum:        10000.00,
-						Avg:        1000.00,
-						Count:      10,
-						Median:     950.00,
-						Percentile: 1500.00,
+				getAnalytics: func(ctx context.Context, query domain.AnalyticsQuery) (*domain.AnalyticsReport, error) {
+					return &domain.AnalyticsReport{
+						Income:  domain.Analytics{Sum: domain.NewMoney(10000, 0), Avg: domain.NewMoney(1000, 0), Count: 10, Median: domain.NewMoney(950, 0), Percentile: domain.NewMoney(1500, 0)},
+						Expense: domain.Analytics{Sum: domain.NewMoney(4000, 0), Avg: domain.NewMoney(800, 0), Count: 5, Median: domain.NewMoney(700, 0), Percentile: domain.NewMoney(1200, 0)},
+						Balance: domain.NewMoney(6000, 0),
 					}, nil
 				},
 			},
-			want: &domain.Analytics{
-				Sum:        10000.00,
-				Avg:        1000.00,
-				Count:      10,
-				Median:     950.00,
-				Percentile: 1500.00,
+			want: &domain.AnalyticsReport{
+				Income:  domain.Analytics{Sum: domain.NewMoney(10000, 0), Avg: domain.NewMoney(1000, 0), Count: 10, Median: domain.NewMoney(950, 0), Percentile: domain.NewMoney(1500, 0)},
+				Expense: domain.Analytics{Sum: domain.NewMoney(4000, 0), Avg: domain.NewMoney(800, 0), Count: 5, Median: domain.NewMoney(700, 0), Percentile: domain.NewMoney(1200, 0)},
+				Balance: domain.NewMoney(6000, 0),
 			},
 			wantErr: false,
 		},
 		{
 			name: "repository error",
 			mock: &mockRepository{
-				getAnalytics: func(ctx context.Context, from, to time.Time) (*domain.Analytics, error) {
+				getAnalytics: func(ctx context.Context, query domain.AnalyticsQuery) (*domain.AnalyticsReport, error) {
 					return nil, errors.New("database error")
 				},
 			},
 			wantErr: true,
 		},
+		{
+			name:     "default reporting currency",
+			currency: "",
+			mock: &mockRepository{
+				getAnalytics: func(ctx context.Context, query domain.AnalyticsQuery) (*domain.AnalyticsReport, error) {
+					if query.Currency != domain.DefaultCurrency {
+						t.Errorf("GetAnalytics() currency = %v, want %v", query.Currency, domain.DefaultCurrency)
+					}
+					return nil, nil
+				},
+			},
+		},
+		{
+			name:     "invalid reporting currency",
+			currency: "XYZ",
+			mock:     &mockRepository{},
+			wantErr:  true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			uc := New(tt.mock)
-			got, err := uc.GetAnalytics(context.Background(), from, to)
+			got, err := uc.GetAnalytics(context.Background(), domain.AnalyticsQuery{From: from, To: to, Currency: tt.currency})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GetAnalytics() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if !tt.wantErr && got != nil && tt.want != nil {
-				if got.Sum != tt.want.Sum || got.Avg != tt.want.Avg || got.Count != tt.want.Count {
+				if !reflect.DeepEqual(got, tt.want) {
 					t.Errorf("GetAnalytics() = %v, want %v", got, tt.want)
 				}
 			}
 		})
 	}
 }
+
+func TestUseCases_GetTimeSeries(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		interval string
+		wantErr  bool
+	}{
+		{name: "valid request", from: from, to: to, interval: "day"},
+		{name: "invalid interval", from: from, to: to, interval: "hour", wantErr: true},
+		{name: "reversed range", from: to, to: from, interval: "day", wantErr: true},
+		{name: "too many points", from: time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC), to: time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC), interval: "day", wantErr: true},
+		{name: "max points", from: from, to: from.AddDate(0, 0, domain.MaxTimeSeriesPoints-1), interval: "day"},
+		{name: "one point over max", from: from, to: from.AddDate(0, 0, domain.MaxTimeSeriesPoints), interval: "day", wantErr: true},
+		{name: "long period by month", from: from, to: from.AddDate(50, 0, 0), interval: "month"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := New(&mockRepository{})
+			_, err := uc.GetTimeSeries(context.Background(), domain.TimeSeriesQuery{
+				From:     tt.from,
+				To:       tt.to,
+				Interval: tt.interval,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetTimeSeries() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package usecases
+
+import (
+	"context"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"github.com/dontpanicw/SalesTracker/internal/port"
+	"log"
+)
+
+func (u *useCases) GetHistory(ctx context.Context, filter domain.HistoryFilter) (*domain.HistoryPage, error) {
+	filter.Normalize()
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	return u.repo.GetHistory(ctx, filter)
+}
+
+// saveHistory сохраняет историю изменения, выполненного в repo внутри inTx.
+// В транзакции ошибка откатывает и само изменение; без транзакций изменение
+// уже сохранено, поэтому ошибка, как в recordHistory, только логируется.
+func (u *useCases) saveHistory(ctx context.Context, repo port.Repository, entries ...*domain.HistoryEntry) error {
+	if u.txManager == nil {
+		u.recordHistory(ctx, entries...)
+		return nil
+	}
+	return repo.AddHistory(ctx, entries)
+}
+
+// recordHistory сохраняет историю уже выполненных изменений. Изменение
+// отменить нельзя, поэтому ошибка сохранения истории только логируется.
+func (u *useCases) recordHistory(ctx context.Context, entries ...*domain.HistoryEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	if err := u.repo.AddHistory(ctx, entries); err != nil {
+		log.Printf("failed to record history of %d item changes: %v", len(entries), err)
+	}
+}
@@ -0,0 +1,184 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"testing"
+	"time"
+)
+
+// historyRepository возвращает мок хранилища с записью 1 и сохраняет добавленную историю в entries
+func historyRepository(entries *[]*domain.HistoryEntry) *mockRepository {
+	stored := &domain.Item{ID: 1, Type: "expense", Amount: domain.NewMoney(100, 0), Currency: "RUB", Category: "Food", Version: 1}
+	return &mockRepository{
+		createFunc: func(ctx context.Context, item *domain.Item) error {
+			item.ID, item.Version = 2, 1
+			return nil
+		},
+		createMany: func(ctx context.Context, items []*domain.Item) error {
+			for i, item := range items {
+				item.ID, item.Version = int64(10+i), 1
+			}
+			return nil
+		},
+		getByIDFunc: func(ctx context.Context, id int64) (*domain.Item, error) {
+			if id != 1 {
+				return nil, domain.NewNotFoundError("item not found")
+			}
+			copied := *stored
+			return &copied, nil
+		},
+		getByIDs: func(ctx context.Context, ids []int64) ([]*domain.Item, error) {
+			copied := *stored
+			return []*domain.Item{&copied}, nil
+		},
+		updateFunc: func(ctx context.Context, item *domain.Item) error {
+			if item.Version != 0 && item.Version != 1 {
+				return domain.ErrVersionMismatch
+			}
+			item.Version = 2
+			return nil
+		},
+		restoreFunc: func(ctx context.Context, id int64, version int64) (*domain.Item, error) {
+			copied := *stored
+			copied.Version = 3
+			return &copied, nil
+		},
+		addHistory: func(ctx context.Context, added []*domain.HistoryEntry) error {
+			*entries = append(*entries, added...)
+			return nil
+		},
+	}
+}
+
+func TestUseCases_RecordsHistory(t *testing.T) {
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	newItem := func(id, version int64) *domain.Item {
+		return &domain.Item{ID: id, Version: version, Type: "expense", Amount: domain.NewMoney(250, 0), Category: "Cafe", Date: date}
+	}
+
+	tests := []struct {
+		name       string
+		run        func(uc *useCases, ctx context.Context) error
+		wantAction string
+		wantBefore bool
+		wantAfter  bool
+	}{
+		{
+			name: "create",
+			run: func(uc *useCases, ctx context.Context) error {
+				return uc.CreateItem(ctx, newItem(0, 0))
+			},
+			wantAction: domain.HistoryCreate,
+			wantAfter:  true,
+		},
+		{
+			name: "update",
+			run: func(uc *useCases, ctx context.Context) error {
+				return uc.UpdateItem(ctx, newItem(1, 1))
+			},
+			wantAction: domain.HistoryUpdate,
+			wantBefore: true,
+			wantAfter:  true,
+		},
+		{
+			name: "delete",
+			run: func(uc *useCases, ctx context.Context) error {
+				return uc.DeleteItem(ctx, 1, 0)
+			},
+			wantAction: domain.HistoryDelete,
+			wantBefore: true,
+		},
+		{
+			name: "restore",
+			run: func(uc *useCases, ctx context.Context) error {
+				_, err := uc.RestoreItem(ctx, 1, 0)
+				return err
+			},
+			wantAction: domain.HistoryRestore,
+			wantAfter:  true,
+		},
+		{
+			name: "batch update",
+			run: func(uc *useCases, ctx context.Context) error {
+				_, err := uc.ApplyBatch(ctx, []domain.BatchOperation{{Op: domain.BatchUpdate, ID: 1, Item: newItem(0, 0)}}, false)
+				return err
+			},
+			wantAction: domain.HistoryUpdate,
+			wantBefore: true,
+			wantAfter:  true,
+		},
+		{
+			name: "import",
+			run: func(uc *useCases, ctx context.Context) error {
+				_, err := uc.ImportItems(ctx, []domain.ImportRow{{Line: 2, Item: newItem(0, 0)}}, false)
+				return err
+			},
+			wantAction: domain.HistoryCreate,
+			wantAfter:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var entries []*domain.HistoryEntry
+			uc := New(historyRepository(&entries)).(*useCases)
+			ctx := domain.WithActor(context.Background(), "alice")
+
+			if err := tt.run(uc, ctx); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(entries) != 1 {
+				t.Fatalf("recorded %d history entries, want 1", len(entries))
+			}
+			entry := entries[0]
+			if entry.Action != tt.wantAction || entry.Actor != "alice" || entry.ItemID == 0 {
+				t.Errorf("history entry = %+v, want action %s by alice", entry, tt.wantAction)
+			}
+			if (entry.Before != nil) != tt.wantBefore || (entry.After != nil) != tt.wantAfter {
+				t.Errorf("history entry before = %v, after = %v", entry.Before, entry.After)
+			}
+			if tt.wantBefore && tt.wantAfter && entry.Before.Category == entry.After.Category {
+				t.Errorf("history entry before and after are equal: %+v", entry.Before)
+			}
+		})
+	}
+}
+
+func TestUseCases_RecordsHistory_FailedChange(t *testing.T) {
+	var entries []*domain.HistoryEntry
+	uc := New(historyRepository(&entries))
+	ctx := context.Background()
+
+	stale := &domain.Item{ID: 1, Version: 5, Type: "expense", Amount: domain.NewMoney(1, 0), Category: "Food", Date: time.Now()}
+	if err := uc.UpdateItem(ctx, stale); !errors.Is(err, domain.ErrVersionMismatch) {
+		t.Fatalf("UpdateItem() error = %v, want ErrVersionMismatch", err)
+	}
+	if err := uc.DeleteItem(ctx, 999, 0); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("DeleteItem() error = %v, want ErrNotFound", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("failed changes recorded history: %+v", entries)
+	}
+}
+
+func TestUseCases_GetHistory(t *testing.T) {
+	var got domain.HistoryFilter
+	uc := New(&mockRepository{
+		getHistory: func(ctx context.Context, filter domain.HistoryFilter) (*domain.HistoryPage, error) {
+			got = filter
+			return &domain.HistoryPage{}, nil
+		},
+	})
+
+	if _, err := uc.GetHistory(context.Background(), domain.HistoryFilter{Actor: " alice "}); err != nil {
+		t.Fatalf("GetHistory() error = %v", err)
+	}
+	if got.Actor != "alice" || got.Limit != domain.DefaultPageLimit {
+		t.Errorf("GetHistory() filter = %+v, want trimmed actor and default limit", got)
+	}
+	if _, err := uc.GetHistory(context.Background(), domain.HistoryFilter{Cursor: "abc"}); !errors.Is(err, domain.ErrValidation) {
+		t.Errorf("GetHistory() invalid cursor error = %v, want ErrValidation", err)
+	}
+}
@@ -0,0 +1,108 @@
+package usecases
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"github.com/dontpanicw/SalesTracker/internal/port"
+	"log"
+	"time"
+)
+
+// CreateItemIdempotent создает запись один раз для ключа key.
+// Ключ резервируется до записи в базу, поэтому параллельный повтор
+// получает domain.ErrIdempotencyKeyInProgress, а не создает дубликат.
+// Если первый запрос не сохранил ответ за время аренды ключа, повтор перехватывает ключ.
+func (u *useCases) CreateItemIdempotent(ctx context.Context, key string, item *domain.Item) (bool, error) {
+	item.Normalize()
+	if err := item.Validate(); err != nil {
+		return false, err
+	}
+
+	hash, err := itemRequestHash(item)
+	if err != nil {
+		return false, err
+	}
+	now := time.Now()
+	reservation := &domain.IdempotencyRecord{
+		Key:         key,
+		RequestHash: hash,
+		CreatedAt:   now,
+		LockedUntil: now.Add(u.idempotencyLease),
+		ExpiresAt:   now.Add(u.idempotencyTTL),
+	}
+	existing, err := u.repo.ReserveIdempotencyKey(ctx, reservation)
+	if err != nil {
+		return false, err
+	}
+	if existing != nil {
+		return true, replayIdempotent(existing, hash, item)
+	}
+
+	item.CreatedAt = now
+	item.UpdatedAt = now
+	err = u.inTx(ctx, func(ctx context.Context, repo port.Repository) error {
+		if err := repo.Create(ctx, item); err != nil {
+			return err
+		}
+		if err := u.saveHistory(ctx, repo, domain.NewHistoryEntry(ctx, domain.HistoryCreate, nil, item)); err != nil {
+			return err
+		}
+		return u.completeIdempotencyKey(ctx, repo, reservation, item)
+	})
+	if err != nil {
+		if releaseErr := u.repo.ReleaseIdempotencyKey(ctx, reservation); releaseErr != nil {
+			log.Printf("failed to release idempotency key %q: %v", key, releaseErr)
+		}
+		return false, err
+	}
+	return false, nil
+}
+
+// completeIdempotencyKey сохраняет ответ на запрос с ключом в repo внутри inTx.
+// В транзакции ошибка откатывает и созданную запись: ключ либо завершен вместе
+// с записью, либо свободен для повтора, даже если процесс упадет или ключ перехватят.
+// Без транзакций запись уже создана, поэтому ошибка только логируется: повтор
+// получит ErrIdempotencyKeyInProgress, пока не истечет аренда ключа.
+func (u *useCases) completeIdempotencyKey(ctx context.Context, repo port.Repository, reservation *domain.IdempotencyRecord, item *domain.Item) error {
+	response, err := json.Marshal(item)
+	if err == nil {
+		err = repo.CompleteIdempotencyKey(ctx, reservation, response)
+	}
+	if err != nil && u.txManager == nil {
+		log.Printf("failed to store response for idempotency key %q: %v", reservation.Key, err)
+		return nil
+	}
+	return err
+}
+
+// replayIdempotent заполняет item сохраненным ответом, если повтор совпадает с исходным запросом
+func replayIdempotent(existing *domain.IdempotencyRecord, hash string, item *domain.Item) error {
+	if existing.RequestHash != hash {
+		return domain.ErrIdempotencyKeyReused
+	}
+	if existing.Response == nil {
+		return domain.ErrIdempotencyKeyInProgress
+	}
+	*item = domain.Item{}
+	return json.Unmarshal(existing.Response, item)
+}
+
+// itemRequestHash вычисляет хеш полей записи, которые передает клиент
+func itemRequestHash(item *domain.Item) (string, error) {
+	data, err := json.Marshal(struct {
+		Type        string
+		Amount      domain.Money
+		Currency    string
+		Category    string
+		Description string
+		Date        time.Time
+	}{item.Type, item.Amount, item.Currency, item.Category, item.Description, item.Date.UTC()})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,204 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"testing"
+	"time"
+)
+
+// newIdempotencyRepo возвращает мок, который хранит ключи в памяти и считает созданные записи.
+// Незавершенный ключ с истекшей арендой перехватывается, как в репозиториях.
+func newIdempotencyRepo(created *int, createErr error) (*mockRepository, map[string]*domain.IdempotencyRecord) {
+	keys := make(map[string]*domain.IdempotencyRecord)
+	return &mockRepository{
+		createFunc: func(ctx context.Context, item *domain.Item) error {
+			if createErr != nil {
+				return createErr
+			}
+			*created++
+			item.ID = int64(*created)
+			item.Version = 1
+			return nil
+		},
+		reserveKey: func(ctx context.Context, record *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error) {
+			if existing, ok := keys[record.Key]; ok && (existing.Response != nil || !existing.LockedUntil.Before(record.CreatedAt)) {
+				return existing, nil
+			}
+			keys[record.Key] = record
+			return nil, nil
+		},
+		completeKey: func(ctx context.Context, record *domain.IdempotencyRecord, response []byte) error {
+			if keys[record.Key] != record {
+				return domain.ErrIdempotencyKeyTakenOver
+			}
+			record.Response = response
+			return nil
+		},
+		releaseKey: func(ctx context.Context, record *domain.IdempotencyRecord) error {
+			if keys[record.Key] == record {
+				delete(keys, record.Key)
+			}
+			return nil
+		},
+	}, keys
+}
+
+func TestUseCases_CreateItemIdempotent(t *testing.T) {
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	newItem := func() *domain.Item {
+		return &domain.Item{Type: "expense", Amount: domain.NewMoney(250, 0), Category: "Food", Date: date}
+	}
+
+	t.Run("repeat returns stored item", func(t *testing.T) {
+		var created int
+		repo, _ := newIdempotencyRepo(&created, nil)
+		uc := New(repo)
+
+		first := newItem()
+		replayed, err := uc.CreateItemIdempotent(context.Background(), "key-1", first)
+		if err != nil || replayed {
+			t.Fatalf("CreateItemIdempotent() = %v, %v, want false, nil", replayed, err)
+		}
+
+		// Повтор с тем же содержимым, валюта в другом регистре
+		second := newItem()
+		second.Currency = "rub"
+		replayed, err = uc.CreateItemIdempotent(context.Background(), "key-1", second)
+		if err != nil || !replayed {
+			t.Fatalf("CreateItemIdempotent() repeat = %v, %v, want true, nil", replayed, err)
+		}
+		if created != 1 {
+			t.Errorf("created %d items, want 1", created)
+		}
+		if second.ID != first.ID || !second.CreatedAt.Equal(first.CreatedAt) {
+			t.Errorf("repeat returned %+v, want %+v", second, first)
+		}
+	})
+
+	t.Run("same key with different body", func(t *testing.T) {
+		var created int
+		repo, _ := newIdempotencyRepo(&created, nil)
+		uc := New(repo)
+
+		uc.CreateItemIdempotent(context.Background(), "key-1", newItem())
+		other := newItem()
+		other.Amount = domain.NewMoney(300, 0)
+		_, err := uc.CreateItemIdempotent(context.Background(), "key-1", other)
+		if !errors.Is(err, domain.ErrIdempotencyKeyReused) {
+			t.Errorf("CreateItemIdempotent() error = %v, want ErrIdempotencyKeyReused", err)
+		}
+	})
+
+	t.Run("first request still in progress", func(t *testing.T) {
+		var created int
+		repo, keys := newIdempotencyRepo(&created, nil)
+		uc := New(repo)
+
+		pending := newItem()
+		pending.Normalize()
+		hash, _ := itemRequestHash(pending)
+		keys["key-1"] = &domain.IdempotencyRecord{Key: "key-1", RequestHash: hash, LockedUntil: time.Now().Add(time.Minute)}
+		_, err := uc.CreateItemIdempotent(context.Background(), "key-1", newItem())
+		if !errors.Is(err, domain.ErrIdempotencyKeyInProgress) || !errors.Is(err, domain.ErrConflict) {
+			t.Errorf("CreateItemIdempotent() error = %v, want ErrIdempotencyKeyInProgress", err)
+		}
+	})
+
+	t.Run("expired lease is taken over", func(t *testing.T) {
+		var created int
+		repo, keys := newIdempotencyRepo(&created, nil)
+		uc := New(repo)
+
+		// Первый запрос упал, не сохранив ответ
+		pending := newItem()
+		pending.Normalize()
+		hash, _ := itemRequestHash(pending)
+		keys["key-1"] = &domain.IdempotencyRecord{Key: "key-1", RequestHash: hash, LockedUntil: time.Now().Add(-time.Second)}
+
+		replayed, err := uc.CreateItemIdempotent(context.Background(), "key-1", newItem())
+		if err != nil || replayed {
+			t.Fatalf("CreateItemIdempotent() = %v, %v, want false, nil", replayed, err)
+		}
+		if created != 1 || keys["key-1"].Response == nil {
+			t.Errorf("created %d items, response = %s; want 1 item and stored response", created, keys["key-1"].Response)
+		}
+	})
+
+	t.Run("key taken over during create rolls back", func(t *testing.T) {
+		var created int
+		repo, keys := newIdempotencyRepo(&created, nil)
+		create := repo.createFunc
+		repo.createFunc = func(ctx context.Context, item *domain.Item) error {
+			// Запрос выполнялся дольше аренды, и ключ перехватил повтор
+			keys["key-1"] = &domain.IdempotencyRecord{Key: "key-1"}
+			return create(ctx, item)
+		}
+		txManager := &mockTxManager{repo: repo}
+		uc := New(repo, WithTxManager(txManager))
+
+		_, err := uc.CreateItemIdempotent(context.Background(), "key-1", newItem())
+		if !errors.Is(err, domain.ErrIdempotencyKeyTakenOver) || !errors.Is(err, domain.ErrConflict) {
+			t.Errorf("CreateItemIdempotent() error = %v, want ErrIdempotencyKeyTakenOver", err)
+		}
+		if txManager.rollbacks != 1 {
+			t.Errorf("rollbacks = %d, want 1", txManager.rollbacks)
+		}
+		if _, ok := keys["key-1"]; !ok {
+			t.Error("stale request released the key of the retry")
+		}
+	})
+
+	t.Run("failed create releases key", func(t *testing.T) {
+		var created int
+		repo, keys := newIdempotencyRepo(&created, errors.New("database error"))
+		uc := New(repo)
+
+		if _, err := uc.CreateItemIdempotent(context.Background(), "key-1", newItem()); err == nil {
+			t.Fatal("CreateItemIdempotent() expected error")
+		}
+		if _, ok := keys["key-1"]; ok {
+			t.Error("key was not released after failed create")
+		}
+	})
+
+	t.Run("invalid item does not reserve key", func(t *testing.T) {
+		var created int
+		repo, keys := newIdempotencyRepo(&created, nil)
+		uc := New(repo)
+
+		item := newItem()
+		item.Category = ""
+		if _, err := uc.CreateItemIdempotent(context.Background(), "key-1", item); !errors.Is(err, domain.ErrValidation) {
+			t.Fatalf("CreateItemIdempotent() error = %v, want validation error", err)
+		}
+		if len(keys) != 0 {
+			t.Error("key reserved for invalid item")
+		}
+	})
+
+	t.Run("ttl option", func(t *testing.T) {
+		var created int
+		repo, keys := newIdempotencyRepo(&created, nil)
+		uc := New(repo, WithIdempotencyTTL(time.Hour))
+
+		uc.CreateItemIdempotent(context.Background(), "key-1", newItem())
+		record := keys["key-1"]
+		if ttl := record.ExpiresAt.Sub(record.CreatedAt); ttl != time.Hour {
+			t.Errorf("key TTL = %v, want 1h", ttl)
+		}
+	})
+
+	t.Run("lease option", func(t *testing.T) {
+		var created int
+		repo, keys := newIdempotencyRepo(&created, nil)
+		uc := New(repo, WithIdempotencyLease(5*time.Second))
+
+		uc.CreateItemIdempotent(context.Background(), "key-1", newItem())
+		record := keys["key-1"]
+		if lease := record.LockedUntil.Sub(record.CreatedAt); lease != 5*time.Second {
+			t.Errorf("key lease = %v, want 5s", lease)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package usecases
+
+import (
+	"context"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"github.com/dontpanicw/SalesTracker/internal/port"
+	"time"
+)
+
+// ImportItems проверяет разобранные строки импорта и сохраняет корректные записи
+// одной транзакцией. При dryRun записи только проверяются.
+func (u *useCases) ImportItems(ctx context.Context, rows []domain.ImportRow, dryRun bool) (*domain.ImportReport, error) {
+	report := &domain.ImportReport{DryRun: dryRun, Total: len(rows), Errors: []domain.ImportRowError{}}
+
+	now := time.Now()
+	items := make([]*domain.Item, 0, len(rows))
+	for _, row := range rows {
+		if row.Err != nil {
+			report.AddError(row.Line, row.Err)
+			continue
+		}
+		row.Item.Normalize()
+		if err := row.Item.Validate(); err != nil {
+			report.AddError(row.Line, err)
+			continue
+		}
+		row.Item.CreatedAt = now
+		row.Item.UpdatedAt = now
+		items = append(items, row.Item)
+	}
+	report.Valid = len(items)
+	report.SortErrors()
+
+	if dryRun || len(items) == 0 {
+		return report, nil
+	}
+	err := u.inTx(ctx, func(ctx context.Context, repo port.Repository) error {
+		// CreateMany заполняет идентификаторы и версии, при повторе транзакции записи создаются заново
+		for _, item := range items {
+			item.ID, item.Version = 0, 0
+		}
+		if err := repo.CreateMany(ctx, items); err != nil {
+			return err
+		}
+		entries := make([]*domain.HistoryEntry, len(items))
+		for i, item := range items {
+			entries[i] = domain.NewHistoryEntry(ctx, domain.HistoryCreate, nil, item)
+		}
+		return u.saveHistory(ctx, repo, entries...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.Imported = len(items)
+	return report, nil
+}
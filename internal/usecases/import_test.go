@@ -0,0 +1,73 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestUseCases_ImportItems(t *testing.T) {
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	newRows := func() []domain.ImportRow {
+		return []domain.ImportRow{
+			{Line: 2, Item: &domain.Item{Type: "income", Amount: domain.NewMoney(1000, 0), Category: "Salary", Date: date}},
+			{Line: 4, Err: errors.New("invalid amount 'abc'")},
+			{Line: 3, Item: &domain.Item{Type: "transfer", Amount: domain.NewMoney(10, 0), Category: "Misc", Date: date}},
+			{Line: 5, Item: &domain.Item{Type: "expense", Amount: domain.NewMoney(5, 50), Currency: "usd", Category: "Food", Date: date}},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		dryRun       bool
+		createErr    error
+		wantSaved    int
+		wantImported int
+		wantErr      bool
+	}{
+		{name: "imports valid rows", wantSaved: 2, wantImported: 2},
+		{name: "dry run does not write", dryRun: true},
+		{name: "repository error", createErr: errors.New("database error"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved []*domain.Item
+			uc := New(&mockRepository{
+				createMany: func(ctx context.Context, items []*domain.Item) error {
+					if tt.createErr != nil {
+						return tt.createErr
+					}
+					saved = items
+					return nil
+				},
+			})
+
+			report, err := uc.ImportItems(context.Background(), newRows(), tt.dryRun)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ImportItems() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(saved) != tt.wantSaved || report.Imported != tt.wantImported {
+				t.Errorf("ImportItems() saved %d, imported %d, want %d, %d", len(saved), report.Imported, tt.wantSaved, tt.wantImported)
+			}
+			if report.Total != 4 || report.Valid != 2 || report.DryRun != tt.dryRun {
+				t.Errorf("ImportItems() report = %+v", report)
+			}
+			if len(report.Errors) != 2 || report.Errors[0].Line != 3 || report.Errors[1].Line != 4 {
+				t.Errorf("ImportItems() errors = %+v, want lines 3 and 4", report.Errors)
+			}
+			if fields := report.Errors[0].Fields; len(fields) != 1 || fields[0].Field != "type" {
+				t.Errorf("ImportItems() line 3 fields = %+v, want type violation", fields)
+			}
+			if len(saved) > 0 && (saved[1].Currency != "USD" || saved[0].CreatedAt.IsZero()) {
+				t.Errorf("ImportItems() did not normalize items: %+v", saved[1])
+			}
+		})
+	}
+}
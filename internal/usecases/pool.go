@@ -0,0 +1,14 @@
+package usecases
+
+import (
+	"context"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+)
+
+func (u *useCases) GetPoolStats(ctx context.Context) (*domain.PoolStats, error) {
+	if u.poolStats == nil {
+		return nil, domain.NewNotFoundError("storage has no connection pool")
+	}
+	stats := u.poolStats.PoolStats()
+	return &stats, nil
+}
@@ -0,0 +1,30 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"testing"
+)
+
+type mockPoolStats domain.PoolStats
+
+func (m mockPoolStats) PoolStats() domain.PoolStats {
+	return domain.PoolStats(m)
+}
+
+func TestUseCases_GetPoolStats(t *testing.T) {
+	uc := New(&mockRepository{}, WithPoolStats(mockPoolStats{MaxOpenConnections: 25, OpenConnections: 3, InUse: 1, Idle: 2}))
+	stats, err := uc.GetPoolStats(context.Background())
+	if err != nil {
+		t.Fatalf("GetPoolStats() error = %v", err)
+	}
+	if stats.MaxOpenConnections != 25 || stats.InUse != 1 || stats.Idle != 2 {
+		t.Errorf("GetPoolStats() = %+v", stats)
+	}
+
+	// Хранилище без пула, например в памяти
+	if _, err := New(&mockRepository{}).GetPoolStats(context.Background()); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("GetPoolStats() without pool error = %v, want ErrNotFound", err)
+	}
+}
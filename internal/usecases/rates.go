@@ -0,0 +1,28 @@
+package usecases
+
+import (
+	"context"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"time"
+)
+
+func (u *useCases) CreateRate(ctx context.Context, rate *domain.ExchangeRate) error {
+	rate.From = domain.NormalizeCurrency(rate.From)
+	rate.To = domain.NormalizeCurrency(rate.To)
+	// Postgres хранит время без смещения, поэтому начало действия курса приводится к UTC,
+	// как и дата записи
+	rate.ValidFrom = rate.ValidFrom.UTC()
+	if err := rate.Validate(); err != nil {
+		return err
+	}
+	rate.CreatedAt = time.Now()
+	return u.repo.CreateRate(ctx, rate)
+}
+
+func (u *useCases) GetRates(ctx context.Context, from, to string) ([]*domain.ExchangeRate, error) {
+	return u.repo.GetRates(ctx, domain.NormalizeCurrency(from), domain.NormalizeCurrency(to))
+}
+
+func (u *useCases) DeleteRate(ctx context.Context, id int64) error {
+	return u.repo.DeleteRate(ctx, id)
+}
@@ -0,0 +1,61 @@
+package usecases
+
+import (
+	"context"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestUseCases_CreateRate(t *testing.T) {
+	validFrom := time.Date(2024, 3, 1, 2, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+
+	tests := []struct {
+		name    string
+		rate    *domain.ExchangeRate
+		wantErr bool
+	}{
+		{
+			name: "lowercase codes are normalized",
+			rate: &domain.ExchangeRate{From: "usd", To: "rub", Rate: domain.MustParseRate("92.5"), ValidFrom: time.Now()},
+		},
+		{
+			name: "valid_from with offset is stored in UTC",
+			rate: &domain.ExchangeRate{From: "USD", To: "RUB", Rate: domain.MustParseRate("92.5"), ValidFrom: validFrom},
+		},
+		{
+			name:    "invalid rate",
+			rate:    &domain.ExchangeRate{From: "USD", To: "RUB", Rate: domain.MustParseRate("-1"), ValidFrom: time.Now()},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created := false
+			uc := New(&mockRepository{
+				createRate: func(ctx context.Context, rate *domain.ExchangeRate) error {
+					created = true
+					if rate.From != "USD" || rate.To != "RUB" {
+						t.Errorf("CreateRate() pair = %v/%v, want USD/RUB", rate.From, rate.To)
+					}
+					if rate.ValidFrom.Location() != time.UTC {
+						t.Errorf("CreateRate() valid_from = %v, want UTC", rate.ValidFrom)
+					}
+					return nil
+				},
+			})
+			want := tt.rate.ValidFrom
+			err := uc.CreateRate(context.Background(), tt.rate)
+			if !tt.rate.ValidFrom.Equal(want) {
+				t.Errorf("CreateRate() valid_from = %v, want %v", tt.rate.ValidFrom, want)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateRate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if created == tt.wantErr {
+				t.Errorf("CreateRate() repository called = %v, wantErr %v", created, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package usecases
+
+import (
+	"context"
+	"github.com/dontpanicw/SalesTracker/internal/port"
+)
+
+// inTx выполняет fn в транзакции хранилища. Если транзакции не поддерживаются,
+// fn вызывается с основным репозиторием и ее операции не атомарны.
+func (u *useCases) inTx(ctx context.Context, fn func(ctx context.Context, repo port.Repository) error) error {
+	if u.txManager == nil {
+		return fn(ctx, u.repo)
+	}
+	return u.txManager.WithinTx(ctx, fn)
+}
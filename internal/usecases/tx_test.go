@@ -0,0 +1,206 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"github.com/dontpanicw/SalesTracker/internal/domain"
+	"github.com/dontpanicw/SalesTracker/internal/port"
+	"testing"
+	"time"
+)
+
+// mockTxManager выполняет fn с репозиторием repo attempts раз, как при повторе
+// транзакции после конфликта сериализации, и считает откаты
+type mockTxManager struct {
+	repo      port.Repository
+	attempts  int
+	rollbacks int
+}
+
+func (m *mockTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context, repo port.Repository) error) error {
+	attempts := m.attempts
+	if attempts == 0 {
+		attempts = 1
+	}
+	var err error
+	for i := 0; i < attempts; i++ {
+		if err = fn(ctx, m.repo); err != nil {
+			m.rollbacks++
+			return err
+		}
+	}
+	return nil
+}
+
+func TestUseCases_HistoryInTx(t *testing.T) {
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	historyErr := errors.New("history unavailable")
+
+	tests := []struct {
+		name          string
+		withTx        bool
+		wantErr       bool
+		wantRollbacks int
+	}{
+		// В транзакции ошибка истории откатывает создание записи
+		{name: "with tx", withTx: true, wantErr: true, wantRollbacks: 1},
+		// Без транзакций запись уже создана, ошибка истории только логируется
+		{name: "without tx", withTx: false, wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepository{
+				addHistory: func(ctx context.Context, entries []*domain.HistoryEntry) error {
+					return historyErr
+				},
+			}
+			txManager := &mockTxManager{repo: repo}
+			var opts []Option
+			if tt.withTx {
+				opts = append(opts, WithTxManager(txManager))
+			}
+			uc := New(repo, opts...)
+
+			item := &domain.Item{Type: "expense", Amount: domain.NewMoney(250, 0), Category: "Cafe", Date: date}
+			err := uc.CreateItem(context.Background(), item)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateItem() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, historyErr) {
+				t.Errorf("CreateItem() error = %v, want %v", err, historyErr)
+			}
+			if txManager.rollbacks != tt.wantRollbacks {
+				t.Errorf("rollbacks = %d, want %d", txManager.rollbacks, tt.wantRollbacks)
+			}
+		})
+	}
+}
+
+func TestUseCases_UpdateItem_RetriedTx(t *testing.T) {
+	var entries []*domain.HistoryEntry
+	repo := historyRepository(&entries)
+	uc := New(repo, WithTxManager(&mockTxManager{repo: repo, attempts: 2}))
+
+	// Повтор транзакции проверяет ту же версию, что и первая попытка
+	item := &domain.Item{ID: 1, Version: 1, Type: "expense", Amount: domain.NewMoney(250, 0), Category: "Cafe", Date: time.Now()}
+	if err := uc.UpdateItem(context.Background(), item); err != nil {
+		t.Fatalf("UpdateItem() error = %v", err)
+	}
+	if item.Version != 2 {
+		t.Errorf("UpdateItem() version = %d, want 2", item.Version)
+	}
+	if len(entries) != 2 {
+		t.Errorf("history entries = %d, want one per attempt", len(entries))
+	}
+}
+
+func TestUseCases_BulkHistoryInTx(t *testing.T) {
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	newItem := func() *domain.Item {
+		return &domain.Item{Type: "expense", Amount: domain.NewMoney(250, 0), Category: "Cafe", Date: date}
+	}
+	historyErr := errors.New("history unavailable")
+
+	tests := []struct {
+		name string
+		run  func(uc *useCases, ctx context.Context) error
+	}{
+		{
+			name: "batch",
+			run: func(uc *useCases, ctx context.Context) error {
+				_, err := uc.ApplyBatch(ctx, []domain.BatchOperation{
+					{Op: domain.BatchCreate, Item: newItem()},
+					{Op: domain.BatchUpdate, ID: 1, Version: 1, Item: newItem()},
+					{Op: domain.BatchDelete, ID: 2, Version: 1},
+				}, false)
+				return err
+			},
+		},
+		{
+			name: "import",
+			run: func(uc *useCases, ctx context.Context) error {
+				_, err := uc.ImportItems(ctx, []domain.ImportRow{{Line: 2, Item: newItem()}, {Line: 3, Item: newItem()}}, false)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Пакет, его исходное состояние и история читаются и пишутся в транзакции
+			var outside int
+			txRepo := &mockRepository{
+				addHistory: func(ctx context.Context, entries []*domain.HistoryEntry) error {
+					return historyErr
+				},
+			}
+			repo := &mockRepository{
+				createMany: func(ctx context.Context, items []*domain.Item) error {
+					outside++
+					return nil
+				},
+				getByIDs: func(ctx context.Context, ids []int64) ([]*domain.Item, error) {
+					outside++
+					return nil, nil
+				},
+				applyBatch: func(ctx context.Context, batch *domain.ItemBatch, partial bool) (map[int64]error, error) {
+					outside++
+					return nil, nil
+				},
+				addHistory: func(ctx context.Context, entries []*domain.HistoryEntry) error {
+					outside++
+					return nil
+				},
+			}
+			txManager := &mockTxManager{repo: txRepo}
+			uc := New(repo, WithTxManager(txManager)).(*useCases)
+
+			// Ошибка истории откатывает все изменения
+			err := tt.run(uc, context.Background())
+			if !errors.Is(err, historyErr) {
+				t.Fatalf("error = %v, want %v", err, historyErr)
+			}
+			if txManager.rollbacks != 1 {
+				t.Errorf("rollbacks = %d, want 1", txManager.rollbacks)
+			}
+			if outside != 0 {
+				t.Errorf("repository called outside of transaction %d times", outside)
+			}
+		})
+	}
+}
+
+func TestUseCases_ApplyBatch_RetriedTx(t *testing.T) {
+	var entries []*domain.HistoryEntry
+	var versions []int64
+	repo := historyRepository(&entries)
+	repo.applyBatch = func(ctx context.Context, batch *domain.ItemBatch, partial bool) (map[int64]error, error) {
+		for _, item := range batch.Update {
+			versions = append(versions, item.Version)
+			item.Version++
+		}
+		return map[int64]error{2: domain.NewNotFoundError("item not found")}, nil
+	}
+	uc := New(repo, WithTxManager(&mockTxManager{repo: repo, attempts: 2}))
+
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	report, err := uc.ApplyBatch(context.Background(), []domain.BatchOperation{
+		{Op: domain.BatchUpdate, ID: 1, Version: 1, Item: &domain.Item{Type: "expense", Amount: domain.NewMoney(250, 0), Category: "Cafe", Date: date}},
+		{Op: domain.BatchDelete, ID: 2, Version: 1},
+	}, true)
+	if err != nil {
+		t.Fatalf("ApplyBatch() error = %v", err)
+	}
+
+	// Повтор транзакции применяет пакет с исходными версиями и не накапливает результаты
+	if len(versions) != 2 || versions[0] != 1 || versions[1] != 1 {
+		t.Errorf("ApplyItemBatch() update versions = %v, want [1 1]", versions)
+	}
+	if report.Succeeded != 1 || report.Failed != 1 {
+		t.Errorf("ApplyBatch() succeeded = %d, failed = %d, want 1 and 1", report.Succeeded, report.Failed)
+	}
+	if len(entries) != 2 {
+		t.Errorf("history entries = %d, want one per attempt", len(entries))
+	}
+}
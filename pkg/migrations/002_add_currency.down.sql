@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS exchange_rates;
+
+DROP INDEX IF EXISTS idx_items_currency;
+ALTER TABLE items DROP COLUMN IF EXISTS currency;
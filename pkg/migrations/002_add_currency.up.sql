@@ -0,0 +1,15 @@
+-- Add currency to items
+ALTER TABLE items ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'RUB';
+
+CREATE INDEX IF NOT EXISTS idx_items_currency ON items(currency);
+
+-- Create exchange rates table
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id BIGSERIAL PRIMARY KEY,
+    base_currency VARCHAR(3) NOT NULL,
+    quote_currency VARCHAR(3) NOT NULL,
+    rate NUMERIC(20, 10) NOT NULL CHECK (rate > 0),
+    valid_from TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (base_currency, quote_currency, valid_from)
+);
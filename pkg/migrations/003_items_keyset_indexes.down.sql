@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_items_date_id;
+DROP INDEX IF EXISTS idx_items_amount_id;
+DROP INDEX IF EXISTS idx_items_category_id;
+DROP INDEX IF EXISTS idx_items_created_at_id;
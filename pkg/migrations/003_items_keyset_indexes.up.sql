@@ -0,0 +1,5 @@
+-- Indexes for keyset pagination of the items list
+CREATE INDEX IF NOT EXISTS idx_items_date_id ON items(date, id);
+CREATE INDEX IF NOT EXISTS idx_items_amount_id ON items(amount, id);
+CREATE INDEX IF NOT EXISTS idx_items_category_id ON items(category, id);
+CREATE INDEX IF NOT EXISTS idx_items_created_at_id ON items(created_at, id);
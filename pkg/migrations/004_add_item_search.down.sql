@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_items_search;
+ALTER TABLE items DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE items DROP COLUMN IF EXISTS description;
@@ -0,0 +1,11 @@
+-- Add free-form description to items
+ALTER TABLE items ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '';
+
+-- Full-text search over category and description
+ALTER TABLE items ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', category), 'A') ||
+        setweight(to_tsvector('simple', description), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_items_search ON items USING GIN (search_vector);
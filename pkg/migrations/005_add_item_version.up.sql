@@ -0,0 +1,2 @@
+-- Version of the item for optimistic concurrency control (ETag / If-Match)
+ALTER TABLE items ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
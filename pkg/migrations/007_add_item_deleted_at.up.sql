@@ -0,0 +1,4 @@
+-- Soft delete: deleted items stay in the trash until purged
+ALTER TABLE items ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_items_deleted_at ON items(deleted_at) WHERE deleted_at IS NOT NULL;
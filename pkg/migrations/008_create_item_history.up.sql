@@ -0,0 +1,15 @@
+-- Audit trail of item changes made through the API.
+-- No foreign key to items: the history outlives items purged from the trash.
+CREATE TABLE IF NOT EXISTS item_history (
+    id BIGSERIAL PRIMARY KEY,
+    item_id BIGINT NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('create', 'update', 'delete', 'restore')),
+    actor VARCHAR(255) NOT NULL,
+    before JSONB, -- item snapshot before the change, NULL for create and restore
+    after JSONB,  -- item snapshot after the change, NULL for delete
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_item_history_item_id ON item_history(item_id, id);
+CREATE INDEX IF NOT EXISTS idx_item_history_actor ON item_history(actor, id);
+CREATE INDEX IF NOT EXISTS idx_item_history_created_at ON item_history(created_at);
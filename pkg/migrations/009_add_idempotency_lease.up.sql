@@ -0,0 +1,6 @@
+-- Lease of an in-progress idempotency key: after locked_until a retry may take
+-- over the key whose first request died before storing the response.
+-- Keys reserved before the lease existed are treated as already expired.
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP;
+UPDATE idempotency_keys SET locked_until = created_at WHERE locked_until IS NULL;
+ALTER TABLE idempotency_keys ALTER COLUMN locked_until SET NOT NULL;
@@ -1,15 +1,321 @@
 package migrations
 
 import (
+	"context"
 	"database/sql"
+	"embed"
 	"fmt"
-	"os"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
 
 	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
 )
 
+// files содержит миграции NNN_name.up.sql и NNN_name.down.sql: в корне — для
+// PostgreSQL, в каталоге sqlite — для SQLite. Они встроены в бинарник,
+// поэтому приложение не зависит от рабочего каталога.
+//
+//go:embed *.sql sqlite/*.sql
+var files embed.FS
+
+// lockID — ключ advisory-блокировки: миграции нескольких реплик выполняются по очереди
+const lockID int64 = 5_420_170_019
+
+// fileName разбирает имя файла миграции: версия, название и направление
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration — одна версия схемы
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string // пустая, если откат не предусмотрен
+}
+
+// Status — состояние миграции в базе
+type Status struct {
+	Migration
+	AppliedAt *time.Time // nil, если миграция не применена
+}
+
+// Migrator применяет и откатывает миграции, сохраняя примененные версии в schema_migrations
+type Migrator struct {
+	db           *sql.DB
+	migrations   []Migration
+	advisoryLock bool // брать advisory-блокировку PostgreSQL на время миграций
+}
+
+// New создает Migrator со встроенными миграциями PostgreSQL
+func New(db *sql.DB) (*Migrator, error) {
+	return NewFromFS(db, files)
+}
+
+// NewSQLite создает Migrator со встроенными миграциями SQLite. Блокировка
+// не нужна: база SQLite принадлежит одному процессу, а каждая миграция
+// и так выполняется в транзакции, которая блокирует файл на запись.
+func NewSQLite(db *sql.DB) (*Migrator, error) {
+	sqliteFiles, err := fs.Sub(files, "sqlite")
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := Load(sqliteFiles)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// NewFromFS создает Migrator для PostgreSQL с миграциями из fsys
+func NewFromFS(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations, advisoryLock: true}, nil
+}
+
+// Load читает миграции из корня fsys и возвращает их по возрастанию версий.
+// У каждой версии должен быть up-файл, down-файл необязателен.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migration files: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %s: want NNN_name.up.sql or NNN_name.down.sql", entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in %s", entry.Name())
+		}
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration file %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has different names: %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(data)
+		} else {
+			migration.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Up применяет все непримененные миграции по возрастанию версий.
+// Каждая миграция выполняется в своей транзакции вместе с записью в schema_migrations.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			err := execInTx(ctx, conn, migration.Up,
+				`INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)`,
+				migration.Version, migration.Name, time.Now())
+			if err != nil {
+				return fmt.Errorf("failed to apply migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down откатывает последние steps примененных миграций по убыванию версий.
+// steps должно быть положительным.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("steps must be positive, got %d", steps)
+	}
+	byVersion := make(map[int64]Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		byVersion[migration.Version] = migration
+	}
+
+	var done []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		versions := make([]int64, 0, len(applied))
+		for version := range applied {
+			versions = append(versions, version)
+		}
+		sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+		if steps < len(versions) {
+			versions = versions[:steps]
+		}
+
+		for _, version := range versions {
+			migration, ok := byVersion[version]
+			if !ok {
+				return fmt.Errorf("applied migration %d is unknown to this build", version)
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("migration %d_%s has no down file", migration.Version, migration.Name)
+			}
+			err := execInTx(ctx, conn, migration.Down, `DELETE FROM schema_migrations WHERE version = $1`, version)
+			if err != nil {
+				return fmt.Errorf("failed to roll back migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Status возвращает все известные миграции с отметкой о применении
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if err := createVersionTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, len(m.migrations))
+	for i, migration := range m.migrations {
+		statuses[i] = Status{Migration: migration}
+		if appliedAt, ok := applied[migration.Version]; ok {
+			statuses[i].AppliedAt = &appliedAt
+		}
+	}
+	return statuses, nil
+}
+
+// withLock выполняет fn на отдельном соединении под advisory-блокировкой.
+// Блокировка сессионная, поэтому все запросы fn идут через то же соединение.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if m.advisoryLock {
+		if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockID); err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, lockID)
+	}
+
+	if err := createVersionTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// createVersionTable создает таблицу примененных версий
+func createVersionTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	return nil
+}
+
+// appliedVersions возвращает примененные версии и время их применения
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+// execInTx выполняет скрипт миграции и запрос к schema_migrations в одной транзакции
+func execInTx(ctx context.Context, conn *sql.Conn, script, query string, args ...interface{}) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Run применяет встроенные миграции к базе PostgreSQL dsn. Все миграции идемпотентны
+// (IF NOT EXISTS), поэтому база, созданная до появления schema_migrations,
+// обновляется без ошибок.
 func Run(dsn string) error {
-	db, err := sql.Open("postgres", dsn)
+	return run("postgres", dsn, New)
+}
+
+// RunSQLite применяет встроенные миграции SQLite к файлу базы path.
+// Файл создается, если его нет.
+func RunSQLite(path string) error {
+	return run("sqlite", path, NewSQLite)
+}
+
+func run(driver, dsn string, newMigrator func(db *sql.DB) (*Migrator, error)) error {
+	db, err := sql.Open(driver, dsn)
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
@@ -19,15 +325,16 @@ func Run(dsn string) error {
 		return fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	// Читаем SQL файл
-	sqlBytes, err := os.ReadFile("pkg/migrations/001_create_tables.sql")
+	migrator, err := newMigrator(db)
 	if err != nil {
-		return fmt.Errorf("failed to read migration file: %w", err)
+		return err
 	}
-
-	// Выполняем миграцию
-	if _, err := db.Exec(string(sqlBytes)); err != nil {
-		return fmt.Errorf("failed to execute migration: %w", err)
+	applied, err := migrator.Up(context.Background())
+	for _, migration := range applied {
+		fmt.Printf("Applied migration %03d_%s\n", migration.Version, migration.Name)
+	}
+	if err != nil {
+		return err
 	}
 
 	fmt.Println("Migrations completed successfully")
@@ -0,0 +1,156 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		want    []int64
+		wantErr string
+	}{
+		{
+			name: "ordered by version",
+			files: fstest.MapFS{
+				"010_add_notes.up.sql":      {Data: []byte("ALTER TABLE items ADD COLUMN notes TEXT;")},
+				"002_add_index.up.sql":      {Data: []byte("CREATE INDEX idx ON items(date);")},
+				"002_add_index.down.sql":    {Data: []byte("DROP INDEX idx;")},
+				"001_create_items.up.sql":   {Data: []byte("CREATE TABLE items (id BIGSERIAL);")},
+				"001_create_items.down.sql": {Data: []byte("DROP TABLE items;")},
+			},
+			want: []int64{1, 2, 10},
+		},
+		{
+			name:    "down without up",
+			files:   fstest.MapFS{"001_create_items.down.sql": {Data: []byte("DROP TABLE items;")}},
+			wantErr: "has no up file",
+		},
+		{
+			name: "same version with different names",
+			files: fstest.MapFS{
+				"001_create_items.up.sql": {Data: []byte("SELECT 1;")},
+				"001_create_rates.up.sql": {Data: []byte("SELECT 2;")},
+			},
+			wantErr: "different names",
+		},
+		{
+			name:    "unexpected file name",
+			files:   fstest.MapFS{"001_create_items.sql": {Data: []byte("SELECT 1;")}},
+			wantErr: "invalid migration file name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := Load(tt.files)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() unexpected error: %v", err)
+			}
+			if len(migrations) != len(tt.want) {
+				t.Fatalf("Load() = %d migrations, want %d", len(migrations), len(tt.want))
+			}
+			for i, version := range tt.want {
+				if migrations[i].Version != version {
+					t.Errorf("migration %d version = %d, want %d", i, migrations[i].Version, version)
+				}
+			}
+			if migrations[0].Name != "create_items" || migrations[0].Down == "" || migrations[2].Down != "" {
+				t.Errorf("Load() = %+v, want names and down scripts", migrations)
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	sqliteFiles, err := fs.Sub(files, "sqlite")
+	if err != nil {
+		t.Fatalf("fs.Sub() error = %v", err)
+	}
+
+	for name, fsys := range map[string]fs.FS{"postgres": files, "sqlite": sqliteFiles} {
+		t.Run(name, func(t *testing.T) {
+			migrations, err := Load(fsys)
+			if err != nil {
+				t.Fatalf("Load() embedded migrations: %v", err)
+			}
+			for i, migration := range migrations {
+				if migration.Version != int64(i+1) {
+					t.Errorf("migration %s has version %d, want %d: versions must be consecutive", migration.Name, migration.Version, i+1)
+				}
+				if migration.Down == "" {
+					t.Errorf("migration %d_%s has no down file", migration.Version, migration.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestMigrator_SQLite(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	defer db.Close()
+
+	migrator, err := NewSQLite(db)
+	if err != nil {
+		t.Fatalf("NewSQLite() error = %v", err)
+	}
+	ctx := context.Background()
+
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	if len(applied) == 0 {
+		t.Fatal("Up() applied no migrations")
+	}
+	if again, err := migrator.Up(ctx); err != nil || len(again) != 0 {
+		t.Errorf("second Up() = %d migrations, %v; want none", len(again), err)
+	}
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			t.Errorf("Status() migration %d not applied", status.Version)
+		}
+	}
+
+	for _, steps := range []int{0, -1} {
+		if _, err := migrator.Down(ctx, steps); err == nil {
+			t.Errorf("Down(%d) expected error", steps)
+		}
+	}
+
+	rolledBack, err := migrator.Down(ctx, len(applied))
+	if err != nil {
+		t.Fatalf("Down() error = %v", err)
+	}
+	if len(rolledBack) != len(applied) {
+		t.Errorf("Down() rolled back %d migrations, want %d", len(rolledBack), len(applied))
+	}
+	var tables int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'items'`).Scan(&tables); err != nil {
+		t.Fatalf("failed to list tables: %v", err)
+	}
+	if tables != 0 {
+		t.Error("Down() left table items")
+	}
+}
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS item_history;
+DROP TABLE IF EXISTS idempotency_keys;
+DROP TABLE IF EXISTS exchange_rates;
+DROP TABLE IF EXISTS items;
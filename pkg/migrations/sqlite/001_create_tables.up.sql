@@ -0,0 +1,61 @@
+-- SQLite schema with the same tables as the PostgreSQL migrations.
+-- Amounts are stored in integer cents. Timestamps are stored in UTC as text
+-- 'YYYY-MM-DD HH:MM:SS.ffffff', so text comparison follows time order.
+-- AUTOINCREMENT keeps ids of purged items from being reused, like BIGSERIAL.
+CREATE TABLE IF NOT EXISTS items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    type TEXT NOT NULL CONSTRAINT items_type_check CHECK (type IN ('income', 'expense')),
+    amount INTEGER NOT NULL CONSTRAINT items_amount_check CHECK (amount >= 0),
+    currency TEXT NOT NULL DEFAULT 'RUB',
+    category TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    date TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    deleted_at TIMESTAMP
+);
+
+-- Indexes for keyset pagination of the items list
+CREATE INDEX IF NOT EXISTS idx_items_date_id ON items(date, id);
+CREATE INDEX IF NOT EXISTS idx_items_amount_id ON items(amount, id);
+CREATE INDEX IF NOT EXISTS idx_items_category_id ON items(category, id);
+CREATE INDEX IF NOT EXISTS idx_items_created_at_id ON items(created_at, id);
+CREATE INDEX IF NOT EXISTS idx_items_deleted_at ON items(deleted_at) WHERE deleted_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    base_currency TEXT NOT NULL,
+    quote_currency TEXT NOT NULL,
+    rate REAL NOT NULL CONSTRAINT exchange_rates_rate_check CHECK (rate > 0),
+    valid_from TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE (base_currency, quote_currency, valid_from)
+);
+
+-- Responses to requests with an Idempotency-Key header
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key TEXT PRIMARY KEY,
+    request_hash TEXT NOT NULL,
+    response BLOB, -- NULL while the first request is in progress
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+
+-- Audit trail of item changes made through the API.
+-- No foreign key to items: the history outlives items purged from the trash.
+CREATE TABLE IF NOT EXISTS item_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    item_id INTEGER NOT NULL,
+    action TEXT NOT NULL CONSTRAINT item_history_action_check CHECK (action IN ('create', 'update', 'delete', 'restore')),
+    actor TEXT NOT NULL,
+    before TEXT, -- JSON item snapshot before the change, NULL for create and restore
+    after TEXT,  -- JSON item snapshot after the change, NULL for delete
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_item_history_item_id ON item_history(item_id, id);
+CREATE INDEX IF NOT EXISTS idx_item_history_actor ON item_history(actor, id);
+CREATE INDEX IF NOT EXISTS idx_item_history_created_at ON item_history(created_at);
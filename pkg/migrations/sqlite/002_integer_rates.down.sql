@@ -0,0 +1,16 @@
+CREATE TABLE exchange_rates_real (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    base_currency TEXT NOT NULL,
+    quote_currency TEXT NOT NULL,
+    rate REAL NOT NULL CONSTRAINT exchange_rates_rate_check CHECK (rate > 0),
+    valid_from TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE (base_currency, quote_currency, valid_from)
+);
+
+INSERT INTO exchange_rates_real (id, base_currency, quote_currency, rate, valid_from, created_at)
+SELECT id, base_currency, quote_currency, rate / 10000000000.0, valid_from, created_at
+FROM exchange_rates;
+
+DROP TABLE exchange_rates;
+ALTER TABLE exchange_rates_real RENAME TO exchange_rates;
@@ -0,0 +1,19 @@
+-- Rates are stored as integers in units of 10^-10, the scale of NUMERIC(20, 10)
+-- in PostgreSQL, so conversion gives the same cents as the PostgreSQL adapter.
+-- SQLite cannot change a column type, so the table is rebuilt.
+CREATE TABLE exchange_rates_integer (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    base_currency TEXT NOT NULL,
+    quote_currency TEXT NOT NULL,
+    rate INTEGER NOT NULL CONSTRAINT exchange_rates_rate_check CHECK (rate > 0),
+    valid_from TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE (base_currency, quote_currency, valid_from)
+);
+
+INSERT INTO exchange_rates_integer (id, base_currency, quote_currency, rate, valid_from, created_at)
+SELECT id, base_currency, quote_currency, CAST(ROUND(rate * 10000000000) AS INTEGER), valid_from, created_at
+FROM exchange_rates;
+
+DROP TABLE exchange_rates;
+ALTER TABLE exchange_rates_integer RENAME TO exchange_rates;
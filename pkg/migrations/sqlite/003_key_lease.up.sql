@@ -0,0 +1,5 @@
+-- Lease of an in-progress idempotency key: after locked_until a retry may take
+-- over the key whose first request died before storing the response.
+-- Keys reserved before the lease existed are treated as already expired.
+ALTER TABLE idempotency_keys ADD COLUMN locked_until TIMESTAMP NOT NULL DEFAULT '';
+UPDATE idempotency_keys SET locked_until = created_at;
@@ -3,6 +3,7 @@ const API_URL = '/api';
 // Загрузка записей при старте
 document.addEventListener('DOMContentLoaded', () => {
     loadItems();
+    loadTrash();
     
     // Установка текущей даты по умолчанию
     const today = new Date().toISOString().split('T')[0];
@@ -21,8 +22,10 @@ document.getElementById('itemForm').addEventListener('submit', async (e) => {
     
     const item = {
         type: document.getElementById('type').value,
-        amount: parseFloat(document.getElementById('amount').value),
+        amount: document.getElementById('amount').value,
+        currency: document.getElementById('currency').value,
         category: document.getElementById('category').value,
+        description: document.getElementById('description').value,
         date: new Date(document.getElementById('date').value).toISOString()
     };
     
@@ -37,6 +40,7 @@ document.getElementById('itemForm').addEventListener('submit', async (e) => {
             alert('Запись добавлена!');
             document.getElementById('itemForm').reset();
             document.getElementById('date').value = new Date().toISOString().split('T')[0];
+            document.getElementById('currency').value = 'RUB';
             loadItems();
         } else {
             const error = await response.json();
@@ -47,32 +51,91 @@ document.getElementById('itemForm').addEventListener('submit', async (e) => {
     }
 });
 
-// Загрузка списка записей
-async function loadItems() {
+// Курсор следующей страницы списка записей
+let nextCursor = '';
+
+// Обработка формы импорта
+document.getElementById('importForm').addEventListener('submit', async (e) => {
+    e.preventDefault();
+    
+    const form = new FormData();
+    form.append('file', document.getElementById('importFile').files[0]);
+    form.append('dry_run', document.getElementById('importDryRun').checked);
+    
+    try {
+        const response = await fetch(`${API_URL}/items/import`, { method: 'POST', body: form });
+        const report = await response.json();
+        if (!response.ok) {
+            alert('Ошибка: ' + report.error);
+            return;
+        }
+        
+        const result = document.getElementById('importResult');
+        result.innerHTML = `<p>Строк: ${report.total}, корректных: ${report.valid}, сохранено: ${report.imported}</p>`;
+        if (report.errors.length > 0) {
+            result.innerHTML += '<ul>' + report.errors
+                .map(e => `<li>Строка ${e.line}: ${escapeHtml(e.error)}</li>`)
+                .join('') + '</ul>';
+        }
+        if (report.imported > 0) loadItems();
+    } catch (error) {
+        alert('Ошибка соединения: ' + error.message);
+    }
+});
+
+// Параметры фильтров списка записей
+function itemFilterParams() {
     const from = document.getElementById('filterFrom').value;
     const to = document.getElementById('filterTo').value;
+    const type = document.getElementById('filterType').value;
+    const q = document.getElementById('filterQuery').value.trim();
+    const sort = document.getElementById('filterSort').value;
     
-    let url = `${API_URL}/items`;
     const params = new URLSearchParams();
-    
     if (from) params.append('from', new Date(from).toISOString());
     if (to) params.append('to', new Date(to).toISOString());
+    if (type) params.append('type', type);
+    if (q) params.append('q', q);
+    if (sort) params.append('sort', sort);
+    return params;
+}
+
+// Выгрузка записей по текущим фильтрам в CSV
+function exportItems() {
+    const params = itemFilterParams();
+    params.append('format', 'csv');
+    window.location = `${API_URL}/items/export?${params.toString()}`;
+}
+
+// Загрузка списка записей. При more = true подгружается следующая страница
+async function loadItems(more = false) {
+    let url = `${API_URL}/items`;
+    const params = itemFilterParams();
+    if (more && nextCursor) params.append('cursor', nextCursor);
     
     if (params.toString()) url += '?' + params.toString();
     
     try {
         const response = await fetch(url);
-        const items = await response.json();
+        const page = await response.json();
+        if (!response.ok) {
+            alert('Ошибка: ' + page.error);
+            return;
+        }
         
         const tbody = document.getElementById('itemsBody');
-        tbody.innerHTML = '';
+        if (!more) tbody.innerHTML = '';
+        
+        nextCursor = page.next_cursor || '';
+        document.getElementById('loadMore').style.display = nextCursor ? '' : 'none';
+        document.getElementById('itemsTotal').textContent = `Всего: ${page.total}`;
         
-        if (!items || items.length === 0) {
+        if (page.items.length === 0 && !more) {
             tbody.innerHTML = '<tr><td colspan="6" style="text-align: center;">Нет записей</td></tr>';
             return;
         }
         
-        items.forEach(item => {
+        page.items.forEach(item => {
             const row = document.createElement('tr');
             const date = new Date(item.date).toLocaleDateString('ru-RU');
             const typeClass = item.type === 'income' ? 'income' : 'expense';
@@ -81,12 +144,12 @@ async function loadItems() {
             row.innerHTML = `
                 <td>${item.id}</td>
                 <td class="${typeClass}">${typeText}</td>
-                <td>${item.amount.toFixed(2)} ₽</td>
-                <td>${item.category}</td>
+                <td>${item.amount.toFixed(2)} ${item.currency}</td>
+                <td>${renderItemText(item)}</td>
                 <td>${date}</td>
                 <td>
                     <button class="btn btn-edit" onclick="editItem(${item.id})">✏️</button>
-                    <button class="btn btn-danger" onclick="deleteItem(${item.id})">🗑️</button>
+                    <button class="btn btn-danger" onclick="deleteItem(${item.id}, ${item.version})">🗑️</button>
                 </td>
             `;
             tbody.appendChild(row);
@@ -100,56 +163,86 @@ async function loadItems() {
 async function loadAnalytics() {
     const from = document.getElementById('analyticsFrom').value;
     const to = document.getElementById('analyticsTo').value;
+    const currency = document.getElementById('analyticsCurrency').value;
     
     if (!from || !to) {
         alert('Укажите период для аналитики');
         return;
     }
     
-    const url = `${API_URL}/analytics?from=${new Date(from).toISOString()}&to=${new Date(to).toISOString()}`;
+    const params = new URLSearchParams({
+        from: new Date(from).toISOString(),
+        to: new Date(to).toISOString(),
+        currency: currency
+    });
+    const url = `${API_URL}/analytics?${params.toString()}`;
     
     try {
         const response = await fetch(url);
         const analytics = await response.json();
         
+        if (!response.ok) {
+            alert('Ошибка: ' + analytics.error);
+            return;
+        }
+        
         const container = document.getElementById('analyticsResult');
         container.innerHTML = `
             <div class="analytics-item">
-                <h3>Сумма</h3>
-                <p>${analytics.sum.toFixed(2)} ₽</p>
-            </div>
-            <div class="analytics-item">
-                <h3>Среднее</h3>
-                <p>${analytics.avg.toFixed(2)} ₽</p>
-            </div>
-            <div class="analytics-item">
-                <h3>Количество</h3>
-                <p>${analytics.count}</p>
-            </div>
-            <div class="analytics-item">
-                <h3>Медиана</h3>
-                <p>${analytics.median.toFixed(2)} ₽</p>
-            </div>
-            <div class="analytics-item">
-                <h3>90-й перцентиль</h3>
-                <p>${analytics.percentile_90.toFixed(2)} ₽</p>
+                <h3>Баланс</h3>
+                <p>${analytics.balance.toFixed(2)} ${analytics.currency}</p>
             </div>
+            ${renderAnalyticsGroup('Доходы', analytics.income, analytics.currency)}
+            ${renderAnalyticsGroup('Расходы', analytics.expense, analytics.currency)}
         `;
     } catch (error) {
         alert('Ошибка загрузки аналитики: ' + error.message);
     }
 }
 
+// Отрисовка статистики по одному типу записей
+function renderAnalyticsGroup(title, stats, currency) {
+    return `
+        <div class="analytics-item">
+            <h3>${title}: сумма</h3>
+            <p>${stats.sum.toFixed(2)} ${currency}</p>
+        </div>
+        <div class="analytics-item">
+            <h3>${title}: среднее</h3>
+            <p>${stats.avg.toFixed(2)} ${currency}</p>
+        </div>
+        <div class="analytics-item">
+            <h3>${title}: количество</h3>
+            <p>${stats.count}</p>
+        </div>
+        <div class="analytics-item">
+            <h3>${title}: медиана</h3>
+            <p>${stats.median.toFixed(2)} ${currency}</p>
+        </div>
+        <div class="analytics-item">
+            <h3>${title}: 90-й перцентиль</h3>
+            <p>${stats.percentile_90.toFixed(2)} ${currency}</p>
+        </div>
+    `;
+}
+
+// ETag редактируемой записи: изменение сохраняется, только если запись
+// не изменили с момента открытия формы
+let editETag = null;
+
 // Редактирование записи
 async function editItem(id) {
     try {
         const response = await fetch(`${API_URL}/items/${id}`);
         const item = await response.json();
+        editETag = response.headers.get('ETag');
         
         document.getElementById('editId').value = item.id;
         document.getElementById('editType').value = item.type;
         document.getElementById('editAmount').value = item.amount;
+        document.getElementById('editCurrency').value = item.currency;
         document.getElementById('editCategory').value = item.category;
+        document.getElementById('editDescription').value = item.description;
         document.getElementById('editDate').value = new Date(item.date).toISOString().split('T')[0];
         
         document.getElementById('editModal').style.display = 'block';
@@ -165,15 +258,19 @@ document.getElementById('editForm').addEventListener('submit', async (e) => {
     const id = document.getElementById('editId').value;
     const item = {
         type: document.getElementById('editType').value,
-        amount: parseFloat(document.getElementById('editAmount').value),
+        amount: document.getElementById('editAmount').value,
+        currency: document.getElementById('editCurrency').value,
         category: document.getElementById('editCategory').value,
+        description: document.getElementById('editDescription').value,
         date: new Date(document.getElementById('editDate').value).toISOString()
     };
     
     try {
+        const headers = { 'Content-Type': 'application/json' };
+        if (editETag) headers['If-Match'] = editETag;
         const response = await fetch(`${API_URL}/items/${id}`, {
             method: 'PUT',
-            headers: { 'Content-Type': 'application/json' },
+            headers,
             body: JSON.stringify(item)
         });
         
@@ -181,6 +278,10 @@ document.getElementById('editForm').addEventListener('submit', async (e) => {
             alert('Запись обновлена!');
             closeEditModal();
             loadItems();
+        } else if (response.status === 412) {
+            alert('Запись уже изменил кто-то другой. Откройте ее заново, чтобы увидеть актуальные данные.');
+            closeEditModal();
+            loadItems();
         } else {
             const error = await response.json();
             alert('Ошибка: ' + error.error);
@@ -190,17 +291,22 @@ document.getElementById('editForm').addEventListener('submit', async (e) => {
     }
 });
 
-// Удаление записи
-async function deleteItem(id) {
+// Удаление записи (перенос в корзину)
+async function deleteItem(id, version) {
     if (!confirm('Удалить эту запись?')) return;
     
     try {
         const response = await fetch(`${API_URL}/items/${id}`, {
-            method: 'DELETE'
+            method: 'DELETE',
+            headers: { 'If-Match': `"${version}"` }
         });
         
         if (response.ok) {
-            alert('Запись удалена!');
+            alert('Запись перенесена в корзину');
+            loadItems();
+            loadTrash();
+        } else if (response.status === 412) {
+            alert('Запись изменилась после загрузки списка. Проверьте ее и повторите удаление.');
             loadItems();
         } else {
             alert('Ошибка удаления');
@@ -210,6 +316,91 @@ async function deleteItem(id) {
     }
 }
 
+// Загрузка записей в корзине
+async function loadTrash() {
+    try {
+        const response = await fetch(`${API_URL}/items/trash`);
+        const page = await response.json();
+        if (!response.ok) {
+            alert('Ошибка: ' + page.error);
+            return;
+        }
+        
+        const tbody = document.getElementById('trashBody');
+        tbody.innerHTML = '';
+        document.getElementById('trashTotal').textContent = `Всего: ${page.total}`;
+        
+        if (page.items.length === 0) {
+            tbody.innerHTML = '<tr><td colspan="6" style="text-align: center;">Корзина пуста</td></tr>';
+            return;
+        }
+        
+        page.items.forEach(item => {
+            const row = document.createElement('tr');
+            const typeClass = item.type === 'income' ? 'income' : 'expense';
+            const typeText = item.type === 'income' ? 'Доход' : 'Расход';
+            const deletedAt = new Date(item.deleted_at).toLocaleString('ru-RU');
+            
+            row.innerHTML = `
+                <td>${item.id}</td>
+                <td class="${typeClass}">${typeText}</td>
+                <td>${item.amount.toFixed(2)} ${item.currency}</td>
+                <td>${renderItemText(item)}</td>
+                <td>${deletedAt}</td>
+                <td>
+                    <button class="btn btn-secondary" onclick="restoreItem(${item.id}, ${item.version})">↩️</button>
+                </td>
+            `;
+            tbody.appendChild(row);
+        });
+    } catch (error) {
+        alert('Ошибка загрузки корзины: ' + error.message);
+    }
+}
+
+// Восстановление записи из корзины
+async function restoreItem(id, version) {
+    try {
+        const response = await fetch(`${API_URL}/items/${id}/restore`, {
+            method: 'POST',
+            headers: { 'If-Match': `"${version}"` }
+        });
+        
+        if (response.ok) {
+            loadItems();
+            loadTrash();
+        } else if (response.status === 412 || response.status === 404) {
+            alert('Запись изменилась после загрузки корзины. Обновите корзину и повторите.');
+            loadTrash();
+        } else {
+            alert('Ошибка восстановления');
+        }
+    } catch (error) {
+        alert('Ошибка соединения: ' + error.message);
+    }
+}
+
+// Экранирование HTML
+function escapeHtml(text) {
+    const div = document.createElement('div');
+    div.textContent = text;
+    return div.innerHTML;
+}
+
+// Категория и описание записи; при поиске — фрагмент с подсветкой совпадений
+function renderItemText(item) {
+    if (item.match) {
+        return escapeHtml(item.match.snippet)
+            .replaceAll('&lt;mark&gt;', '<mark>')
+            .replaceAll('&lt;/mark&gt;', '</mark>');
+    }
+    let html = escapeHtml(item.category);
+    if (item.description) {
+        html += `<br><small>${escapeHtml(item.description)}</small>`;
+    }
+    return html;
+}
+
 // Закрытие модального окна
 function closeEditModal() {
     document.getElementById('editModal').style.display = 'none';
@@ -219,6 +410,9 @@ function closeEditModal() {
 function clearFilters() {
     document.getElementById('filterFrom').value = '';
     document.getElementById('filterTo').value = '';
+    document.getElementById('filterType').value = '';
+    document.getElementById('filterQuery').value = '';
+    document.getElementById('filterSort').value = '';
     loadItems();
 }
 